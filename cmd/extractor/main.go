@@ -1,26 +1,40 @@
 package main
 
 import (
+	"bufio"
 	"context"
 	"database/sql"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
 
+	"github.com/brianluby/karakeep-extractor/internal/adapter/bitbucket"
+	"github.com/brianluby/karakeep-extractor/internal/adapter/gitea"
 	gh "github.com/brianluby/karakeep-extractor/internal/adapter/github"
+	"github.com/brianluby/karakeep-extractor/internal/adapter/gitlab"
 	"github.com/brianluby/karakeep-extractor/internal/adapter/http"
 	"github.com/brianluby/karakeep-extractor/internal/adapter/karakeep"
 	"github.com/brianluby/karakeep-extractor/internal/adapter/llm"
 	rep "github.com/brianluby/karakeep-extractor/internal/adapter/reporter"
+	"github.com/brianluby/karakeep-extractor/internal/adapter/sourcehut"
 	"github.com/brianluby/karakeep-extractor/internal/adapter/sqlite"
 	"github.com/brianluby/karakeep-extractor/internal/adapter/trillium"
 	"github.com/brianluby/karakeep-extractor/internal/config"
 	"github.com/brianluby/karakeep-extractor/internal/core/domain"
 	"github.com/brianluby/karakeep-extractor/internal/core/service"
 	"github.com/brianluby/karakeep-extractor/internal/core/service/analysis"
+	"github.com/brianluby/karakeep-extractor/internal/core/service/filter"
 	"github.com/brianluby/karakeep-extractor/internal/ui"
 	"github.com/brianluby/karakeep-extractor/internal/ui/tui"
 )
@@ -50,25 +64,85 @@ func main() {
 	enrichToken := enrichCmd.String("token", "", "GitHub Personal Access Token (overrides env var)")
 	enrichDB := enrichCmd.String("db", "", "Path to SQLite database")
 	enrichTui := enrichCmd.Bool("tui", false, "Enable TUI mode")
+	enrichWait := enrichCmd.Bool("wait-on-ratelimit", false, "Pause until the rate limit resets instead of stopping")
+	enrichReadme := enrichCmd.Bool("readme", false, "Also fetch README text for GitHub repositories (used by search and analyze --readme)")
+	enrichReleases := enrichCmd.Bool("releases", false, "Also record the latest release (or tag) of GitHub repositories (used by the releases command)")
+	enrichAPI := enrichCmd.String("api", "rest", "GitHub API to use: rest (one request per repo) or graphql (batched, requires a token)")
+
+	reportStaleCmd := flag.NewFlagSet("report stale", flag.ExitOnError)
+	reportStaleMonths := reportStaleCmd.Int("months", 12, "Flag repositories with no push in this many months")
+	reportStaleLimit := reportStaleCmd.Int("limit", 0, "Maximum number of repositories to list (0 = all)")
+	reportStaleFormat := reportStaleCmd.String("format", "table", "Output format (table, json, csv)")
+	reportStaleDB := reportStaleCmd.String("db", "", "Path to SQLite database")
+
+	releasesCmd := flag.NewFlagSet("releases", flag.ExitOnError)
+	releasesSince := releasesCmd.String("since", "", "List releases published within this window (e.g. 7d) instead of those recorded since the last run")
+	releasesLimit := releasesCmd.Int("limit", 0, "Maximum number of repositories to list (0 = all)")
+	releasesFormat := releasesCmd.String("format", "table", "Output format (table, json, csv)")
+	releasesPeek := releasesCmd.Bool("peek", false, "Don't record this run; the same releases are listed next time")
+	releasesSinkURL := releasesCmd.String("sink-url", "", "URL to POST new releases to")
+	var releasesSinkHeaders arrayFlags
+	releasesCmd.Var(&releasesSinkHeaders, "sink-header", "Header to send with sink request (Key: Value)")
+	releasesSinkTrillium := releasesCmd.Bool("sink-trillium", false, "Send new releases to Trillium Notes")
+	releasesDB := releasesCmd.String("db", "", "Path to SQLite database")
+
+	searchCmd := flag.NewFlagSet("search", flag.ExitOnError)
+	searchLimit := searchCmd.Int("limit", 20, "Maximum number of results")
+	searchFormat := searchCmd.String("format", "table", "Output format (table, json, csv)")
+	searchDB := searchCmd.String("db", "", "Path to SQLite database")
+	searchIncludeOrphaned := searchCmd.Bool("include-orphaned", false, "Include repositories whose bookmark was deleted from Karakeep")
+	searchIncludeArchived := searchCmd.Bool("include-archived", false, "Include repositories whose bookmark is archived in Karakeep")
+
+	dbCmd := flag.NewFlagSet("db", flag.ExitOnError)
+	dbPathFlag := dbCmd.String("db", "", "Path to SQLite database")
 
 	rankCmd := flag.NewFlagSet("rank", flag.ExitOnError)
 	rankLimit := rankCmd.Int("limit", 20, "Number of repositories to display")
-	rankSort := rankCmd.String("sort", "stars", "Metric to sort by (stars, forks, updated)")
+	rankOffset := rankCmd.Int("offset", 0, "Skip this many ranked repositories")
+	rankPage := rankCmd.Int("page", 0, "Show this page of --limit repositories (1 = first)")
+	rankSort := rankCmd.String("sort", "stars", "Metric to sort by (stars, forks, updated, stars-delta, bookmarks, score)")
+	rankExplain := rankCmd.Bool("explain", false, "With --sort score, show each score component's contribution")
+	rankSince := rankCmd.String("since", "30d", "Window for --sort stars-delta (e.g. 30d, 2w, 12h)")
 	rankFormat := rankCmd.String("format", "table", "Output format (table, json, csv)")
 	rankSinkURL := rankCmd.String("sink-url", "", "URL to POST ranked results to")
 	var rankSinkHeaders arrayFlags
 	rankCmd.Var(&rankSinkHeaders, "sink-header", "Header to send with sink request (Key: Value)")
 	rankSinkTrillium := rankCmd.Bool("sink-trillium", false, "Send ranked results to Trillium Notes")
-	rankTag := rankCmd.String("tag", "", "Filter repositories by tag (title/description)")
+	rankTag := rankCmd.String("tag", "", "Filter repositories by Karakeep tag")
+	rankTagSource := rankCmd.String("tag-source", "", "Only match --tag when attached by: ai, human")
+	rankLicense := rankCmd.String("license", "", "Filter by SPDX license identifier (e.g. MIT)")
+	rankTopic := rankCmd.String("topic", "", "Filter by repository topic")
+	rankWhere := rankCmd.String("where", "", "Filter expression, e.g. 'lang:go stars:>1000 pushed:>2024-01-01 -tag:archived'")
+	rankView := rankCmd.String("view", "", "Run a saved view (see 'views list'); other flags refine or override it")
+	rankExcludeArchived := rankCmd.Bool("exclude-archived", false, "Hide repositories archived on their forge")
 	rankDB := rankCmd.String("db", "", "Path to SQLite database")
+	rankIncludeOrphaned := rankCmd.Bool("include-orphaned", false, "Include repositories whose bookmark was deleted from Karakeep")
+	rankIncludeArchived := rankCmd.Bool("include-archived", false, "Include repositories whose bookmark is archived in Karakeep")
+
+	viewsAddCmd := flag.NewFlagSet("views add", flag.ExitOnError)
+	viewsAddDescription := viewsAddCmd.String("description", "", "What the view is for, shown by 'views list'")
+	viewsAddWhere := viewsAddCmd.String("where", "", "Filter expression (see rank --where)")
+	viewsAddSort := viewsAddCmd.String("sort", "", "Metric to sort by (stars, forks, updated, stars-delta, bookmarks, score)")
+	viewsAddLimit := viewsAddCmd.Int("limit", 0, "Number of repositories to display")
+	viewsAddFormat := viewsAddCmd.String("format", "", "Output format (table, json, csv)")
+	viewsAddSinkURL := viewsAddCmd.String("sink-url", "", "URL to POST the view's results to")
+	var viewsAddSinkHeaders arrayFlags
+	viewsAddCmd.Var(&viewsAddSinkHeaders, "sink-header", "Header to send with sink request (Key: Value)")
+	viewsAddSinkTrillium := viewsAddCmd.Bool("sink-trillium", false, "Send the view's results to Trillium Notes")
+	viewsAddReplace := viewsAddCmd.Bool("replace", false, "Overwrite an existing view of the same name")
 
 	analyzeCmd := flag.NewFlagSet("analyze", flag.ExitOnError)
 	analyzeLang := analyzeCmd.String("lang", "", "Filter by language")
 	analyzeLimit := analyzeCmd.Int("limit", 50, "Limit number of repositories")
 	analyzeTag := analyzeCmd.String("tag", "", "Filter by tag")
+	analyzeTagSource := analyzeCmd.String("tag-source", "", "Only match --tag when attached by: ai, human")
 	analyzeDB := analyzeCmd.String("db", "", "Path to SQLite database")
 	analyzeMinStars := analyzeCmd.Int("min-stars", 0, "Minimum number of stars")
 	analyzeMaxStars := analyzeCmd.Int("max-stars", 0, "Maximum number of stars (0 for no limit)")
+	analyzeWhere := analyzeCmd.String("where", "", "Filter expression, e.g. 'lang:go stars:>1000 tag:cli' (see rank --where)")
+	analyzeReadme := analyzeCmd.Bool("readme", false, "Include stored README text in the LLM context (see enrich --readme)")
+	analyzeContextTokens := analyzeCmd.Int("context-tokens", 0, "Model context window in tokens; larger sets are analyzed in parts (default: llm.context_tokens)")
+	analyzeChat := analyzeCmd.Bool("chat", false, "Start an interactive session for follow-up questions (/filter, /save, /reset)")
 
 	// Global flags logic is complex with subcommands if mixed. 
 	// We'll assume extract is default if no subcommand, or explicit 'extract' command.
@@ -85,10 +159,124 @@ func main() {
 	case "enrich":
 		// Parse flags for enrich
 		enrichCmd.Parse(os.Args[2:])
-		runEnrich(*enrichLimit, *enrichForce, *enrichToken, *enrichDB, *enrichTui)
+		runEnrich(*enrichLimit, *enrichForce, *enrichToken, *enrichDB, *enrichTui, *enrichAPI, *enrichWait, *enrichReadme, *enrichReleases)
 	case "rank":
 		rankCmd.Parse(os.Args[2:])
-		runRank(*rankLimit, *rankSort, *rankFormat, *rankSinkURL, rankSinkHeaders, *rankSinkTrillium, *rankTag, *rankDB)
+		var viewWhere []domain.FilterTerm
+		if *rankView != "" {
+			viewWhere = applyView(rankCmd, *rankView)
+		}
+		since, err := service.ParseSince(*rankSince)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		tagSource, err := parseTagSource(*rankTagSource)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		where, err := filter.Parse(*rankWhere)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid --where: %v\n", err)
+			os.Exit(1)
+		}
+		offset, paging, err := rankOffsetFlags(rankCmd, *rankOffset, *rankPage, *rankLimit)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		query := domain.RankQuery{
+			Limit:               *rankLimit,
+			Offset:              offset,
+			SortBy:              domain.RankSortOption(*rankSort),
+			Tag:                 *rankTag,
+			TagSource:           tagSource,
+			License:             *rankLicense,
+			Topic:               *rankTopic,
+			ExcludeRepoArchived: *rankExcludeArchived,
+			IncludeOrphaned:     *rankIncludeOrphaned,
+			IncludeArchived:     *rankIncludeArchived,
+			Since:               since,
+			Where:               append(viewWhere, where...),
+		}
+		runRank(query, *rankExplain, paging, *rankFormat, *rankSinkURL, rankSinkHeaders, *rankSinkTrillium, *rankDB)
+	case "views":
+		usage := "Usage: karakeep-extractor views <list|add|delete> [name] [flags]"
+		if len(os.Args) < 3 {
+			fmt.Println(usage)
+			os.Exit(1)
+		}
+		switch os.Args[2] {
+		case "list":
+			runViewsList()
+		case "add":
+			if len(os.Args) < 4 || strings.HasPrefix(os.Args[3], "-") {
+				fmt.Println("Usage: karakeep-extractor views add <name> [--where expr] [--sort s] [--limit n] [--format f] [--sink-url url]")
+				os.Exit(1)
+			}
+			viewsAddCmd.Parse(os.Args[4:])
+			view := domain.View{
+				Description: *viewsAddDescription,
+				Where:       *viewsAddWhere,
+				Sort:        *viewsAddSort,
+				Limit:       *viewsAddLimit,
+				Format:      *viewsAddFormat,
+			}
+			if *viewsAddSinkURL != "" || *viewsAddSinkTrillium {
+				view.Sink = &domain.ViewSink{URL: *viewsAddSinkURL, Headers: viewsAddSinkHeaders, Trillium: *viewsAddSinkTrillium}
+			}
+			runViewsAdd(os.Args[3], view, *viewsAddReplace)
+		case "delete":
+			if len(os.Args) < 4 {
+				fmt.Println("Usage: karakeep-extractor views delete <name>")
+				os.Exit(1)
+			}
+			runViewsDelete(os.Args[3])
+		default:
+			fmt.Println(usage)
+			os.Exit(1)
+		}
+	case "report":
+		if len(os.Args) < 3 || os.Args[2] != "stale" {
+			fmt.Println("Usage: karakeep-extractor report stale [flags]")
+			os.Exit(1)
+		}
+		reportStaleCmd.Parse(os.Args[3:])
+		runReportStale(*reportStaleMonths, *reportStaleLimit, *reportStaleFormat, *reportStaleDB)
+	case "releases":
+		releasesCmd.Parse(os.Args[2:])
+		since, err := service.ParseSince(*releasesSince)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		opts := service.ReleaseWatchOptions{Since: since, Limit: *releasesLimit, Peek: *releasesPeek}
+		runReleases(opts, *releasesFormat, *releasesSinkURL, releasesSinkHeaders, *releasesSinkTrillium, *releasesDB)
+	case "search":
+		searchCmd.Parse(os.Args[2:])
+		if searchCmd.NArg() < 1 {
+			fmt.Println("Usage: karakeep-extractor search [flags] \"query\"")
+			os.Exit(1)
+		}
+		query := domain.SearchQuery{
+			Text:            strings.Join(searchCmd.Args(), " "),
+			Limit:           *searchLimit,
+			IncludeOrphaned: *searchIncludeOrphaned,
+			IncludeArchived: *searchIncludeArchived,
+		}
+		runSearch(query, *searchFormat, *searchDB)
+	case "db":
+		if len(os.Args) < 3 || (os.Args[2] != "migrate" && os.Args[2] != "status") {
+			fmt.Println("Usage: karakeep-extractor db <migrate|status> [--db path]")
+			os.Exit(1)
+		}
+		dbCmd.Parse(os.Args[3:])
+		if os.Args[2] == "migrate" {
+			runDBMigrate(*dbPathFlag)
+		} else {
+			runDBStatus(*dbPathFlag)
+		}
 	case "setup":
 		runSetup()
 	case "config":
@@ -99,12 +287,35 @@ func main() {
 		runConfigLLM()
 	case "analyze":
 		analyzeCmd.Parse(os.Args[2:])
-		if analyzeCmd.NArg() < 1 {
+		if analyzeCmd.NArg() < 1 && !*analyzeChat {
 			fmt.Println("Usage: karakeep analyze [flags] \"query\"")
+			fmt.Println("       karakeep analyze --chat [flags] [\"first question\"]")
+			os.Exit(1)
+		}
+		question := analyzeCmd.Arg(0)
+		tagSource, err := parseTagSource(*analyzeTagSource)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
-		query := analyzeCmd.Arg(0)
-		runAnalyze(*analyzeLang, *analyzeLimit, *analyzeTag, *analyzeDB, *analyzeMinStars, *analyzeMaxStars, query)
+		where, err := filter.Parse(*analyzeWhere)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid --where: %v\n", err)
+			os.Exit(1)
+		}
+		query := domain.RankQuery{
+			Limit:     *analyzeLimit,
+			SortBy:    domain.SortByStars,
+			Tag:       *analyzeTag,
+			TagSource: tagSource,
+			Where:     append(where, analyzeFilterTerms(*analyzeLang, *analyzeMinStars, *analyzeMaxStars)...),
+		}
+		if *analyzeChat {
+			filterDesc := analyzeFilterDescription(*analyzeWhere, *analyzeTag, *analyzeLang, *analyzeMinStars, *analyzeMaxStars)
+			runAnalyzeChat(query, filterDesc, *analyzeDB, *analyzeReadme, *analyzeContextTokens, question)
+		} else {
+			runAnalyze(query, *analyzeDB, *analyzeReadme, *analyzeContextTokens, question)
+		}
 	}
 }
 
@@ -116,14 +327,63 @@ func printUsage() {
 	fmt.Println("Commands:")
 	fmt.Println("  setup      Run the interactive configuration wizard to set API tokens and URLs.")
 	fmt.Println("  config     Manage configuration (e.g., 'config llm').")
-	fmt.Println("  extract    Fetch bookmarks from Karakeep and save GitHub links to the local database.")
-	fmt.Println("  enrich     Fetch metadata (stars, forks, etc.) from GitHub for extracted repositories.")
+	fmt.Println("  extract    Fetch bookmarks from Karakeep and save repository links (GitHub, GitLab, Codeberg/Gitea, Bitbucket, sourcehut) to the local database.")
+	fmt.Println("  enrich     Fetch metadata (stars, forks, etc.) from each forge for extracted repositories.")
 	fmt.Println("  rank       Display, filter, and export a ranked list of repositories.")
+	fmt.Println("  views      Manage saved rank queries ('views list', 'views add', 'views delete'); run one with 'rank --view'.")
+	fmt.Println("  releases   List bookmarked repositories with new releases since the last run (see 'enrich --releases').")
+	fmt.Println("  search     Full-text search over repository names, titles, descriptions and tags.")
+	fmt.Println("  report     Reports over the database (e.g. 'report stale' for abandoned, archived or deleted repos).")
 	fmt.Println("  analyze    Analyze repositories using an LLM.")
+	fmt.Println("  db         Manage the database schema ('db migrate', 'db status').")
 	fmt.Println("")
 	fmt.Println("Run 'karakeep-extractor <command> --help' for command-specific flags.")
 }
 
+// forgeConfigs returns the built-in public forges overlaid with any configured (e.g. self-hosted) ones.
+func forgeConfigs(cfg *config.Config) []domain.ForgeConfig {
+	byHost := make(map[string]domain.ForgeConfig)
+	for host, forge := range service.DefaultForgeHosts {
+		byHost[host] = domain.ForgeConfig{Host: host, Type: forge}
+	}
+	if cfg != nil {
+		for _, fc := range cfg.Forges {
+			fc.Host = strings.ToLower(fc.Host)
+			byHost[fc.Host] = fc
+		}
+	}
+
+	var forges []domain.ForgeConfig
+	for _, fc := range byHost {
+		forges = append(forges, fc)
+	}
+	return forges
+}
+
+// newForgeClient builds the stats client for a forge host, or nil for unknown forge types.
+func newForgeClient(fc domain.ForgeConfig) domain.ForgeClient {
+	apiURL := fc.APIURL
+	if apiURL == "" {
+		apiURL = "https://" + fc.Host
+	}
+
+	switch fc.Type {
+	case domain.ForgeGitLab:
+		return gitlab.NewClient(fc.Token).WithBaseURL(apiURL)
+	case domain.ForgeGitea:
+		return gitea.NewClient(fc.Token).WithBaseURL(apiURL)
+	case domain.ForgeBitbucket:
+		if fc.APIURL == "" {
+			apiURL = "https://api.bitbucket.org"
+		}
+		return bitbucket.NewClient(fc.Token).WithBaseURL(apiURL)
+	case domain.ForgeSourceHut:
+		return sourcehut.NewClient(fc.Token).WithBaseURL(apiURL)
+	default:
+		return nil
+	}
+}
+
 // expandPath expands the tilde (~) in the path to the user's home directory.
 func expandPath(path string) string {
 	if strings.HasPrefix(path, "~/") {
@@ -144,39 +404,51 @@ func runConfigLLM() {
 	fmt.Println("--------------------------")
 
 	// Defaults
-	defaultProvider := "openai"
+	defaultProvider := llm.ProviderOpenAI
 	if currentCfg.LLM.Provider != "" {
 		defaultProvider = currentCfg.LLM.Provider
 	}
-	defaultBaseURL := "https://api.openai.com/v1"
-	if currentCfg.LLM.BaseURL != "" {
-		defaultBaseURL = currentCfg.LLM.BaseURL
-	}
-	defaultModel := "gpt-4o"
-	if currentCfg.LLM.Model != "" {
-		defaultModel = currentCfg.LLM.Model
-	}
 
 	// Prompts
-	provider, err := prompt.Ask("Provider (openai, anthropic, local)", defaultProvider)
+	provider, err := prompt.Ask("Provider (openai, anthropic, ollama, local)", defaultProvider)
 	if err != nil {
 		log.Fatalf("Error reading input: %v", err)
 	}
+	provider = strings.ToLower(provider)
+	if _, err := llm.NewProvider(domain.LLMConfig{Provider: provider}); err != nil {
+		log.Fatalf("Error: %v", err)
+	}
 
-	baseURL, err := prompt.Ask("Base URL", defaultBaseURL)
-	if err != nil {
-		log.Fatalf("Error reading input: %v", err)
+	// Keep the current URL and model unless switching providers.
+	defaultBaseURL, defaultModel := llm.Defaults(provider)
+	if provider == currentCfg.LLM.Provider {
+		if currentCfg.LLM.BaseURL != "" {
+			defaultBaseURL = currentCfg.LLM.BaseURL
+		}
+		if currentCfg.LLM.Model != "" {
+			defaultModel = currentCfg.LLM.Model
+		}
 	}
 
-	apiKey, err := prompt.AskSecret("API Key")
+	baseURL, err := prompt.Ask("Base URL", defaultBaseURL)
 	if err != nil {
 		log.Fatalf("Error reading input: %v", err)
 	}
-	if apiKey == "" {
-		apiKey = currentCfg.LLM.APIKey
-	}
 
-	model, err := prompt.Ask("Model Name", defaultModel)
+	// Ollama needs no key; pick from its installed models instead of typing a name.
+	var apiKey, model string
+	if provider == llm.ProviderOllama {
+		model, err = askOllamaModel(prompt, baseURL, defaultModel)
+	} else {
+		apiKey, err = prompt.AskSecret("API Key")
+		if err != nil {
+			log.Fatalf("Error reading input: %v", err)
+		}
+		if apiKey == "" {
+			apiKey = currentCfg.LLM.APIKey
+		}
+		model, err = prompt.Ask("Model Name", defaultModel)
+	}
 	if err != nil {
 		log.Fatalf("Error reading input: %v", err)
 	}
@@ -196,53 +468,313 @@ func runConfigLLM() {
 	fmt.Printf("\nLLM configuration saved to %s\n", path)
 }
 
-func runAnalyze(lang string, limit int, tag string, dbFlag string, minStars int, maxStars int, query string) {
-	// 1. Config
-	loader := config.NewConfigLoader()
-	cfg, err := loader.LoadConfig(nil)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Warning: Failed to load config: %v\n", err)
+// rankOffsetFlags turns --offset or --page into an offset, and reports whether either was given
+// (in which case the output includes paging metadata).
+func rankOffsetFlags(fs *flag.FlagSet, offset int, page int, limit int) (int, bool, error) {
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	switch {
+	case set["offset"] && set["page"]:
+		return 0, false, fmt.Errorf("use either --offset or --page, not both")
+	case set["page"]:
+		if page < 1 {
+			return 0, false, fmt.Errorf("invalid --page %d (pages start at 1)", page)
+		}
+		if limit <= 0 {
+			return 0, false, fmt.Errorf("--page needs a positive --limit")
+		}
+		return (page - 1) * limit, true, nil
+	case set["offset"]:
+		if offset < 0 {
+			return 0, false, fmt.Errorf("invalid --offset %d", offset)
+		}
+		return offset, true, nil
 	}
+	return 0, false, nil
+}
+
+// newAnalysisService opens the database and the configured LLM provider for analyze.
+// The caller closes the returned *sql.DB.
+func newAnalysisService(dbFlag string, readme bool, contextTokens int) (*analysis.Service, *sql.DB) {
+	cfg, db, repo := openRepository(dbFlag)
 
-	// Basic Validation
-	if cfg.LLM.BaseURL == "" {
+	// Basic Validation (Anthropic and Ollama have a default URL)
+	if cfg == nil || (cfg.LLM.BaseURL == "" && cfg.LLM.Provider == "") {
+		db.Close()
 		fmt.Println("Error: LLM not configured. Run 'karakeep config llm'.")
 		os.Exit(1)
 	}
 
-	// 2. DB
-	dbPath := dbFlag
-	if dbPath == "" {
-		dbPath = os.Getenv("KARAKEEP_DB")
+	// Service
+	llmClient, err := llm.NewProvider(cfg.LLM)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v. Run 'karakeep-extractor config llm'.\n", err)
+		os.Exit(1)
 	}
-	if dbPath == "" && cfg != nil {
-		dbPath = cfg.DBPath
+	svc := analysis.NewService(repo, llmClient)
+	if readme {
+		svc.WithReadmes(repo, analysis.DefaultReadmeChars)
 	}
-	if dbPath == "" {
-		dbPath = "./karakeep.db"
+	if contextTokens <= 0 {
+		contextTokens = llmContextTokens(cfg.LLM)
 	}
-	dbPath = expandPath(dbPath)
+	svc.WithContextBudget(analysis.PromptBudget(contextTokens, cfg.LLM.MaxTokens)).
+		WithProgress(rep.NewTextReporter())
+	return svc, db
+}
 
-	db, err := sql.Open("sqlite3", dbPath)
+// llmContextTokens returns the configured context window, falling back to Ollama's
+// num_ctx option (0 if neither is set).
+func llmContextTokens(cfg domain.LLMConfig) int {
+	if cfg.ContextTokens > 0 {
+		return cfg.ContextTokens
+	}
+	switch n := cfg.Options["num_ctx"].(type) {
+	case int:
+		return n
+	case float64:
+		return int(n)
+	}
+	return 0
+}
+
+// analyzeFilterDescription summarizes analyze's filter flags in --where syntax, for the chat transcript.
+func analyzeFilterDescription(where string, tag string, lang string, minStars int, maxStars int) string {
+	var parts []string
+	if where != "" {
+		parts = append(parts, where)
+	}
+	if tag != "" {
+		parts = append(parts, "tag:"+tag)
+	}
+	if lang != "" {
+		parts = append(parts, "lang:"+lang)
+	}
+	if minStars > 0 {
+		parts = append(parts, fmt.Sprintf("stars:>=%d", minStars))
+	}
+	if maxStars > 0 {
+		parts = append(parts, fmt.Sprintf("stars:<=%d", maxStars))
+	}
+	return strings.Join(parts, " ")
+}
+
+const chatHelp = `Commands:
+  /filter [expr]  Show the current filter, or switch to repos matching a --where expression
+  /reset          Forget the conversation and start over with the same repos
+  /save [file]    Save the conversation as Markdown (default: analysis-<time>.md)
+  /quit           Leave (or press Ctrl+D)
+Ctrl+C stops the answer being written.`
+
+// runAnalyzeChat answers questions about one selection of repos until the user quits,
+// sending each with the conversation so far.
+func runAnalyzeChat(query domain.RankQuery, filterDesc string, dbFlag string, readme bool, contextTokens int, question string) {
+	svc, db := newAnalysisService(dbFlag, readme, contextTokens)
+	defer db.Close()
+	svc.WithStream(func(token string) { fmt.Print(token) })
+
+	ctx := context.Background()
+	session, err := svc.NewSession(ctx, query, filterDesc)
 	if err != nil {
-		log.Fatalf("Failed to open DB: %v", err)
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Chatting about %d repositories. Type /help for commands.\n", session.RepoCount())
+
+	ask := func(question string) {
+		// Ctrl+C stops this answer, not the session.
+		askCtx, stop := signal.NotifyContext(ctx, os.Interrupt)
+		defer stop()
+		fmt.Println()
+		_, err := session.Ask(askCtx, question)
+		fmt.Println()
+		if err != nil {
+			if askCtx.Err() != nil {
+				fmt.Println("(cancelled; the question was not added to the conversation)")
+				return
+			}
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		}
+	}
+
+	if question != "" {
+		fmt.Printf("\n> %s\n", question)
+		ask(question)
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		fmt.Print("\n> ")
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			fmt.Println()
+			return
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if !strings.HasPrefix(line, "/") {
+			ask(line)
+			continue
+		}
+
+		command, arg, _ := strings.Cut(line, " ")
+		arg = strings.TrimSpace(arg)
+		switch command {
+		case "/quit", "/exit":
+			return
+		case "/help":
+			fmt.Println(chatHelp)
+		case "/reset":
+			session.Reset()
+			fmt.Println("Conversation cleared.")
+		case "/filter":
+			if arg == "" {
+				desc := session.Filter()
+				if desc == "" {
+					desc = "(none)"
+				}
+				fmt.Printf("Filter: %s (%d repositories)\n", desc, session.RepoCount())
+				continue
+			}
+			where, err := filter.Parse(arg)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: invalid filter: %v\n", err)
+				continue
+			}
+			// The expression replaces --where and the --lang/--stars shorthands; --tag and --limit stay.
+			next := session.Query()
+			next.Where = where
+			desc := arg
+			if next.Tag != "" {
+				desc += " tag:" + next.Tag
+			}
+			if err := session.SetQuery(ctx, next, desc); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				continue
+			}
+			fmt.Printf("Now discussing %d repositories; the conversation so far is kept (/reset to clear it).\n", session.RepoCount())
+		case "/save":
+			if len(session.Turns()) == 0 {
+				fmt.Println("Nothing to save yet.")
+				continue
+			}
+			path := arg
+			if path == "" {
+				path = fmt.Sprintf("analysis-%s.md", time.Now().Format("20060102-150405"))
+			}
+			if err := saveChatTranscript(expandPath(path), session.Turns()); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				continue
+			}
+			fmt.Printf("Saved to %s.\n", path)
+		default:
+			fmt.Printf("Unknown command %s. Type /help for commands.\n", command)
+		}
+	}
+}
+
+func saveChatTranscript(path string, turns []domain.ChatTurn) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create transcript: %w", err)
+	}
+	if err := ui.WriteChatTranscript(f, turns, time.Now()); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write transcript: %w", err)
 	}
+	return f.Close()
+}
+
+// askOllamaModel offers the models installed in Ollama, falling back to typing a name
+// when Ollama can't be reached or has none.
+func askOllamaModel(prompt *ui.Prompt, baseURL string, defaultModel string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	models, err := llm.NewOllamaClient(domain.LLMConfig{BaseURL: baseURL}).ListModels(ctx)
+	if err != nil {
+		fmt.Printf("Could not list Ollama models: %v\n", err)
+		return prompt.Ask("Model Name", defaultModel)
+	}
+	if len(models) == 0 {
+		fmt.Println("No models installed in Ollama yet (see 'ollama pull').")
+		return prompt.Ask("Model Name", defaultModel)
+	}
+
+	names := make([]string, len(models))
+	for i, m := range models {
+		names[i] = m.Name
+	}
+	// Default to the configured model if installed, else the first one.
+	if !slices.Contains(names, defaultModel) {
+		defaultModel = names[0]
+	}
+	fmt.Println("Installed Ollama models:")
+	return prompt.AskChoice("Model (number or name)", names, defaultModel)
+}
+
+// parseTagSource validates the --tag-source flag; empty matches tags from any source.
+func parseTagSource(s string) (domain.TagSource, error) {
+	switch source := domain.TagSource(strings.ToLower(s)); source {
+	case "", domain.TagSourceAI, domain.TagSourceHuman:
+		return source, nil
+	default:
+		return "", fmt.Errorf("invalid tag source %q (use ai or human)", s)
+	}
+}
+
+// analyzeFilterTerms turns analyze's --lang, --min-stars and --max-stars into --where terms.
+func analyzeFilterTerms(lang string, minStars int, maxStars int) []domain.FilterTerm {
+	var terms []domain.FilterTerm
+	if lang != "" {
+		terms = append(terms, domain.FilterTerm{Field: domain.FilterLang, Op: domain.OpEq, Value: lang})
+	}
+	if minStars > 0 {
+		terms = append(terms, domain.FilterTerm{Field: domain.FilterStars, Op: domain.OpGte, Number: &minStars})
+	}
+	if maxStars > 0 {
+		terms = append(terms, domain.FilterTerm{Field: domain.FilterStars, Op: domain.OpLte, Number: &maxStars})
+	}
+	return terms
+}
+
+func runAnalyze(query domain.RankQuery, dbFlag string, readme bool, contextTokens int, question string) {
+	svc, db := newAnalysisService(dbFlag, readme, contextTokens)
 	defer db.Close()
-	repo := sqlite.NewSQLiteRepository(db)
-	
-	// 3. Service
-	llmClient := llm.NewClient(cfg.LLM)
-	svc := analysis.NewService(repo, llmClient)
+
+	// Ctrl+C cancels the request instead of waiting for the answer to finish.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Print the answer as it streams in.
+	streamed := false
+	svc.WithStream(func(token string) {
+		if !streamed {
+			fmt.Println("\n--- Analysis Result ---")
+			streamed = true
+		}
+		fmt.Print(token)
+	})
 
 	fmt.Println("Analyzing repositories...")
-	answer, err := svc.Analyze(context.Background(), query, limit, lang, tag, minStars, maxStars)
+	answer, err := svc.Analyze(ctx, question, query)
+	if streamed {
+		fmt.Println()
+	}
 	if err != nil {
+		if ctx.Err() != nil {
+			fmt.Fprintln(os.Stderr, "Cancelled.")
+			os.Exit(130)
+		}
 		fmt.Fprintf(os.Stderr, "Error during analysis: %v\n", err)
 		os.Exit(1)
 	}
 
-	fmt.Println("\n--- Analysis Result ---")
-	fmt.Println(answer)
+	if !streamed {
+		fmt.Println("\n--- Analysis Result ---")
+		fmt.Println(answer)
+	}
 }
 
 func runExtract() {
@@ -261,6 +793,7 @@ func runExtract() {
 		karakeepToken = extractCmd.String("token", "", "Karakeep API Token")
 		dbPath        = extractCmd.String("db", "", "Path to SQLite database")
 		tuiMode       = extractCmd.Bool("tui", false, "Enable TUI mode")
+		fullSync      = extractCmd.Bool("full", false, "Ignore the sync checkpoint and resync all bookmarks")
 	)
 
 	// Parse arguments starting from os.Args[2]
@@ -309,6 +842,11 @@ func runExtract() {
 	}
 	client := karakeep.NewClient(domainCfg)
 
+	forgeHosts := make(map[string]domain.Forge)
+	for _, fc := range forgeConfigs(cfg) {
+		forgeHosts[fc.Host] = fc.Type
+	}
+
 	// Ensure DB directory exists
 	dbDir := filepath.Dir(*dbPath)
 	if err := os.MkdirAll(dbDir, 0755); err != nil {
@@ -325,13 +863,16 @@ func runExtract() {
 		log.Fatalf("Schema init failed: %v", err)
 	}
 
-	svc := service.NewExtractor(client, repo)
+	svc := service.NewExtractor(client, repo).
+		WithSyncState(repo).
+		WithReconciler(repo).
+		WithDetectors(service.DefaultForgeDetectors(forgeHosts))
 
 	// Select Reporter
 	var reporter domain.ProgressReporter
 	if *tuiMode {
 		task := func(r domain.ProgressReporter) error {
-			return svc.Extract(context.Background(), r)
+			return svc.Extract(context.Background(), *fullSync, r)
 		}
 
 		// Run TUI
@@ -342,13 +883,13 @@ func runExtract() {
 		os.Exit(0)
 	} else {
 		reporter = rep.NewTextReporter()
-		if err := svc.Extract(context.Background(), reporter); err != nil {
+		if err := svc.Extract(context.Background(), *fullSync, reporter); err != nil {
 			log.Fatalf("Extraction failed: %v", err)
 		}
 	}
 }
 
-func runEnrich(limit int, force bool, tokenOverride string, dbFlag string, tuiMode bool) {
+func runEnrich(limit int, force bool, tokenOverride string, dbFlag string, tuiMode bool, api string, waitOnRateLimit bool, readme bool, releases bool) {
 	// Load Config
 	loader := config.NewConfigLoader()
 	cfg, err := loader.LoadConfig(nil)
@@ -394,8 +935,36 @@ func runEnrich(limit int, force bool, tokenOverride string, dbFlag string, tuiMo
 		log.Fatalf("Schema init failed: %v", err)
 	}
 
-	ghClient := gh.NewClient(ghToken)
-	enricher := service.NewEnricher(repo, ghClient)
+	var ghClient domain.GitHubClient
+	switch api {
+	case "rest":
+		ghClient = gh.NewClient(ghToken)
+	case "graphql":
+		if ghToken == "" {
+			log.Fatal("The GraphQL API requires a GitHub token (--token, GITHUB_TOKEN or config)")
+		}
+		ghClient = gh.NewGraphQLClient(ghToken)
+	default:
+		log.Fatalf("Invalid --api value %q (use rest or graphql)", api)
+	}
+	enricher := service.NewEnricher(repo, ghClient).WithWaitOnRateLimit(waitOnRateLimit)
+	for _, fc := range forgeConfigs(cfg) {
+		if forgeClient := newForgeClient(fc); forgeClient != nil {
+			enricher.WithForgeClient(fc.Host, forgeClient)
+		} else {
+			fmt.Fprintf(os.Stderr, "Warning: unknown forge type %q for host %s\n", fc.Type, fc.Host)
+		}
+	}
+
+	// READMEs and releases always come from the REST API.
+	var readmes *service.ReadmeFetcher
+	if readme {
+		readmes = service.NewReadmeFetcher(repo, gh.NewClient(ghToken))
+	}
+	var releaseFetcher *service.ReleaseFetcher
+	if releases {
+		releaseFetcher = service.NewReleaseFetcher(repo, gh.NewClient(ghToken))
+	}
 
 	// Select Reporter
 	var reporter domain.ProgressReporter
@@ -403,6 +972,12 @@ func runEnrich(limit int, force bool, tokenOverride string, dbFlag string, tuiMo
 		task := func(r domain.ProgressReporter) error {
 			// fmt.Printf("Starting enrichment (Limit: %d, Force: %t)...\n", limit, force) // Handled by Reporter
 			_, _, err := enricher.EnrichBatch(context.Background(), limit, force, 5, r) // 5 workers
+			if err == nil && readmes != nil {
+				_, _, err = readmes.FetchReadmes(context.Background(), limit, force, r)
+			}
+			if err == nil && releaseFetcher != nil {
+				_, _, err = releaseFetcher.FetchReleases(context.Background(), limit, force, r)
+			}
 			return err
 		}
 
@@ -419,6 +994,16 @@ func runEnrich(limit int, force bool, tokenOverride string, dbFlag string, tuiMo
 		if err != nil {
 			os.Exit(1)
 		}
+		if readmes != nil {
+			if _, _, err := readmes.FetchReadmes(context.Background(), limit, force, reporter); err != nil {
+				os.Exit(1)
+			}
+		}
+		if releaseFetcher != nil {
+			if _, _, err := releaseFetcher.FetchReleases(context.Background(), limit, force, reporter); err != nil {
+				os.Exit(1)
+			}
+		}
 		// If using text reporter, we might want to log summary if not already done by Finish()
 		// TextReporter implementation does log "Finished: ...".
 		_ = success
@@ -426,7 +1011,7 @@ func runEnrich(limit int, force bool, tokenOverride string, dbFlag string, tuiMo
 	}
 }
 
-func runRank(limit int, sort string, format string, sinkURL string, sinkHeaders []string, sinkTrillium bool, tag string, dbFlag string) {
+func runRank(query domain.RankQuery, explain bool, paging bool, format string, sinkURL string, sinkHeaders []string, sinkTrillium bool, dbFlag string) {
 	// Load Config
 	loader := config.NewConfigLoader()
 	cfg, err := loader.LoadConfig(nil)
@@ -472,25 +1057,326 @@ func runRank(limit int, sort string, format string, sinkURL string, sinkHeaders
 		os.Exit(1)
 	}
 
-	var sink domain.Sink
+	sink := newSink(cfg, sinkURL, sinkHeaders, sinkTrillium, "GitHub Rankings")
+
+	var scoreCfg domain.ScoreConfig
+	if cfg != nil {
+		scoreCfg = cfg.Score
+	}
+	scorer, err := service.NewScorer(scoreCfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	ranker := service.NewRanker(repo, exporter, sink).WithScorer(scorer).WithExplain(explain).WithPaging(paging)
+	if err := ranker.Rank(context.Background(), query, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// newSink builds the sink selected by the --sink-* flags, or nil if none was.
+// The title names the Trillium note.
+func newSink(cfg *config.Config, sinkURL string, sinkHeaders []string, sinkTrillium bool, title string) domain.Sink {
 	if sinkTrillium {
-		if cfg.TrilliumURL == "" || cfg.TrilliumToken == "" {
+		if cfg == nil || cfg.TrilliumURL == "" || cfg.TrilliumToken == "" {
 			fmt.Fprintf(os.Stderr, "Error: Trillium URL and Token required. Run 'karakeep-extractor setup'.\n")
 			os.Exit(1)
 		}
 		client := trillium.NewClient(cfg.TrilliumURL, cfg.TrilliumToken)
-		sink = trillium.NewSink(client)
-	} else if sinkURL != "" {
-		sink = http.NewHTTPSink(sinkURL, sinkHeaders)
+		return trillium.NewSink(client).WithTitle(title)
+	}
+	if sinkURL != "" {
+		return http.NewHTTPSink(sinkURL, sinkHeaders)
+	}
+	return nil
+}
+
+// applyView fills in the rank flags that weren't given on the command line from the named
+// view, and returns the view's filter terms to AND with --where.
+func applyView(fs *flag.FlagSet, name string) []domain.FilterTerm {
+	cfg, err := config.NewConfigLoader().LoadConfig(nil)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	view, ok := cfg.Views[name]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Error: no view named %q (see 'karakeep-extractor views list')\n", name)
+		os.Exit(1)
+	}
+	where, err := validateView(view)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: view %q: %v\n", name, err)
+		os.Exit(1)
+	}
+
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	values := map[string]string{"sort": view.Sort, "format": view.Format}
+	if view.Limit > 0 {
+		values["limit"] = strconv.Itoa(view.Limit)
+	}
+	// A sink given on the command line replaces the view's entirely.
+	if view.Sink != nil && !set["sink-url"] && !set["sink-trillium"] {
+		values["sink-url"] = view.Sink.URL
+		if view.Sink.Trillium {
+			values["sink-trillium"] = "true"
+		}
+		for _, h := range view.Sink.Headers {
+			fs.Set("sink-header", h)
+		}
+	}
+	for flagName, value := range values {
+		if value != "" && !set[flagName] {
+			fs.Set(flagName, value)
+		}
+	}
+	return where
+}
+
+// validateView checks a view's fields as rank would, returning its parsed filter terms.
+func validateView(view domain.View) ([]domain.FilterTerm, error) {
+	where, err := filter.Parse(view.Where)
+	if err != nil {
+		return nil, fmt.Errorf("invalid where: %w", err)
+	}
+	if view.Sort != "" {
+		if err := service.ValidateSortOption(domain.RankSortOption(view.Sort)); err != nil {
+			return nil, err
+		}
+	}
+	if view.Format != "" {
+		if _, err := ui.GetExporter(view.Format); err != nil {
+			return nil, err
+		}
+	}
+	if view.Limit < 0 {
+		return nil, fmt.Errorf("invalid limit %d", view.Limit)
+	}
+	return where, nil
+}
+
+func runViewsList() {
+	cfg, err := config.NewConfigLoader().LoadConfig(nil)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if len(cfg.Views) == 0 {
+		fmt.Println("No views saved. Add one with 'karakeep-extractor views add <name> --where ...'.")
+		return
+	}
+
+	names := make([]string, 0, len(cfg.Views))
+	for name := range cfg.Views {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tSORT\tLIMIT\tFORMAT\tSINK\tWHERE\tDESCRIPTION")
+	for _, name := range names {
+		view := cfg.Views[name]
+		limit := "-"
+		if view.Limit > 0 {
+			limit = strconv.Itoa(view.Limit)
+		}
+		sink := "-"
+		if view.Sink != nil {
+			if view.Sink.Trillium {
+				sink = "trillium"
+			} else if view.Sink.URL != "" {
+				sink = view.Sink.URL
+			}
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", name, orDash(view.Sort), limit, orDash(view.Format), sink, orDash(view.Where), view.Description)
+	}
+	w.Flush()
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func runViewsAdd(name string, view domain.View, replace bool) {
+	if _, err := validateView(view); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if err := config.NewConfigLoader().SaveView(name, view, replace); err != nil {
+		if errors.Is(err, config.ErrViewExists) {
+			fmt.Fprintf(os.Stderr, "Error: %v (use --replace to overwrite it)\n", err)
+		} else {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		}
+		os.Exit(1)
+	}
+	path, _ := config.GetConfigPath()
+	fmt.Printf("View %q saved to %s. Run it with 'karakeep-extractor rank --view %s'.\n", name, path, name)
+}
+
+func runViewsDelete(name string) {
+	if err := config.NewConfigLoader().DeleteView(name); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("View %q deleted.\n", name)
+}
+
+// openRepository is openDatabase plus bringing the schema up to date.
+// The caller closes the returned *sql.DB.
+func openRepository(dbFlag string) (*config.Config, *sql.DB, *sqlite.SQLiteRepository) {
+	cfg, db, repo := openDatabase(dbFlag)
+	if err := repo.InitSchema(context.Background()); err != nil {
+		db.Close()
+		log.Fatalf("Schema init failed: %v", err)
+	}
+	return cfg, db, repo
+}
+
+// openDatabase loads the config and opens the SQLite database (Flag > Env > Config > Default)
+// without touching the schema.
+func openDatabase(dbFlag string) (*config.Config, *sql.DB, *sqlite.SQLiteRepository) {
+	loader := config.NewConfigLoader()
+	cfg, err := loader.LoadConfig(nil)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Failed to load config file: %v\n", err)
 	}
 
-	ranker := service.NewRanker(repo, exporter, sink)
-	if err := ranker.Rank(context.Background(), limit, sort, tag, os.Stdout); err != nil {
+	dbPath := dbFlag
+	if dbPath == "" {
+		dbPath = os.Getenv("KARAKEEP_DB")
+	}
+	if dbPath == "" && cfg != nil {
+		dbPath = cfg.DBPath
+	}
+	if dbPath == "" {
+		dbPath = "./karakeep.db"
+	}
+	dbPath = expandPath(dbPath)
+
+	if err := os.MkdirAll(filepath.Dir(dbPath), 0755); err != nil {
+		log.Fatalf("Failed to create DB directory: %v", err)
+	}
+
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		log.Fatalf("Failed to open DB: %v", err)
+	}
+	return cfg, db, sqlite.NewSQLiteRepository(db)
+}
+
+func runReportStale(months int, limit int, format string, dbFlag string) {
+	_, db, repo := openRepository(dbFlag)
+	defer db.Close()
+
+	exporter, err := ui.GetExporter(format)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	report := service.NewStaleReport(repo, exporter)
+	if err := report.Run(context.Background(), months, limit, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func runReleases(opts service.ReleaseWatchOptions, format string, sinkURL string, sinkHeaders []string, sinkTrillium bool, dbFlag string) {
+	cfg, db, repo := openRepository(dbFlag)
+	defer db.Close()
+
+	exporter, err := ui.GetExporter(format)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	sink := newSink(cfg, sinkURL, sinkHeaders, sinkTrillium, "New Releases")
+
+	watcher := service.NewReleaseWatcher(repo, repo, exporter, sink)
+	if err := watcher.Run(context.Background(), opts, os.Stdout); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 }
 
+func runSearch(query domain.SearchQuery, format string, dbFlag string) {
+	_, db, repo := openRepository(dbFlag)
+	defer db.Close()
+
+	exporter, err := ui.GetExporter(format)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if !repo.HasFullTextSearch(context.Background()) {
+		fmt.Fprintln(os.Stderr, "Note: built without SQLite FTS5 (-tags sqlite_fts5); using substring matching.")
+	}
+
+	searcher := service.NewSearcher(repo, exporter)
+	if err := searcher.Search(context.Background(), query, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func runDBMigrate(dbFlag string) {
+	_, db, repo := openDatabase(dbFlag)
+	defer db.Close()
+
+	applied, err := repo.Migrate(context.Background())
+	for _, m := range applied {
+		fmt.Printf("Applied %03d %s\n", m.Version, m.Name)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if len(applied) == 0 {
+		fmt.Printf("Schema is already up to date (version %d).\n", sqlite.LatestSchemaVersion())
+		return
+	}
+	fmt.Printf("Schema is at version %d.\n", sqlite.LatestSchemaVersion())
+}
+
+func runDBStatus(dbFlag string) {
+	_, db, repo := openDatabase(dbFlag)
+	defer db.Close()
+
+	statuses, err := repo.MigrationStatuses(context.Background())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	version, err := repo.SchemaVersion(context.Background())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Current version: %d (latest: %d)\n\n", version, sqlite.LatestSchemaVersion())
+	pending := 0
+	for _, s := range statuses {
+		applied := "pending"
+		if s.AppliedAt != nil {
+			applied = "applied " + s.AppliedAt.Local().Format("2006-01-02 15:04")
+		} else {
+			pending++
+		}
+		fmt.Printf("  %03d  %-40s %s\n", s.Version, s.Name, applied)
+	}
+	if pending > 0 {
+		fmt.Printf("\n%d pending migration(s). Run 'karakeep-extractor db migrate'.\n", pending)
+	}
+}
+
 func runSetup() {
 	prompt := ui.NewPrompt(os.Stdin, os.Stdout)
 
@@ -568,14 +1454,14 @@ func runSetup() {
 	// 3. Confirm Overwrite if file exists
 	path, _ := config.GetConfigPath()
 	if _, err := os.Stat(path); err == nil {
-		confirm, _ := prompt.Ask(fmt.Sprintf("Overwrite existing config at %s? (y/N)", path), "N")
+		confirm, _ := prompt.Ask(fmt.Sprintf("Update existing config at %s? (y/N)", path), "N")
 		if strings.ToLower(confirm) != "y" {
 			fmt.Println("Aborted.")
 			os.Exit(0)
 		}
 	}
 
-	// 4. Save
+	// 4. Save, keeping the rest of the file (views, score, forges, llm)
 	newCfg := &config.Config{
 		KarakeepURL:   url,
 		KarakeepToken: token,
@@ -585,7 +1471,7 @@ func runSetup() {
 		TrilliumToken: trilliumToken,
 	}
 
-	if err := loader.SaveConfig(newCfg); err != nil {
+	if err := loader.SaveSetup(newCfg); err != nil {
 		log.Fatalf("Failed to save config: %v", err)
 	}
 
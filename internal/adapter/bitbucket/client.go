@@ -0,0 +1,103 @@
+package bitbucket
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/brianluby/karakeep-extractor/internal/core/domain"
+)
+
+// Client fetches repository metadata from the Bitbucket Cloud 2.0 API.
+type Client struct {
+	token      string
+	baseURL    string
+	httpClient *http.Client
+}
+
+func NewClient(token string) *Client {
+	return &Client{
+		token:   token,
+		baseURL: "https://api.bitbucket.org",
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+// WithBaseURL allows overriding the base URL for testing.
+func (c *Client) WithBaseURL(url string) *Client {
+	c.baseURL = url
+	return c
+}
+
+type bitbucketRepoResponse struct {
+	UpdatedOn   time.Time `json:"updated_on"`
+	Description string    `json:"description"`
+	Language    string    `json:"language"`
+}
+
+// bitbucketPageResponse is used to read collection sizes (forks, watchers) without paging.
+type bitbucketPageResponse struct {
+	Size int `json:"size"`
+}
+
+// GetRepoStats fetches stats for workspace/slug. Bitbucket has no stars, so watchers are used instead.
+func (c *Client) GetRepoStats(ctx context.Context, workspace, slug string) (*domain.RepoStats, int, error) {
+	repoURL := fmt.Sprintf("%s/2.0/repositories/%s/%s", c.baseURL, workspace, slug)
+
+	var repo bitbucketRepoResponse
+	if err := c.get(ctx, repoURL, &repo); err != nil {
+		return nil, -1, err
+	}
+
+	var forks, watchers bitbucketPageResponse
+	if err := c.get(ctx, repoURL+"/forks?pagelen=1", &forks); err != nil {
+		return nil, -1, err
+	}
+	if err := c.get(ctx, repoURL+"/watchers?pagelen=1", &watchers); err != nil {
+		return nil, -1, err
+	}
+
+	stats := &domain.RepoStats{
+		Stars:       watchers.Size,
+		Forks:       forks.Size,
+		LastPushed:  repo.UpdatedOn,
+		Description: repo.Description,
+		Language:    repo.Language,
+	}
+	return stats, -1, nil
+}
+
+func (c *Client) get(ctx context.Context, url string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return domain.ErrRepoNotFound
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return domain.ErrRateLimitExceeded
+	case resp.StatusCode != http.StatusOK:
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
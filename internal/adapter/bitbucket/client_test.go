@@ -0,0 +1,42 @@
+package bitbucket
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/brianluby/karakeep-extractor/internal/core/domain"
+)
+
+func TestClient_GetRepoStats(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/2.0/repositories/team/repo":
+			w.Write([]byte(`{"updated_on": "2024-01-15T08:00:00+00:00", "description": "Bitbucket repo", "language": "python"}`))
+		case "/2.0/repositories/team/repo/forks":
+			w.Write([]byte(`{"size": 4}`))
+		case "/2.0/repositories/team/repo/watchers":
+			w.Write([]byte(`{"size": 25}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	client := NewClient("").WithBaseURL(ts.URL)
+
+	stats, _, err := client.GetRepoStats(context.Background(), "team", "repo")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if stats.Stars != 25 || stats.Forks != 4 || stats.Language != "python" {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+
+	_, _, err = client.GetRepoStats(context.Background(), "team", "missing")
+	if !errors.Is(err, domain.ErrRepoNotFound) {
+		t.Errorf("Expected ErrRepoNotFound, got %v", err)
+	}
+}
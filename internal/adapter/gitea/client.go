@@ -0,0 +1,87 @@
+package gitea
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/brianluby/karakeep-extractor/internal/core/domain"
+)
+
+// Client fetches repository metadata from the Gitea/Forgejo REST API (Codeberg or self-hosted).
+type Client struct {
+	token      string
+	baseURL    string
+	httpClient *http.Client
+}
+
+func NewClient(token string) *Client {
+	return &Client{
+		token:   token,
+		baseURL: "https://codeberg.org",
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+// WithBaseURL points the client at another instance (or a test server).
+func (c *Client) WithBaseURL(url string) *Client {
+	c.baseURL = url
+	return c
+}
+
+type giteaRepoResponse struct {
+	StarsCount  int       `json:"stars_count"`
+	ForksCount  int       `json:"forks_count"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	Description string    `json:"description"`
+	Language    string    `json:"language"`
+	Archived    bool      `json:"archived"`
+}
+
+// GetRepoStats fetches stats for owner/name. Gitea doesn't report rate limits, so -1 is returned.
+func (c *Client) GetRepoStats(ctx context.Context, owner, name string) (*domain.RepoStats, int, error) {
+	url := fmt.Sprintf("%s/api/v1/repos/%s/%s", c.baseURL, owner, name)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, -1, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	if c.token != "" {
+		req.Header.Set("Authorization", "token "+c.token)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, -1, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, -1, domain.ErrRepoNotFound
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return nil, 0, domain.ErrRateLimitExceeded
+	case resp.StatusCode != http.StatusOK:
+		return nil, -1, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	var repo giteaRepoResponse
+	if err := json.NewDecoder(resp.Body).Decode(&repo); err != nil {
+		return nil, -1, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	stats := &domain.RepoStats{
+		Stars:       repo.StarsCount,
+		Forks:       repo.ForksCount,
+		LastPushed:  repo.UpdatedAt,
+		Description: repo.Description,
+		Language:    repo.Language,
+		Archived:    repo.Archived,
+	}
+	return stats, -1, nil
+}
@@ -0,0 +1,40 @@
+package gitea
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/brianluby/karakeep-extractor/internal/core/domain"
+)
+
+func TestClient_GetRepoStats(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/repos/owner/repo" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.Header.Get("Authorization") != "token secret" {
+			t.Errorf("Expected Authorization header")
+		}
+		w.Write([]byte(`{"stars_count": 12, "forks_count": 3, "updated_at": "2024-02-01T00:00:00Z", "description": "Forgejo repo", "language": "Rust"}`))
+	}))
+	defer ts.Close()
+
+	client := NewClient("secret").WithBaseURL(ts.URL)
+
+	stats, _, err := client.GetRepoStats(context.Background(), "owner", "repo")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if stats.Stars != 12 || stats.Forks != 3 || stats.Language != "Rust" {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+
+	_, _, err = client.GetRepoStats(context.Background(), "owner", "missing")
+	if !errors.Is(err, domain.ErrRepoNotFound) {
+		t.Errorf("Expected ErrRepoNotFound, got %v", err)
+	}
+}
@@ -34,14 +34,35 @@ func (c *Client) WithBaseURL(url string) *Client {
 }
 
 type githubRepoResponse struct {
+	FullName        string    `json:"full_name"`
 	StargazersCount int       `json:"stargazers_count"`
 	ForksCount      int       `json:"forks_count"`
 	PushedAt        time.Time `json:"pushed_at"`
 	Description     string    `json:"description"`
 	Language        string    `json:"language"`
+	Archived        bool      `json:"archived"`
+	Topics          []string  `json:"topics"`
+	License         *struct {
+		SPDXID string `json:"spdx_id"`
+	} `json:"license"`
+	Fork   bool `json:"fork"`
+	Parent *struct {
+		FullName string `json:"full_name"`
+	} `json:"parent"`
+	OpenIssuesCount int       `json:"open_issues_count"`
+	Homepage        string    `json:"homepage"`
+	CreatedAt       time.Time `json:"created_at"`
+	DefaultBranch   string    `json:"default_branch"`
+	Size            int       `json:"size"` // KB
 }
 
 func (c *Client) GetRepoStats(ctx context.Context, owner, repo string) (*domain.RepoStats, int, error) {
+	return c.GetRepoStatsIfChanged(ctx, owner, repo, "", "")
+}
+
+// GetRepoStatsIfChanged sends the cache validators from a previous fetch. GitHub answers an
+// unchanged repo with 304, which doesn't count against the rate limit, and we return ErrNotModified.
+func (c *Client) GetRepoStatsIfChanged(ctx context.Context, owner, repo, etag, lastModified string) (*domain.RepoStats, int, error) {
 	url := fmt.Sprintf("%s/repos/%s/%s", c.baseURL, owner, repo)
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
@@ -52,6 +73,12 @@ func (c *Client) GetRepoStats(ctx context.Context, owner, repo string) (*domain.
 		req.Header.Set("Authorization", "token "+c.token)
 	}
 	req.Header.Set("Accept", "application/vnd.github.v3+json")
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	if lastModified != "" {
+		req.Header.Set("If-Modified-Since", lastModified)
+	}
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
@@ -65,11 +92,14 @@ func (c *Client) GetRepoStats(ctx context.Context, owner, repo string) (*domain.
 		remaining, _ = strconv.Atoi(remStr)
 	}
 
+	if resp.StatusCode == http.StatusNotModified {
+		return nil, remaining, domain.ErrNotModified
+	}
 	if resp.StatusCode == http.StatusNotFound {
 		return nil, remaining, domain.ErrRepoNotFound
 	}
-	if resp.StatusCode == http.StatusForbidden && remaining == 0 {
-		return nil, 0, domain.ErrRateLimitExceeded
+	if rlErr := rateLimitError(resp, time.Now()); rlErr != nil {
+		return nil, 0, rlErr
 	}
 	if resp.StatusCode != http.StatusOK {
 		return nil, remaining, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
@@ -81,11 +111,32 @@ func (c *Client) GetRepoStats(ctx context.Context, owner, repo string) (*domain.
 	}
 
 	stats := &domain.RepoStats{
+		FullName:    ghResp.FullName,
 		Stars:       ghResp.StargazersCount,
 		Forks:       ghResp.ForksCount,
 		LastPushed:  ghResp.PushedAt,
 		Description: ghResp.Description,
 		Language:    ghResp.Language,
+		Archived:    ghResp.Archived,
+
+		Topics:        ghResp.Topics,
+		Fork:          ghResp.Fork,
+		OpenIssues:    ghResp.OpenIssuesCount,
+		Homepage:      ghResp.Homepage,
+		CreatedAt:     ghResp.CreatedAt,
+		DefaultBranch: ghResp.DefaultBranch,
+		SizeKB:        ghResp.Size,
+
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}
+
+	// GitHub reports licenses it can't identify as NOASSERTION.
+	if ghResp.License != nil && ghResp.License.SPDXID != "NOASSERTION" {
+		stats.License = ghResp.License.SPDXID
+	}
+	if ghResp.Parent != nil {
+		stats.Parent = ghResp.Parent.FullName
 	}
 
 	return stats, remaining, nil
@@ -2,9 +2,13 @@ package github
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
+
+	"github.com/brianluby/karakeep-extractor/internal/core/domain"
 )
 
 func TestClient_GetRepoStats(t *testing.T) {
@@ -82,4 +86,168 @@ func TestClient_GetRepoStats(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+func TestClient_GetRepoStatsIfChanged(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining", "4999")
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Last-Modified", "Mon, 01 Jan 2024 00:00:00 GMT")
+		w.Write([]byte(`{"stargazers_count": 100}`))
+	}))
+	defer ts.Close()
+
+	client := NewClient("").WithBaseURL(ts.URL)
+
+	stats, _, err := client.GetRepoStats(context.Background(), "owner", "repo")
+	if err != nil {
+		t.Fatalf("GetRepoStats failed: %v", err)
+	}
+	if stats.ETag != `"v1"` || stats.LastModified != "Mon, 01 Jan 2024 00:00:00 GMT" {
+		t.Errorf("Expected cache validators, got %q, %q", stats.ETag, stats.LastModified)
+	}
+
+	_, _, err = client.GetRepoStatsIfChanged(context.Background(), "owner", "repo", stats.ETag, stats.LastModified)
+	if !errors.Is(err, domain.ErrNotModified) {
+		t.Errorf("Expected ErrNotModified, got %v", err)
+	}
+}
+
+func TestClient_GetRepoStats_Renamed(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/repos/old-owner/repo" {
+			http.Redirect(w, r, "/repositories/42", http.StatusMovedPermanently)
+			return
+		}
+		w.Write([]byte(`{"full_name": "new-owner/repo", "stargazers_count": 7}`))
+	}))
+	defer ts.Close()
+
+	stats, _, err := NewClient("").WithBaseURL(ts.URL).GetRepoStats(context.Background(), "old-owner", "repo")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if stats.FullName != "new-owner/repo" || stats.Stars != 7 {
+		t.Errorf("Expected canonical name from the redirect target, got %+v", stats)
+	}
+}
+
+func TestClient_GetRepoStats_Metadata(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{
+			"full_name": "owner/repo",
+			"topics": ["cli", "go"],
+			"license": {"key": "mit", "spdx_id": "MIT"},
+			"fork": true,
+			"parent": {"full_name": "upstream/repo"},
+			"open_issues_count": 12,
+			"homepage": "https://example.com",
+			"created_at": "2019-05-01T00:00:00Z",
+			"default_branch": "main",
+			"size": 2048
+		}`))
+	}))
+	defer ts.Close()
+
+	stats, _, err := NewClient("").WithBaseURL(ts.URL).GetRepoStats(context.Background(), "owner", "repo")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(stats.Topics) != 2 || stats.License != "MIT" || !stats.Fork || stats.Parent != "upstream/repo" ||
+		stats.OpenIssues != 12 || stats.Homepage != "https://example.com" || stats.CreatedAt.Year() != 2019 ||
+		stats.DefaultBranch != "main" || stats.SizeKB != 2048 {
+		t.Errorf("Unexpected metadata: %+v", stats)
+	}
+}
+
+func TestClient_GetReadme(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/repos/owner/empty/readme" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		// "# Hello\n" base64-encoded and wrapped like GitHub does.
+		w.Write([]byte(`{"sha": "abc123", "encoding": "base64", "content": "IyBIZWxs\nbwo=\n"}`))
+	}))
+	defer ts.Close()
+
+	client := NewClient("").WithBaseURL(ts.URL)
+	readme, err := client.GetReadme(context.Background(), "owner", "repo")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if readme.SHA != "abc123" || readme.Content != "# Hello\n" {
+		t.Errorf("Unexpected readme: %+v", readme)
+	}
+
+	if _, err := client.GetReadme(context.Background(), "owner", "empty"); !errors.Is(err, domain.ErrNoReadme) {
+		t.Errorf("Expected ErrNoReadme, got %v", err)
+	}
+}
+
+func TestClient_GetLatestRelease(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/owner/released/releases/latest":
+			w.Write([]byte(`{"tag_name": "v1.2.0", "published_at": "2024-03-01T12:00:00Z", "prerelease": false}`))
+		case "/repos/owner/tagged/tags":
+			w.Write([]byte(`[{"name": "v0.10.0-rc1", "commit": {"sha": "cafe"}}, {"name": "v0.9.0", "commit": {"sha": "deadbeef"}}, {"name": "v0.10.0", "commit": {"sha": "f00d"}}, {"name": "nightly", "commit": {"sha": "beef"}}]`))
+		case "/repos/owner/tagged/commits/f00d":
+			w.Write([]byte(`{"commit": {"committer": {"date": "2024-02-01T08:00:00Z"}}}`))
+		case "/repos/owner/bare/tags":
+			w.Write([]byte(`[]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	client := NewClient("").WithBaseURL(ts.URL)
+	ctx := context.Background()
+
+	release, err := client.GetLatestRelease(ctx, "owner", "released")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if release.Tag != "v1.2.0" || release.FromTag || !release.PublishedAt.Equal(time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected release: %+v", release)
+	}
+
+	// Without releases, the highest version tag is used, dated by its commit.
+	release, err = client.GetLatestRelease(ctx, "owner", "tagged")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if release.Tag != "v0.10.0" || !release.FromTag || !release.PublishedAt.Equal(time.Date(2024, 2, 1, 8, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected tag release: %+v", release)
+	}
+
+	if _, err := client.GetLatestRelease(ctx, "owner", "bare"); !errors.Is(err, domain.ErrNoRelease) {
+		t.Errorf("Expected ErrNoRelease, got %v", err)
+	}
+}
+
+func TestLatestTag(t *testing.T) {
+	tests := []struct {
+		tags []string
+		want string
+	}{
+		{[]string{"v1.2.9", "v1.2.10", "v1.2"}, "v1.2.10"},
+		{[]string{"v2.0.0-beta", "v1.9.0", "v2.0.0"}, "v2.0.0"},
+		{[]string{"v2.0.0-beta", "v1.9.0"}, "v2.0.0-beta"},
+		{[]string{"latest", "release-2024.01.05", "release-2023.12.30"}, "release-2024.01.05"},
+		{[]string{"stable", "edge"}, "stable"},
+	}
+	for _, tc := range tests {
+		var tags []tagResponse
+		for _, name := range tc.tags {
+			tags = append(tags, tagResponse{Name: name})
+		}
+		if got := latestTag(tags).Name; got != tc.want {
+			t.Errorf("latestTag(%v) = %s, want %s", tc.tags, got, tc.want)
+		}
+	}
+}
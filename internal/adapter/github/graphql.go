@@ -0,0 +1,252 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/brianluby/karakeep-extractor/internal/core/domain"
+)
+
+// MaxGraphQLBatch is the number of repositories requested per GraphQL query.
+const MaxGraphQLBatch = 100
+
+// GraphQLClient fetches repository stats through the GitHub GraphQL API,
+// batching up to MaxGraphQLBatch aliased repository lookups into a single query.
+type GraphQLClient struct {
+	token      string
+	baseURL    string
+	batchSize  int
+	httpClient *http.Client
+}
+
+func NewGraphQLClient(token string) *GraphQLClient {
+	return &GraphQLClient{
+		token:     token,
+		baseURL:   "https://api.github.com",
+		batchSize: MaxGraphQLBatch,
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// WithBaseURL allows overriding the base URL for testing.
+func (c *GraphQLClient) WithBaseURL(url string) *GraphQLClient {
+	c.baseURL = url
+	return c
+}
+
+// WithBatchSize overrides the number of repositories per query (capped at MaxGraphQLBatch).
+func (c *GraphQLClient) WithBatchSize(n int) *GraphQLClient {
+	if n > 0 && n <= MaxGraphQLBatch {
+		c.batchSize = n
+	}
+	return c
+}
+
+func (c *GraphQLClient) BatchSize() int {
+	return c.batchSize
+}
+
+const repoFields = `nameWithOwner stargazerCount forkCount pushedAt description isArchived primaryLanguage { name } ` +
+	`repositoryTopics(first: 20) { nodes { topic { name } } } licenseInfo { spdxId } isFork parent { nameWithOwner } ` +
+	`issues(states: OPEN) { totalCount } homepageUrl createdAt defaultBranchRef { name } diskUsage`
+
+type graphqlRequest struct {
+	Query     string            `json:"query"`
+	Variables map[string]string `json:"variables"`
+}
+
+type graphqlRepo struct {
+	NameWithOwner   string     `json:"nameWithOwner"`
+	StargazerCount  int        `json:"stargazerCount"`
+	ForkCount       int        `json:"forkCount"`
+	PushedAt        *time.Time `json:"pushedAt"`
+	Description     string     `json:"description"`
+	IsArchived      bool       `json:"isArchived"`
+	PrimaryLanguage *struct {
+		Name string `json:"name"`
+	} `json:"primaryLanguage"`
+	RepositoryTopics struct {
+		Nodes []struct {
+			Topic struct {
+				Name string `json:"name"`
+			} `json:"topic"`
+		} `json:"nodes"`
+	} `json:"repositoryTopics"`
+	LicenseInfo *struct {
+		SPDXID string `json:"spdxId"`
+	} `json:"licenseInfo"`
+	IsFork bool `json:"isFork"`
+	Parent *struct {
+		NameWithOwner string `json:"nameWithOwner"`
+	} `json:"parent"`
+	Issues struct {
+		TotalCount int `json:"totalCount"`
+	} `json:"issues"`
+	HomepageURL      string     `json:"homepageUrl"`
+	CreatedAt        *time.Time `json:"createdAt"`
+	DefaultBranchRef *struct {
+		Name string `json:"name"`
+	} `json:"defaultBranchRef"`
+	DiskUsage int `json:"diskUsage"` // KB
+}
+
+type graphqlError struct {
+	Type    string        `json:"type"`
+	Path    []interface{} `json:"path"`
+	Message string        `json:"message"`
+}
+
+type graphqlResponse struct {
+	Data   map[string]*graphqlRepo `json:"data"`
+	Errors []graphqlError          `json:"errors"`
+}
+
+// GetRepoStats fetches a single repository; it is a batch of one.
+func (c *GraphQLClient) GetRepoStats(ctx context.Context, owner, name string) (*domain.RepoStats, int, error) {
+	results, remaining, err := c.GetRepoStatsBatch(ctx, []domain.RepoRef{{Forge: domain.ForgeGitHub, Host: domain.GitHubHost, Owner: owner, Name: name}})
+	if err != nil {
+		return nil, remaining, err
+	}
+	res := results[owner+"/"+name]
+	return res.Stats, remaining, res.Err
+}
+
+// GetRepoStatsBatch fetches all repos in one query. The returned error is only set when the
+// whole request failed (network, auth, rate limit); per-repo failures are reported in the map.
+func (c *GraphQLClient) GetRepoStatsBatch(ctx context.Context, repos []domain.RepoRef) (map[string]domain.RepoStatsResult, int, error) {
+	if len(repos) == 0 {
+		return map[string]domain.RepoStatsResult{}, 0, nil
+	}
+	if len(repos) > MaxGraphQLBatch {
+		return nil, 0, fmt.Errorf("batch of %d exceeds maximum of %d repositories", len(repos), MaxGraphQLBatch)
+	}
+
+	// Variables keep owner/name out of the query text, so no escaping is needed.
+	var params, nodes []string
+	vars := make(map[string]string, len(repos)*2)
+	for i, r := range repos {
+		params = append(params, fmt.Sprintf("$o%d: String!, $n%d: String!", i, i))
+		nodes = append(nodes, fmt.Sprintf("r%d: repository(owner: $o%d, name: $n%d) { %s }", i, i, i, repoFields))
+		vars[fmt.Sprintf("o%d", i)] = r.Owner
+		vars[fmt.Sprintf("n%d", i)] = r.Name
+	}
+	query := fmt.Sprintf("query(%s) { %s }", strings.Join(params, ", "), strings.Join(nodes, " "))
+
+	body, err := json.Marshal(graphqlRequest{Query: query, Variables: vars})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to encode query: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/graphql", bytes.NewReader(body))
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "bearer "+c.token)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	remaining := 0
+	if remStr := resp.Header.Get("X-RateLimit-Remaining"); remStr != "" {
+		remaining, _ = strconv.Atoi(remStr)
+	}
+
+	if rlErr := rateLimitError(resp, time.Now()); rlErr != nil {
+		return nil, 0, rlErr
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, remaining, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	var gqlResp graphqlResponse
+	if err := json.NewDecoder(resp.Body).Decode(&gqlResp); err != nil {
+		return nil, remaining, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	// Attribute errors to their alias; errors without a path fail the whole query.
+	aliasErrs := make(map[string]error)
+	for _, e := range gqlResp.Errors {
+		if e.Type == "RATE_LIMITED" {
+			return nil, 0, &domain.RateLimitError{ResetAt: resetTime(resp.Header, time.Now())}
+		}
+		alias := ""
+		if len(e.Path) > 0 {
+			alias, _ = e.Path[0].(string)
+		}
+		if alias == "" {
+			return nil, remaining, fmt.Errorf("graphql error: %s", e.Message)
+		}
+		if e.Type == "NOT_FOUND" {
+			aliasErrs[alias] = domain.ErrRepoNotFound
+		} else {
+			aliasErrs[alias] = fmt.Errorf("graphql error: %s", e.Message)
+		}
+	}
+
+	results := make(map[string]domain.RepoStatsResult, len(repos))
+	for i, r := range repos {
+		alias := fmt.Sprintf("r%d", i)
+		key := r.Owner + "/" + r.Name
+
+		if err, ok := aliasErrs[alias]; ok {
+			results[key] = domain.RepoStatsResult{Err: err}
+			continue
+		}
+		node := gqlResp.Data[alias]
+		if node == nil {
+			results[key] = domain.RepoStatsResult{Err: domain.ErrRepoNotFound}
+			continue
+		}
+
+		stats := &domain.RepoStats{
+			FullName:    node.NameWithOwner,
+			Stars:       node.StargazerCount,
+			Forks:       node.ForkCount,
+			Description: node.Description,
+			Archived:    node.IsArchived,
+
+			Fork:       node.IsFork,
+			OpenIssues: node.Issues.TotalCount,
+			Homepage:   node.HomepageURL,
+			SizeKB:     node.DiskUsage,
+		}
+		for _, t := range node.RepositoryTopics.Nodes {
+			stats.Topics = append(stats.Topics, t.Topic.Name)
+		}
+		if node.LicenseInfo != nil && node.LicenseInfo.SPDXID != "NOASSERTION" {
+			stats.License = node.LicenseInfo.SPDXID
+		}
+		if node.Parent != nil {
+			stats.Parent = node.Parent.NameWithOwner
+		}
+		if node.CreatedAt != nil {
+			stats.CreatedAt = *node.CreatedAt
+		}
+		if node.DefaultBranchRef != nil {
+			stats.DefaultBranch = node.DefaultBranchRef.Name
+		}
+		if node.PushedAt != nil {
+			stats.LastPushed = *node.PushedAt
+		}
+		if node.PrimaryLanguage != nil {
+			stats.Language = node.PrimaryLanguage.Name
+		}
+		results[key] = domain.RepoStatsResult{Stats: stats}
+	}
+
+	return results, remaining, nil
+}
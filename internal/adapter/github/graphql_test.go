@@ -0,0 +1,87 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/brianluby/karakeep-extractor/internal/core/domain"
+)
+
+func TestGraphQLClient_GetRepoStatsBatch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/graphql" || r.Method != http.MethodPost {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if r.Header.Get("Authorization") != "bearer valid-token" {
+			t.Errorf("Expected bearer Authorization header")
+		}
+
+		var req graphqlRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("Failed to decode request: %v", err)
+		}
+		if !strings.Contains(req.Query, "r0: repository(owner: $o0, name: $n0)") || !strings.Contains(req.Query, "r1: repository(owner: $o1, name: $n1)") {
+			t.Errorf("Expected aliased repository nodes, got %s", req.Query)
+		}
+		if req.Variables["o0"] != "owner" || req.Variables["n1"] != "missing" {
+			t.Errorf("Unexpected variables: %v", req.Variables)
+		}
+
+		w.Header().Set("X-RateLimit-Remaining", "4990")
+		w.Write([]byte(`{
+			"data": {
+				"r0": {"nameWithOwner": "owner/repo", "stargazerCount": 100, "forkCount": 20, "pushedAt": "2023-01-01T12:00:00Z", "description": "Test Repo", "primaryLanguage": {"name": "Go"},
+					"repositoryTopics": {"nodes": [{"topic": {"name": "cli"}}]}, "licenseInfo": {"spdxId": "NOASSERTION"}, "issues": {"totalCount": 3}, "defaultBranchRef": {"name": "main"}},
+				"r1": null
+			},
+			"errors": [{"type": "NOT_FOUND", "path": ["r1"], "message": "Could not resolve to a Repository"}]
+		}`))
+	}))
+	defer server.Close()
+
+	client := NewGraphQLClient("valid-token").WithBaseURL(server.URL)
+	results, remaining, err := client.GetRepoStatsBatch(context.Background(), []domain.RepoRef{
+		{Owner: "owner", Name: "repo"},
+		{Owner: "owner", Name: "missing"},
+	})
+	if err != nil {
+		t.Fatalf("GetRepoStatsBatch failed: %v", err)
+	}
+	if remaining != 4990 {
+		t.Errorf("Expected remaining 4990, got %d", remaining)
+	}
+
+	found := results["owner/repo"]
+	if found.Err != nil || found.Stats == nil {
+		t.Fatalf("Expected stats for owner/repo, got %+v", found)
+	}
+	if found.Stats.Stars != 100 || found.Stats.Forks != 20 || found.Stats.Language != "Go" || found.Stats.FullName != "owner/repo" {
+		t.Errorf("Unexpected stats: %+v", found.Stats)
+	}
+
+	if len(found.Stats.Topics) != 1 || found.Stats.License != "" || found.Stats.OpenIssues != 3 || found.Stats.DefaultBranch != "main" {
+		t.Errorf("Unexpected metadata: %+v", found.Stats)
+	}
+
+	if !errors.Is(results["owner/missing"].Err, domain.ErrRepoNotFound) {
+		t.Errorf("Expected ErrRepoNotFound for owner/missing, got %v", results["owner/missing"].Err)
+	}
+}
+
+func TestGraphQLClient_RateLimited(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"errors": [{"type": "RATE_LIMITED", "message": "API rate limit exceeded"}]}`))
+	}))
+	defer server.Close()
+
+	client := NewGraphQLClient("valid-token").WithBaseURL(server.URL)
+	_, _, err := client.GetRepoStats(context.Background(), "owner", "repo")
+	if !errors.Is(err, domain.ErrRateLimitExceeded) {
+		t.Errorf("Expected ErrRateLimitExceeded, got %v", err)
+	}
+}
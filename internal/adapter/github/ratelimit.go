@@ -0,0 +1,56 @@
+package github
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/brianluby/karakeep-extractor/internal/core/domain"
+)
+
+// rateLimitError inspects a response and returns a *domain.RateLimitError if it is a
+// primary (quota exhausted) or secondary (abuse detection) rate limit, nil otherwise.
+// It may consume the response body.
+func rateLimitError(resp *http.Response, now time.Time) *domain.RateLimitError {
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+		return nil
+	}
+
+	var rlErr *domain.RateLimitError
+	if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		rlErr = &domain.RateLimitError{}
+	} else {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		msg := strings.ToLower(string(body))
+		if strings.Contains(msg, "secondary rate limit") || strings.Contains(msg, "abuse") || resp.Header.Get("Retry-After") != "" {
+			rlErr = &domain.RateLimitError{Secondary: true}
+		} else if resp.StatusCode == http.StatusTooManyRequests {
+			rlErr = &domain.RateLimitError{}
+		} else {
+			return nil // A plain 403 (e.g. bad token or blocked repo).
+		}
+	}
+
+	rlErr.ResetAt = resetTime(resp.Header, now)
+	return rlErr
+}
+
+// resetTime derives when requests may resume, preferring Retry-After over X-RateLimit-Reset.
+func resetTime(h http.Header, now time.Time) time.Time {
+	if ra := h.Get("Retry-After"); ra != "" {
+		if secs, err := strconv.Atoi(ra); err == nil {
+			return now.Add(time.Duration(secs) * time.Second)
+		}
+		if t, err := http.ParseTime(ra); err == nil {
+			return t
+		}
+	}
+	if reset := h.Get("X-RateLimit-Reset"); reset != "" {
+		if unix, err := strconv.ParseInt(reset, 10, 64); err == nil {
+			return time.Unix(unix, 0)
+		}
+	}
+	return time.Time{}
+}
@@ -0,0 +1,92 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/brianluby/karakeep-extractor/internal/core/domain"
+)
+
+func TestClient_RateLimitErrors(t *testing.T) {
+	tests := []struct {
+		name          string
+		handler       func(w http.ResponseWriter, r *http.Request)
+		wantRateLimit bool
+		wantSecondary bool
+		wantReset     time.Time
+	}{
+		{
+			name: "Primary limit with reset header",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("X-RateLimit-Remaining", "0")
+				w.Header().Set("X-RateLimit-Reset", "1700000000")
+				w.WriteHeader(http.StatusForbidden)
+			},
+			wantRateLimit: true,
+			wantReset:     time.Unix(1700000000, 0),
+		},
+		{
+			name: "Secondary limit with Retry-After",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("X-RateLimit-Remaining", "4000")
+				w.Header().Set("Retry-After", "60")
+				w.WriteHeader(http.StatusForbidden)
+				w.Write([]byte(`{"message": "You have exceeded a secondary rate limit."}`))
+			},
+			wantRateLimit: true,
+			wantSecondary: true,
+		},
+		{
+			name: "Too many requests",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusTooManyRequests)
+			},
+			wantRateLimit: true,
+		},
+		{
+			name: "Plain forbidden",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("X-RateLimit-Remaining", "4000")
+				w.WriteHeader(http.StatusForbidden)
+				w.Write([]byte(`{"message": "Repository access blocked"}`))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(tt.handler))
+			defer ts.Close()
+
+			start := time.Now()
+			_, _, err := NewClient("").WithBaseURL(ts.URL).GetRepoStats(context.Background(), "owner", "repo")
+			if err == nil {
+				t.Fatal("Expected error, got nil")
+			}
+			if errors.Is(err, domain.ErrRateLimitExceeded) != tt.wantRateLimit {
+				t.Fatalf("errors.Is(ErrRateLimitExceeded) = %v, want %v (err: %v)", !tt.wantRateLimit, tt.wantRateLimit, err)
+			}
+			if !tt.wantRateLimit {
+				return
+			}
+
+			var rlErr *domain.RateLimitError
+			if !errors.As(err, &rlErr) {
+				t.Fatalf("Expected *domain.RateLimitError, got %T", err)
+			}
+			if rlErr.Secondary != tt.wantSecondary {
+				t.Errorf("Secondary = %v, want %v", rlErr.Secondary, tt.wantSecondary)
+			}
+			if !tt.wantReset.IsZero() && !rlErr.ResetAt.Equal(tt.wantReset) {
+				t.Errorf("ResetAt = %v, want %v", rlErr.ResetAt, tt.wantReset)
+			}
+			if tt.wantSecondary && rlErr.ResetAt.Before(start.Add(59*time.Second)) {
+				t.Errorf("Expected ResetAt about 60s from now, got %v", rlErr.ResetAt)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package github
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/brianluby/karakeep-extractor/internal/core/domain"
+)
+
+type readmeResponse struct {
+	SHA      string `json:"sha"`
+	Content  string `json:"content"`
+	Encoding string `json:"encoding"`
+}
+
+// GetReadme fetches the repository's preferred README through the contents API.
+func (c *Client) GetReadme(ctx context.Context, owner, repo string) (*domain.Readme, error) {
+	url := fmt.Sprintf("%s/repos/%s/%s/readme", c.baseURL, owner, repo)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "token "+c.token)
+	}
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, domain.ErrNoReadme
+	}
+	if rlErr := rateLimitError(resp, time.Now()); rlErr != nil {
+		return nil, rlErr
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	var rd readmeResponse
+	if err := json.NewDecoder(resp.Body).Decode(&rd); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if rd.Encoding != "base64" {
+		return nil, fmt.Errorf("unsupported readme encoding %q", rd.Encoding)
+	}
+	// GitHub wraps the base64 content at 60 characters.
+	content, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(rd.Content, "\n", ""))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode readme content: %w", err)
+	}
+
+	return &domain.Readme{SHA: rd.SHA, Content: string(content)}, nil
+}
@@ -0,0 +1,180 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/brianluby/karakeep-extractor/internal/core/domain"
+)
+
+type releaseResponse struct {
+	TagName     string    `json:"tag_name"`
+	PublishedAt time.Time `json:"published_at"`
+	Prerelease  bool      `json:"prerelease"`
+}
+
+type tagResponse struct {
+	Name   string `json:"name"`
+	Commit struct {
+		SHA string `json:"sha"`
+	} `json:"commit"`
+}
+
+type commitResponse struct {
+	Commit struct {
+		Committer struct {
+			Date time.Time `json:"date"`
+		} `json:"committer"`
+	} `json:"commit"`
+}
+
+// GetLatestRelease fetches the repository's latest release. Repositories that only push tags
+// fall back to their highest version tag, dated by its commit.
+func (c *Client) GetLatestRelease(ctx context.Context, owner, repo string) (*domain.Release, error) {
+	var rel releaseResponse
+	found, err := c.getJSON(ctx, fmt.Sprintf("/repos/%s/%s/releases/latest", owner, repo), &rel)
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		return &domain.Release{Tag: rel.TagName, PublishedAt: rel.PublishedAt, Prerelease: rel.Prerelease}, nil
+	}
+
+	var tags []tagResponse
+	found, err = c.getJSON(ctx, fmt.Sprintf("/repos/%s/%s/tags?per_page=100", owner, repo), &tags)
+	if err != nil {
+		return nil, err
+	}
+	if !found || len(tags) == 0 {
+		return nil, domain.ErrNoRelease
+	}
+
+	tag := latestTag(tags)
+	var commit commitResponse
+	found, err = c.getJSON(ctx, fmt.Sprintf("/repos/%s/%s/commits/%s", owner, repo, tag.Commit.SHA), &commit)
+	if err != nil {
+		return nil, err
+	}
+	release := &domain.Release{Tag: tag.Name, FromTag: true}
+	if found {
+		release.PublishedAt = commit.Commit.Committer.Date
+	}
+	return release, nil
+}
+
+// latestTag picks the highest version among tags. The tags endpoint isn't ordered by date,
+// and dating every tag would take a request each, so version order stands in for recency.
+// Tags without a version number rank below those with one.
+func latestTag(tags []tagResponse) tagResponse {
+	best := tags[0]
+	bestVersion := parseVersion(best.Name)
+	for _, tag := range tags[1:] {
+		if v := parseVersion(tag.Name); compareVersions(v, bestVersion) > 0 {
+			best, bestVersion = tag, v
+		}
+	}
+	return best
+}
+
+// tagVersion is a tag's numeric version, e.g. [1 2 3] for "v1.2.3", and whether it has a
+// pre-release suffix ("-rc1", "-beta").
+type tagVersion struct {
+	parts      []int
+	prerelease bool
+}
+
+// parseVersion reads the dotted numbers starting at a tag's first digit.
+func parseVersion(tag string) tagVersion {
+	start := strings.IndexFunc(tag, unicode.IsDigit)
+	if start < 0 {
+		return tagVersion{}
+	}
+	var v tagVersion
+	rest := tag[start:]
+	for {
+		end := strings.IndexFunc(rest, func(r rune) bool { return !unicode.IsDigit(r) })
+		if end < 0 {
+			end = len(rest)
+		}
+		n, err := strconv.Atoi(rest[:end])
+		if err != nil {
+			break
+		}
+		v.parts = append(v.parts, n)
+		rest = rest[end:]
+		if len(rest) < 2 || rest[0] != '.' || !unicode.IsDigit(rune(rest[1])) {
+			break
+		}
+		rest = rest[1:]
+	}
+	v.prerelease = rest != "" && (rest[0] == '-' || unicode.IsLetter(rune(rest[0])))
+	return v
+}
+
+func compareVersions(a, b tagVersion) int {
+	for i := 0; i < len(a.parts) || i < len(b.parts); i++ {
+		var x, y int
+		if i < len(a.parts) {
+			x = a.parts[i]
+		}
+		if i < len(b.parts) {
+			y = b.parts[i]
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	switch {
+	case len(a.parts) == 0 && len(b.parts) > 0:
+		return -1
+	case len(b.parts) == 0 && len(a.parts) > 0:
+		return 1
+	case a.prerelease && !b.prerelease:
+		return -1
+	case b.prerelease && !a.prerelease:
+		return 1
+	}
+	return 0
+}
+
+// getJSON decodes a GET response into v. It reports false, without an error, on 404.
+func (c *Client) getJSON(ctx context.Context, path string, v interface{}) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to create request: %w", err)
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "token "+c.token)
+	}
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	if rlErr := rateLimitError(resp, time.Now()); rlErr != nil {
+		return false, rlErr
+	}
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return false, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return true, nil
+}
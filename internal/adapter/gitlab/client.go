@@ -0,0 +1,113 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/brianluby/karakeep-extractor/internal/core/domain"
+)
+
+// Client fetches project metadata from the GitLab REST API (gitlab.com or self-hosted).
+type Client struct {
+	token      string
+	baseURL    string
+	httpClient *http.Client
+}
+
+func NewClient(token string) *Client {
+	return &Client{
+		token:   token,
+		baseURL: "https://gitlab.com",
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+// WithBaseURL points the client at a self-hosted instance (or a test server).
+func (c *Client) WithBaseURL(url string) *Client {
+	c.baseURL = url
+	return c
+}
+
+type gitlabProjectResponse struct {
+	StarCount      int       `json:"star_count"`
+	ForksCount     int       `json:"forks_count"`
+	LastActivityAt time.Time `json:"last_activity_at"`
+	Description    string    `json:"description"`
+	Archived       bool      `json:"archived"`
+}
+
+// GetRepoStats fetches stats for the project at owner/name; owner may include subgroups.
+func (c *Client) GetRepoStats(ctx context.Context, owner, name string) (*domain.RepoStats, int, error) {
+	projectPath := url.PathEscape(owner + "/" + name)
+	endpoint := fmt.Sprintf("%s/api/v4/projects/%s", c.baseURL, projectPath)
+
+	var project gitlabProjectResponse
+	remaining, err := c.get(ctx, endpoint, &project)
+	if err != nil {
+		return nil, remaining, err
+	}
+
+	stats := &domain.RepoStats{
+		Stars:       project.StarCount,
+		Forks:       project.ForksCount,
+		LastPushed:  project.LastActivityAt,
+		Description: project.Description,
+		Archived:    project.Archived,
+	}
+
+	// Languages come from a separate endpoint as {"Go": 80.5, ...}; best effort only.
+	var languages map[string]float64
+	if _, err := c.get(ctx, endpoint+"/languages", &languages); err == nil {
+		best := 0.0
+		for lang, share := range languages {
+			if share > best {
+				best = share
+				stats.Language = lang
+			}
+		}
+	}
+
+	return stats, remaining, nil
+}
+
+func (c *Client) get(ctx context.Context, endpoint string, out interface{}) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return -1, fmt.Errorf("failed to create request: %w", err)
+	}
+	if c.token != "" {
+		req.Header.Set("PRIVATE-TOKEN", c.token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return -1, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	remaining := -1
+	if remStr := resp.Header.Get("RateLimit-Remaining"); remStr != "" {
+		remaining, _ = strconv.Atoi(remStr)
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return remaining, domain.ErrRepoNotFound
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return 0, domain.ErrRateLimitExceeded
+	case resp.StatusCode != http.StatusOK:
+		return remaining, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return remaining, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return remaining, nil
+}
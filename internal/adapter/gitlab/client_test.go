@@ -0,0 +1,47 @@
+package gitlab
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/brianluby/karakeep-extractor/internal/core/domain"
+)
+
+func TestClient_GetRepoStats(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("PRIVATE-TOKEN") != "secret" {
+			t.Errorf("Expected PRIVATE-TOKEN header")
+		}
+		switch r.URL.EscapedPath() {
+		case "/api/v4/projects/group%2Fsub%2Fproject":
+			w.Header().Set("RateLimit-Remaining", "99")
+			w.Write([]byte(`{"star_count": 42, "forks_count": 7, "last_activity_at": "2024-03-01T10:00:00Z", "description": "Nested project"}`))
+		case "/api/v4/projects/group%2Fsub%2Fproject/languages":
+			w.Write([]byte(`{"Go": 80.5, "Shell": 19.5}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	client := NewClient("secret").WithBaseURL(ts.URL)
+
+	stats, rem, err := client.GetRepoStats(context.Background(), "group/sub", "project")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if stats.Stars != 42 || stats.Forks != 7 || stats.Language != "Go" || stats.Description != "Nested project" {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+	if rem != 99 {
+		t.Errorf("Expected rate limit 99, got %d", rem)
+	}
+
+	_, _, err = client.GetRepoStats(context.Background(), "group", "missing")
+	if !errors.Is(err, domain.ErrRepoNotFound) {
+		t.Errorf("Expected ErrRepoNotFound, got %v", err)
+	}
+}
@@ -15,6 +15,7 @@ import (
 const (
 	maxRetries    = 3
 	initialBackoff = 100 * time.Millisecond
+	pageSize      = 100 // Bookmarks per page.
 )
 
 type Client struct {
@@ -150,59 +151,125 @@ func handleErrorResponse(resp *http.Response) error {
 }
 
 
-// FetchBookmarks fetches bookmarks from the Karakeep API.
-func (c *Client) FetchBookmarks(ctx context.Context) ([]domain.RawBookmark, error) {
-	baseURL := strings.TrimSuffix(c.Config.BaseURL, "/")
+// FetchBookmarks fetches bookmarks (including archived ones) from the Karakeep API.
+func (c *Client) FetchBookmarks(ctx context.Context, since time.Time) ([]domain.RawBookmark, error) {
+	bookmarks, _, err := c.FetchBookmarksWithStates(ctx, since)
+	return bookmarks, err
+}
+
+// FetchBookmarksWithStates fetches bookmarks like FetchBookmarks, and also returns the ID and
+// archived state of every bookmark in the account.
+// Karakeep returns bookmarks newest first by creation date and can't sort by modification,
+// so when 'since' is set the pages before the checkpoint are fetched with content, the rest
+// are listed without it, and older bookmarks edited since the checkpoint are fetched one by one.
+// An incremental fetch therefore still costs one light request per page of the whole account;
+// that listing is what finds old bookmarks that were edited, deleted or archived.
+func (c *Client) FetchBookmarksWithStates(ctx context.Context, since time.Time) ([]domain.RawBookmark, []domain.BookmarkState, error) {
 	var allBookmarks []domain.RawBookmark
+	var states []domain.BookmarkState
+	var editedIDs []string
 	var cursor string
-	limit := 100
+	includeContent := true
 
 	for {
-		url := fmt.Sprintf("%s/bookmarks?archived=false&includeContent=true&limit=%d", baseURL, limit)
-		if cursor != "" {
-			url += fmt.Sprintf("&cursor=%s", cursor)
+		page, nextCursor, err := c.fetchPage(ctx, cursor, includeContent)
+		if err != nil {
+			return nil, nil, err
 		}
 
-		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create request: %w", err)
+		reachedCheckpoint := false
+		for _, bm := range page {
+			states = append(states, domain.BookmarkState{ID: bm.ID, Archived: bm.Archived})
+			if since.IsZero() || !bm.CreatedAt.Before(since) {
+				allBookmarks = append(allBookmarks, bm)
+				continue
+			}
+			reachedCheckpoint = true
+			// Older bookmarks are only interesting if they were edited since the checkpoint.
+			if bm.ModifiedAt == nil || bm.ModifiedAt.Before(since) {
+				continue
+			}
+			if includeContent {
+				allBookmarks = append(allBookmarks, bm)
+			} else {
+				editedIDs = append(editedIDs, bm.ID)
+			}
 		}
 
-		resp, err := c.HTTPClient.Do(req)
-		if err != nil {
-			return nil, fmt.Errorf("failed to fetch bookmarks: %w", err)
+		// Past the checkpoint only edits matter, and they are rare: list without content.
+		if reachedCheckpoint {
+			includeContent = false
+		}
+		if nextCursor == "" {
+			break
 		}
-		// Defer inside loop is okay if loop isn't huge, but better to close explicitly
-		// We'll read properly below
+		cursor = nextCursor
+	}
 
-		bodyBytes, err := io.ReadAll(resp.Body)
-		resp.Body.Close()
+	for _, id := range editedIDs {
+		bm, err := c.fetchBookmark(ctx, id)
 		if err != nil {
-			return nil, fmt.Errorf("failed to read response body: %w", err)
+			return nil, nil, err
 		}
+		allBookmarks = append(allBookmarks, bm)
+	}
 
-		if resp.StatusCode != http.StatusOK {
-			return nil, handleErrorResponse(resp)
-		}
+	return allBookmarks, states, nil
+}
 
-		var response struct {
-			Bookmarks  []domain.RawBookmark `json:"bookmarks"`
-			NextCursor *string              `json:"nextCursor"`
-		}
+// fetchPage fetches one page of bookmarks, returning the cursor of the next page ("" on the last).
+func (c *Client) fetchPage(ctx context.Context, cursor string, includeContent bool) ([]domain.RawBookmark, string, error) {
+	baseURL := strings.TrimSuffix(c.Config.BaseURL, "/")
+	url := fmt.Sprintf("%s/bookmarks?includeContent=%t&sortOrder=desc&limit=%d", baseURL, includeContent, pageSize)
+	if cursor != "" {
+		url += fmt.Sprintf("&cursor=%s", cursor)
+	}
 
-		if err := json.Unmarshal(bodyBytes, &response); err != nil {
-			return nil, fmt.Errorf("failed to decode bookmarks: %w", err)
-		}
+	var response struct {
+		Bookmarks  []domain.RawBookmark `json:"bookmarks"`
+		NextCursor *string              `json:"nextCursor"`
+	}
+	if err := c.getJSON(ctx, url, &response); err != nil {
+		return nil, "", fmt.Errorf("failed to fetch bookmarks: %w", err)
+	}
+	if response.NextCursor == nil {
+		return response.Bookmarks, "", nil
+	}
+	return response.Bookmarks, *response.NextCursor, nil
+}
 
-		if len(response.Bookmarks) > 0 {
-			allBookmarks = append(allBookmarks, response.Bookmarks...)
-		}
+// fetchBookmark fetches a single bookmark with its content.
+func (c *Client) fetchBookmark(ctx context.Context, id string) (domain.RawBookmark, error) {
+	baseURL := strings.TrimSuffix(c.Config.BaseURL, "/")
+	var bm domain.RawBookmark
+	if err := c.getJSON(ctx, fmt.Sprintf("%s/bookmarks/%s?includeContent=true", baseURL, id), &bm); err != nil {
+		return domain.RawBookmark{}, fmt.Errorf("failed to fetch bookmark %s: %w", id, err)
+	}
+	return bm, nil
+}
 
-		if response.NextCursor == nil || *response.NextCursor == "" {
-			break
-		}
-		cursor = *response.NextCursor
+// getJSON decodes the response to a GET request into v.
+func (c *Client) getJSON(ctx context.Context, url string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
 	}
 
-	return allBookmarks, nil
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	bodyBytes, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return handleErrorResponse(resp)
+	}
+	if err := json.Unmarshal(bodyBytes, v); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
 }
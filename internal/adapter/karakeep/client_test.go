@@ -6,6 +6,7 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -128,7 +129,8 @@ func TestFetchBookmarks(t *testing.T) {
 					}{URL: "https://example.com/article1", Title: "Article 1", Description: "", HTMLContent: ""},
 				},
 				{
-					ID: "3",
+					ID:   "3",
+					Tags: []domain.Tag{{Name: "go", AttachedBy: domain.TagSourceAI}},
 					Content: struct {
 						URL         string `json:"url"`
 						Title       string `json:"title"`
@@ -149,7 +151,7 @@ func TestFetchBookmarks(t *testing.T) {
 	client := karakeep.NewClient(cfg)
 
 	// Test case: Fetch all bookmarks
-	bookmarks, err := client.FetchBookmarks(context.Background())
+	bookmarks, err := client.FetchBookmarks(context.Background(), time.Time{})
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
@@ -162,4 +164,73 @@ func TestFetchBookmarks(t *testing.T) {
 	if bookmarks[2].Content.URL != "https://github.com/repo2" {
 		t.Errorf("Expected URL https://github.com/repo2, got %s", bookmarks[2].Content.URL)
 	}
+	if len(bookmarks[2].Tags) != 1 || bookmarks[2].Tags[0].AttachedBy != domain.TagSourceAI {
+		t.Errorf("Expected AI-attached tag on bookmark 3, got %+v", bookmarks[2].Tags)
+	}
+}
+
+func TestFetchBookmarks_Since(t *testing.T) {
+	checkpoint := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	edited := checkpoint.Add(time.Minute)
+
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.Path+"?"+r.URL.RawQuery)
+		if r.URL.Path == "/bookmarks/later-edited" {
+			bm := domain.RawBookmark{ID: "later-edited", CreatedAt: checkpoint.Add(-48 * time.Hour), ModifiedAt: &edited}
+			bm.Content.HTMLContent = `<a href="https://github.com/owner/repo">repo</a>`
+			json.NewEncoder(w).Encode(bm)
+			return
+		}
+
+		type page struct {
+			Bookmarks  []domain.RawBookmark `json:"bookmarks"`
+			NextCursor *string              `json:"nextCursor"`
+		}
+		var response page
+		switch r.URL.Query().Get("cursor") {
+		case "":
+			next := "page2"
+			response = page{Bookmarks: []domain.RawBookmark{
+				{ID: "new", CreatedAt: checkpoint.Add(time.Hour)},
+				{ID: "old-edited", CreatedAt: checkpoint.Add(-time.Hour), ModifiedAt: &edited},
+				{ID: "old", CreatedAt: checkpoint.Add(-2 * time.Hour)},
+			}, NextCursor: &next}
+		case "page2":
+			if r.URL.Query().Get("includeContent") != "false" {
+				t.Errorf("Expected pages past the checkpoint to be listed without content")
+			}
+			response = page{Bookmarks: []domain.RawBookmark{
+				{ID: "older", CreatedAt: checkpoint.Add(-24 * time.Hour), Archived: true},
+				{ID: "later-edited", CreatedAt: checkpoint.Add(-48 * time.Hour), ModifiedAt: &edited},
+			}}
+		}
+		json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+
+	client := karakeep.NewClient(&domain.KarakeepConfig{BaseURL: server.URL, APIToken: "test-token"})
+
+	bookmarks, states, err := client.FetchBookmarksWithStates(context.Background(), checkpoint)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(requests) != 3 {
+		t.Errorf("Expected 2 pages and 1 bookmark request, got %v", requests)
+	}
+	var ids []string
+	for _, bm := range bookmarks {
+		ids = append(ids, bm.ID)
+	}
+	if strings.Join(ids, " ") != "new old-edited later-edited" {
+		t.Errorf("Expected bookmarks [new old-edited later-edited], got %v", ids)
+	}
+	// Every listed bookmark is reported for reconciliation, including unchanged ones.
+	if len(states) != 5 || states[3].ID != "older" || !states[3].Archived {
+		t.Errorf("Expected the states of all 5 bookmarks, got %+v", states)
+	}
+	// Edits found on later pages are fetched with their content.
+	if last := bookmarks[len(bookmarks)-1]; last.Content.HTMLContent == "" {
+		t.Errorf("Expected later-edited to include its content, got %+v", last)
+	}
 }
@@ -0,0 +1,208 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/brianluby/karakeep-extractor/internal/core/domain"
+)
+
+const (
+	anthropicBaseURL = "https://api.anthropic.com/v1"
+	anthropicVersion = "2023-06-01"
+	// anthropicMaxTokens is used when the config sets no max_tokens; the Messages API requires one.
+	anthropicMaxTokens = 4096
+)
+
+// AnthropicClient talks to the Anthropic Messages API.
+type AnthropicClient struct {
+	config domain.LLMConfig
+	http   *http.Client
+}
+
+func NewAnthropicClient(cfg domain.LLMConfig) *AnthropicClient {
+	return &AnthropicClient{
+		config: cfg,
+		http:   newHTTPClient(),
+	}
+}
+
+type anthropicContent struct {
+	Type string `json:"type"`
+	Text string `json:"text,omitempty"`
+}
+
+type anthropicMessage struct {
+	Role    string             `json:"role"`
+	Content []anthropicContent `json:"content"`
+}
+
+type anthropicRequest struct {
+	Model     string             `json:"model"`
+	MaxTokens int                `json:"max_tokens"`
+	System    string             `json:"system,omitempty"`
+	Messages  []anthropicMessage `json:"messages"`
+	Stream    bool               `json:"stream,omitempty"`
+}
+
+// anthropicEvent is one server-sent event of a streamed reply.
+type anthropicEvent struct {
+	Type  string `json:"type"`
+	Delta struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"delta"`
+	Error *struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+type anthropicResponse struct {
+	Content    []anthropicContent `json:"content"`
+	StopReason string             `json:"stop_reason"`
+	Error      *struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+// newAnthropicRequest converts a chat request: system messages move to the top-level
+// system field, and the rest become text content blocks.
+func newAnthropicRequest(req domain.AnalysisRequest) anthropicRequest {
+	out := anthropicRequest{Model: req.Model, MaxTokens: req.MaxTokens, Stream: req.Stream}
+	var system []string
+	for _, msg := range req.Messages {
+		if msg.Role == "system" {
+			system = append(system, msg.Content)
+			continue
+		}
+		out.Messages = append(out.Messages, anthropicMessage{
+			Role:    msg.Role,
+			Content: []anthropicContent{{Type: "text", Text: msg.Content}},
+		})
+	}
+	out.System = strings.Join(system, "\n\n")
+	return out
+}
+
+func (c *AnthropicClient) SendMessage(ctx context.Context, req domain.AnalysisRequest) (string, error) {
+	req.Stream = false
+	resp, err := c.post(ctx, req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var response anthropicResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	var text strings.Builder
+	for _, block := range response.Content {
+		if block.Type == "text" {
+			text.WriteString(block.Text)
+		}
+	}
+	if text.Len() == 0 {
+		return "", fmt.Errorf("empty response from LLM")
+	}
+	return text.String(), nil
+}
+
+// StreamMessage is SendMessage, receiving the reply as server-sent events and passing
+// each text delta to onToken as it arrives.
+func (c *AnthropicClient) StreamMessage(ctx context.Context, req domain.AnalysisRequest, onToken func(string)) (string, error) {
+	req.Stream = true
+	resp, err := c.post(ctx, req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var answer strings.Builder
+	err = readSSE(resp.Body, func(_ string, data string) error {
+		var event anthropicEvent
+		if err := json.Unmarshal([]byte(data), &event); err != nil {
+			return fmt.Errorf("failed to decode stream event: %w", err)
+		}
+		switch event.Type {
+		case "content_block_delta":
+			if event.Delta.Type == "text_delta" && event.Delta.Text != "" {
+				answer.WriteString(event.Delta.Text)
+				onToken(event.Delta.Text)
+			}
+		case "error":
+			msg := "unknown error"
+			if event.Error != nil {
+				msg = event.Error.Message
+			}
+			return fmt.Errorf("LLM API error: %s", msg)
+		}
+		return nil
+	})
+	if err != nil {
+		return answer.String(), err
+	}
+	if answer.Len() == 0 {
+		return "", fmt.Errorf("empty response from LLM")
+	}
+	return answer.String(), nil
+}
+
+// post sends req to the Messages endpoint, turning error statuses into errors.
+func (c *AnthropicClient) post(ctx context.Context, req domain.AnalysisRequest) (*http.Response, error) {
+	baseURL := strings.TrimRight(c.config.BaseURL, "/")
+	if baseURL == "" {
+		baseURL = anthropicBaseURL
+	}
+	url := baseURL + "/messages"
+
+	if req.Model == "" {
+		req.Model = c.config.Model
+	}
+	if c.config.MaxTokens > 0 {
+		req.MaxTokens = c.config.MaxTokens
+	}
+	if req.MaxTokens <= 0 {
+		req.MaxTokens = anthropicMaxTokens
+	}
+
+	body, err := json.Marshal(newAnthropicRequest(req))
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("x-api-key", c.config.APIKey)
+	httpReq.Header.Set("anthropic-version", anthropicVersion)
+
+	resp, err := doIdle(c.http, httpReq, idleTimeout(c.config.IdleTimeout, DefaultIdleTimeout))
+	if err != nil {
+		return nil, fmt.Errorf("network error calling LLM: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		if resp.StatusCode == http.StatusUnauthorized {
+			return nil, fmt.Errorf("authentication failed: check your API key in 'karakeep config llm'")
+		}
+		var errResp anthropicResponse
+		json.NewDecoder(resp.Body).Decode(&errResp)
+		msg := "unknown error"
+		if errResp.Error != nil {
+			msg = errResp.Error.Message
+		}
+		return nil, fmt.Errorf("LLM API error (status %d): %s", resp.StatusCode, msg)
+	}
+	return resp, nil
+}
@@ -0,0 +1,125 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/brianluby/karakeep-extractor/internal/core/domain"
+)
+
+func TestAnthropicClient_SendMessage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/v1/messages" {
+			t.Errorf("Expected POST /v1/messages, got %s %s", r.Method, r.URL.Path)
+		}
+		if r.Header.Get("x-api-key") != "test-key" {
+			t.Errorf("Expected x-api-key header, got %q", r.Header.Get("x-api-key"))
+		}
+		if r.Header.Get("anthropic-version") != anthropicVersion {
+			t.Errorf("Expected anthropic-version header, got %q", r.Header.Get("anthropic-version"))
+		}
+		if r.Header.Get("Authorization") != "" {
+			t.Errorf("Unexpected Authorization header")
+		}
+
+		var req anthropicRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("Failed to decode request: %v", err)
+		}
+		if req.Model != "claude-test" || req.MaxTokens != anthropicMaxTokens {
+			t.Errorf("Unexpected model or max_tokens: %+v", req)
+		}
+		if req.System != "You are helpful." {
+			t.Errorf("Expected the system prompt as a top-level field, got %q", req.System)
+		}
+		if len(req.Messages) != 1 || req.Messages[0].Role != "user" ||
+			len(req.Messages[0].Content) != 1 || req.Messages[0].Content[0].Type != "text" || req.Messages[0].Content[0].Text != "Hello" {
+			t.Errorf("Unexpected messages: %+v", req.Messages)
+		}
+
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"content": []map[string]interface{}{
+				{"type": "text", "text": "Test "},
+				{"type": "text", "text": "Response"},
+			},
+			"stop_reason": "end_turn",
+		})
+	}))
+	defer server.Close()
+
+	client := NewAnthropicClient(domain.LLMConfig{
+		Provider: ProviderAnthropic,
+		BaseURL:  server.URL + "/v1/",
+		APIKey:   "test-key",
+		Model:    "claude-test",
+	})
+
+	resp, err := client.SendMessage(context.Background(), domain.AnalysisRequest{
+		Messages: []domain.Message{
+			{Role: "system", Content: "You are helpful."},
+			{Role: "user", Content: "Hello"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("SendMessage failed: %v", err)
+	}
+	if resp != "Test Response" {
+		t.Errorf("Expected 'Test Response', got '%s'", resp)
+	}
+}
+
+func TestAnthropicClient_SendMessage_Error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("x-api-key") == "bad-key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"type":"error","error":{"type":"invalid_request_error","message":"max_tokens: too large"}}`))
+	}))
+	defer server.Close()
+
+	client := NewAnthropicClient(domain.LLMConfig{BaseURL: server.URL, APIKey: "test-key", Model: "claude-test"})
+	_, err := client.SendMessage(context.Background(), domain.AnalysisRequest{Messages: []domain.Message{{Role: "user", Content: "Hi"}}})
+	if err == nil || !strings.Contains(err.Error(), "status 400") || !strings.Contains(err.Error(), "max_tokens: too large") {
+		t.Errorf("Expected the API error message, got %v", err)
+	}
+
+	client = NewAnthropicClient(domain.LLMConfig{BaseURL: server.URL, APIKey: "bad-key"})
+	_, err = client.SendMessage(context.Background(), domain.AnalysisRequest{Messages: []domain.Message{{Role: "user", Content: "Hi"}}})
+	if err == nil || !strings.Contains(err.Error(), "authentication failed") {
+		t.Errorf("Expected an authentication error, got %v", err)
+	}
+}
+
+func TestNewProvider(t *testing.T) {
+	tests := []struct {
+		provider string
+		want     string
+	}{
+		{"", "*llm.Client"},
+		{"openai", "*llm.Client"},
+		{"local", "*llm.Client"},
+		{"Anthropic", "*llm.AnthropicClient"},
+		{"ollama", "*llm.OllamaClient"},
+	}
+	for _, tt := range tests {
+		p, err := NewProvider(domain.LLMConfig{Provider: tt.provider})
+		if err != nil {
+			t.Errorf("NewProvider(%q) failed: %v", tt.provider, err)
+			continue
+		}
+		if got := fmt.Sprintf("%T", p); got != tt.want {
+			t.Errorf("NewProvider(%q) = %s, want %s", tt.provider, got, tt.want)
+		}
+	}
+
+	if _, err := NewProvider(domain.LLMConfig{Provider: "gemini"}); err == nil {
+		t.Error("Expected an error for an unknown provider")
+	}
+}
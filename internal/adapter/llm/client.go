@@ -7,11 +7,11 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
-	"time"
 
 	"github.com/brianluby/karakeep-extractor/internal/core/domain"
 )
 
+// Client talks to OpenAI-compatible chat completions APIs.
 type Client struct {
 	config domain.LLMConfig
 	http   *http.Client
@@ -20,9 +20,7 @@ type Client struct {
 func NewClient(cfg domain.LLMConfig) *Client {
 	return &Client{
 		config: cfg,
-		http: &http.Client{
-			Timeout: 60 * time.Second, // Generous timeout for LLM thinking
-		},
+		http:   newHTTPClient(),
 	}
 }
 
@@ -31,6 +29,9 @@ type openAIResponse struct {
 		Message struct {
 			Content string `json:"content"`
 		} `json:"message"`
+		Delta struct {
+			Content string `json:"content"`
+		} `json:"delta"`
 	} `json:"choices"`
 	Error *struct {
 		Message string `json:"message"`
@@ -38,6 +39,66 @@ type openAIResponse struct {
 }
 
 func (c *Client) SendMessage(ctx context.Context, req domain.AnalysisRequest) (string, error) {
+	req.Stream = false
+	resp, err := c.post(ctx, req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var response openAIResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	if len(response.Choices) == 0 {
+		return "", fmt.Errorf("empty response from LLM")
+	}
+
+	return response.Choices[0].Message.Content, nil
+}
+
+// StreamMessage is SendMessage, receiving the reply as server-sent events and passing
+// each piece to onToken as it arrives.
+func (c *Client) StreamMessage(ctx context.Context, req domain.AnalysisRequest, onToken func(string)) (string, error) {
+	req.Stream = true
+	resp, err := c.post(ctx, req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var answer strings.Builder
+	err = readSSE(resp.Body, func(_ string, data string) error {
+		if data == "[DONE]" {
+			return nil
+		}
+		var chunk openAIResponse
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return fmt.Errorf("failed to decode stream chunk: %w", err)
+		}
+		if chunk.Error != nil {
+			return fmt.Errorf("LLM API error: %s", chunk.Error.Message)
+		}
+		for _, choice := range chunk.Choices {
+			if choice.Delta.Content != "" {
+				answer.WriteString(choice.Delta.Content)
+				onToken(choice.Delta.Content)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return answer.String(), err
+	}
+	if answer.Len() == 0 {
+		return "", fmt.Errorf("empty response from LLM")
+	}
+	return answer.String(), nil
+}
+
+// post sends req to the chat completions endpoint, turning error statuses into errors.
+func (c *Client) post(ctx context.Context, req domain.AnalysisRequest) (*http.Response, error) {
 	// Normalize BaseURL
 	baseURL := strings.TrimRight(c.config.BaseURL, "/")
 	url := fmt.Sprintf("%s/chat/completions", baseURL)
@@ -53,12 +114,12 @@ func (c *Client) SendMessage(ctx context.Context, req domain.AnalysisRequest) (s
 
 	body, err := json.Marshal(req)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal request: %w", err)
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
 	httpReq, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(body))
 	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	httpReq.Header.Set("Content-Type", "application/json")
@@ -66,16 +127,16 @@ func (c *Client) SendMessage(ctx context.Context, req domain.AnalysisRequest) (s
 		httpReq.Header.Set("Authorization", "Bearer "+c.config.APIKey)
 	}
 
-	resp, err := c.http.Do(httpReq)
+	resp, err := doIdle(c.http, httpReq, idleTimeout(c.config.IdleTimeout, DefaultIdleTimeout))
 	if err != nil {
-		return "", fmt.Errorf("network error calling LLM: %w", err)
+		return nil, fmt.Errorf("network error calling LLM: %w", err)
 	}
-	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
 		// Specific handling for 401
 		if resp.StatusCode == http.StatusUnauthorized {
-			return "", fmt.Errorf("authentication failed: check your API key in 'karakeep config llm'")
+			return nil, fmt.Errorf("authentication failed: check your API key in 'karakeep config llm'")
 		}
 
 		// Try to read error from body
@@ -85,17 +146,7 @@ func (c *Client) SendMessage(ctx context.Context, req domain.AnalysisRequest) (s
 		if errResp.Error != nil {
 			msg = errResp.Error.Message
 		}
-		return "", fmt.Errorf("LLM API error (status %d): %s", resp.StatusCode, msg)
+		return nil, fmt.Errorf("LLM API error (status %d): %s", resp.StatusCode, msg)
 	}
-
-	var response openAIResponse
-	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
-		return "", fmt.Errorf("failed to decode response: %w", err)
-	}
-
-	if len(response.Choices) == 0 {
-		return "", fmt.Errorf("empty response from LLM")
-	}
-
-	return response.Choices[0].Message.Content, nil
+	return resp, nil
 }
@@ -0,0 +1,215 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/brianluby/karakeep-extractor/internal/core/domain"
+)
+
+const ollamaBaseURL = "http://localhost:11434"
+
+// ollamaIdleTimeout is longer than DefaultIdleTimeout since Ollama may first load the model.
+const ollamaIdleTimeout = 5 * time.Minute
+
+// OllamaClient talks to Ollama's native API.
+type OllamaClient struct {
+	config domain.LLMConfig
+	http   *http.Client
+}
+
+func NewOllamaClient(cfg domain.LLMConfig) *OllamaClient {
+	return &OllamaClient{
+		config: cfg,
+		http:   newHTTPClient(),
+	}
+}
+
+type ollamaChatRequest struct {
+	Model    string                 `json:"model"`
+	Messages []domain.Message       `json:"messages"`
+	Stream   bool                   `json:"stream"`
+	Options  map[string]interface{} `json:"options,omitempty"`
+}
+
+type ollamaChatResponse struct {
+	Message domain.Message `json:"message"`
+	Done    bool           `json:"done"`
+	Error   string         `json:"error,omitempty"`
+}
+
+// OllamaModel is a model installed in Ollama.
+type OllamaModel struct {
+	Name          string
+	Size          int64 // Bytes on disk.
+	ParameterSize string
+	Quantization  string
+}
+
+func (c *OllamaClient) baseURL() string {
+	baseURL := strings.TrimRight(c.config.BaseURL, "/")
+	if baseURL == "" {
+		return ollamaBaseURL
+	}
+	// Tolerate an OpenAI-style base URL pointing at Ollama's compatibility endpoint.
+	return strings.TrimSuffix(baseURL, "/v1")
+}
+
+// options merges the configured options with max_tokens, which Ollama calls num_predict.
+func (c *OllamaClient) options(maxTokens int) map[string]interface{} {
+	opts := make(map[string]interface{}, len(c.config.Options)+1)
+	if maxTokens > 0 {
+		opts["num_predict"] = maxTokens
+	}
+	for k, v := range c.config.Options {
+		opts[k] = v
+	}
+	if len(opts) == 0 {
+		return nil
+	}
+	return opts
+}
+
+func (c *OllamaClient) SendMessage(ctx context.Context, req domain.AnalysisRequest) (string, error) {
+	req.Stream = false
+	resp, err := c.chat(ctx, req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var response ollamaChatResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+	if response.Message.Content == "" {
+		return "", fmt.Errorf("empty response from LLM")
+	}
+	return response.Message.Content, nil
+}
+
+// StreamMessage is SendMessage, receiving the reply as newline-delimited JSON and passing
+// each piece to onToken as it arrives.
+func (c *OllamaClient) StreamMessage(ctx context.Context, req domain.AnalysisRequest, onToken func(string)) (string, error) {
+	req.Stream = true
+	resp, err := c.chat(ctx, req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var answer strings.Builder
+	decoder := json.NewDecoder(resp.Body)
+	for {
+		var chunk ollamaChatResponse
+		if err := decoder.Decode(&chunk); err == io.EOF {
+			break
+		} else if err != nil {
+			return answer.String(), fmt.Errorf("failed to decode stream chunk: %w", err)
+		}
+		if chunk.Error != "" {
+			return answer.String(), fmt.Errorf("LLM API error: %s", chunk.Error)
+		}
+		if chunk.Message.Content != "" {
+			answer.WriteString(chunk.Message.Content)
+			onToken(chunk.Message.Content)
+		}
+		if chunk.Done {
+			break
+		}
+	}
+	if answer.Len() == 0 {
+		return "", fmt.Errorf("empty response from LLM")
+	}
+	return answer.String(), nil
+}
+
+// chat sends req to /api/chat, turning error statuses into errors.
+func (c *OllamaClient) chat(ctx context.Context, req domain.AnalysisRequest) (*http.Response, error) {
+	if req.Model == "" {
+		req.Model = c.config.Model
+	}
+	if c.config.MaxTokens > 0 {
+		req.MaxTokens = c.config.MaxTokens
+	}
+
+	body, err := json.Marshal(ollamaChatRequest{
+		Model:    req.Model,
+		Messages: req.Messages,
+		Stream:   req.Stream,
+		Options:  c.options(req.MaxTokens),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.baseURL()+"/api/chat", bytes.NewBuffer(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := doIdle(c.http, httpReq, idleTimeout(c.config.IdleTimeout, ollamaIdleTimeout))
+	if err != nil {
+		return nil, fmt.Errorf("network error calling Ollama (is it running?): %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		var errResp ollamaChatResponse
+		json.NewDecoder(resp.Body).Decode(&errResp)
+		msg := "unknown error"
+		if errResp.Error != "" {
+			msg = errResp.Error
+		}
+		return nil, fmt.Errorf("LLM API error (status %d): %s", resp.StatusCode, msg)
+	}
+	return resp, nil
+}
+
+// ListModels returns the models installed in Ollama (GET /api/tags).
+func (c *OllamaClient) ListModels(ctx context.Context) ([]OllamaModel, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, "GET", c.baseURL()+"/api/tags", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := c.http.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("network error calling Ollama (is it running?): %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to list Ollama models (status %d)", resp.StatusCode)
+	}
+
+	var tags struct {
+		Models []struct {
+			Name    string `json:"name"`
+			Size    int64  `json:"size"`
+			Details struct {
+				ParameterSize     string `json:"parameter_size"`
+				QuantizationLevel string `json:"quantization_level"`
+			} `json:"details"`
+		} `json:"models"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tags); err != nil {
+		return nil, fmt.Errorf("failed to decode model list: %w", err)
+	}
+
+	models := make([]OllamaModel, 0, len(tags.Models))
+	for _, m := range tags.Models {
+		models = append(models, OllamaModel{
+			Name:          m.Name,
+			Size:          m.Size,
+			ParameterSize: m.Details.ParameterSize,
+			Quantization:  m.Details.QuantizationLevel,
+		})
+	}
+	return models, nil
+}
@@ -0,0 +1,99 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/brianluby/karakeep-extractor/internal/core/domain"
+)
+
+func TestOllamaClient_SendMessage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/api/chat" {
+			t.Errorf("Expected POST /api/chat, got %s %s", r.Method, r.URL.Path)
+		}
+
+		var req map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("Failed to decode request: %v", err)
+		}
+		if req["model"] != "llama3.2" || req["stream"] != false {
+			t.Errorf("Unexpected model or stream: %v", req)
+		}
+		opts, _ := req["options"].(map[string]interface{})
+		if opts["num_ctx"] != float64(8192) || opts["num_predict"] != float64(512) {
+			t.Errorf("Expected num_ctx and num_predict options, got %v", req["options"])
+		}
+		msgs, _ := req["messages"].([]interface{})
+		if len(msgs) != 2 {
+			t.Errorf("Expected 2 messages, got %v", req["messages"])
+		}
+
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"model":   "llama3.2",
+			"message": map[string]interface{}{"role": "assistant", "content": "Test Response"},
+			"done":    true,
+		})
+	}))
+	defer server.Close()
+
+	client := NewOllamaClient(domain.LLMConfig{
+		Provider:  ProviderOllama,
+		BaseURL:   server.URL + "/v1", // OpenAI-compatible URL, trimmed to the native API.
+		Model:     "llama3.2",
+		MaxTokens: 512,
+		Options:   map[string]interface{}{"num_ctx": 8192},
+	})
+
+	resp, err := client.SendMessage(context.Background(), domain.AnalysisRequest{
+		Messages: []domain.Message{
+			{Role: "system", Content: "You are helpful."},
+			{Role: "user", Content: "Hello"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("SendMessage failed: %v", err)
+	}
+	if resp != "Test Response" {
+		t.Errorf("Expected 'Test Response', got '%s'", resp)
+	}
+}
+
+func TestOllamaClient_SendMessage_Error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error":"model \"nope\" not found, try pulling it first"}`))
+	}))
+	defer server.Close()
+
+	client := NewOllamaClient(domain.LLMConfig{BaseURL: server.URL, Model: "nope"})
+	_, err := client.SendMessage(context.Background(), domain.AnalysisRequest{Messages: []domain.Message{{Role: "user", Content: "Hi"}}})
+	if err == nil || !strings.Contains(err.Error(), "status 404") || !strings.Contains(err.Error(), "try pulling it first") {
+		t.Errorf("Expected Ollama's error message, got %v", err)
+	}
+}
+
+func TestOllamaClient_ListModels(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" || r.URL.Path != "/api/tags" {
+			t.Errorf("Expected GET /api/tags, got %s %s", r.Method, r.URL.Path)
+		}
+		w.Write([]byte(`{"models":[
+			{"name":"llama3.2:latest","size":2019393189,"details":{"parameter_size":"3.2B","quantization_level":"Q4_K_M"}},
+			{"name":"qwen2.5:7b","size":4683087332,"details":{"parameter_size":"7.6B","quantization_level":"Q4_K_M"}}
+		]}`))
+	}))
+	defer server.Close()
+
+	models, err := NewOllamaClient(domain.LLMConfig{BaseURL: server.URL}).ListModels(context.Background())
+	if err != nil {
+		t.Fatalf("ListModels failed: %v", err)
+	}
+	if len(models) != 2 || models[0].Name != "llama3.2:latest" || models[1].ParameterSize != "7.6B" || models[1].Size != 4683087332 {
+		t.Errorf("Unexpected models: %+v", models)
+	}
+}
@@ -0,0 +1,50 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/brianluby/karakeep-extractor/internal/core/domain"
+)
+
+// Provider names accepted in LLMConfig.Provider.
+const (
+	ProviderOpenAI    = "openai"
+	ProviderAnthropic = "anthropic"
+	ProviderOllama    = "ollama"
+	ProviderLocal     = "local" // Any OpenAI-compatible server, e.g. LM Studio or llama.cpp.
+)
+
+// Provider sends a conversation to an LLM and returns its reply, either whole or
+// streamed piece by piece to onToken.
+type Provider interface {
+	SendMessage(ctx context.Context, req domain.AnalysisRequest) (string, error)
+	StreamMessage(ctx context.Context, req domain.AnalysisRequest, onToken func(string)) (string, error)
+}
+
+// NewProvider returns the client for cfg.Provider; an empty provider means OpenAI.
+func NewProvider(cfg domain.LLMConfig) (Provider, error) {
+	switch strings.ToLower(cfg.Provider) {
+	case "", ProviderOpenAI, ProviderLocal:
+		return NewClient(cfg), nil
+	case ProviderAnthropic:
+		return NewAnthropicClient(cfg), nil
+	case ProviderOllama:
+		return NewOllamaClient(cfg), nil
+	}
+	return nil, fmt.Errorf("unknown LLM provider %q (valid: %s, %s, %s, %s)", cfg.Provider, ProviderOpenAI, ProviderAnthropic, ProviderOllama, ProviderLocal)
+}
+
+// Defaults returns the base URL and model 'config llm' suggests for a provider.
+func Defaults(provider string) (baseURL string, model string) {
+	switch strings.ToLower(provider) {
+	case ProviderAnthropic:
+		return anthropicBaseURL, "claude-sonnet-4-5"
+	case ProviderOllama:
+		return ollamaBaseURL, "llama3.2"
+	case ProviderLocal:
+		return "http://localhost:1234/v1", ""
+	}
+	return "https://api.openai.com/v1", "gpt-4o"
+}
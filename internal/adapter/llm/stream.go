@@ -0,0 +1,112 @@
+package llm
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultIdleTimeout is how long a response may go without new data before it is abandoned.
+// There is no limit on the total time, so long streamed answers aren't cut off.
+const DefaultIdleTimeout = 60 * time.Second
+
+var errIdleTimeout = errors.New("idle timeout")
+
+// newHTTPClient returns a client without an overall timeout; doIdle bounds the wait instead.
+func newHTTPClient() *http.Client {
+	return &http.Client{}
+}
+
+// idleTimeout returns the configured idle timeout, or fallback when unset.
+func idleTimeout(seconds int, fallback time.Duration) time.Duration {
+	if seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	return fallback
+}
+
+// doIdle sends req, abandoning it once the server sends nothing for idle: while waiting for
+// the response headers, or between reads of the body. The caller closes the response body.
+func doIdle(client *http.Client, req *http.Request, idle time.Duration) (*http.Response, error) {
+	ctx, cancel := context.WithCancelCause(req.Context())
+	timer := time.AfterFunc(idle, func() { cancel(errIdleTimeout) })
+
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		timer.Stop()
+		if errors.Is(context.Cause(ctx), errIdleTimeout) {
+			err = idleError(idle)
+		}
+		cancel(nil)
+		return nil, err
+	}
+	resp.Body = &idleBody{ReadCloser: resp.Body, ctx: ctx, cancel: cancel, timer: timer, idle: idle}
+	return resp, nil
+}
+
+func idleError(idle time.Duration) error {
+	return fmt.Errorf("LLM stopped responding (no data for %s)", idle)
+}
+
+// idleBody restarts the idle timer whenever data arrives.
+type idleBody struct {
+	io.ReadCloser
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+	timer  *time.Timer
+	idle   time.Duration
+}
+
+func (b *idleBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err != nil && errors.Is(context.Cause(b.ctx), errIdleTimeout) {
+		return n, idleError(b.idle)
+	}
+	b.timer.Reset(b.idle)
+	return n, err
+}
+
+func (b *idleBody) Close() error {
+	b.timer.Stop()
+	b.cancel(nil)
+	return b.ReadCloser.Close()
+}
+
+// readSSE calls fn with the event name and data of each server-sent event in r.
+func readSSE(r io.Reader, fn func(event string, data string) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var event string
+	var data []string
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if len(data) > 0 {
+				if err := fn(event, strings.Join(data, "\n")); err != nil {
+					return err
+				}
+			}
+			event, data = "", nil
+		case strings.HasPrefix(line, ":"):
+			// Comment, used as a keep-alive.
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			data = append(data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read stream: %w", err)
+	}
+	if len(data) > 0 {
+		return fn(event, strings.Join(data, "\n"))
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brianluby/karakeep-extractor/internal/core/domain"
+)
+
+// streamServer writes each chunk and flushes it, pausing between them.
+func streamServer(t *testing.T, contentType string, pause time.Duration, chunks []string, check func(body map[string]interface{})) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatalf("Failed to decode request: %v", err)
+		}
+		if check != nil {
+			check(body)
+		}
+		w.Header().Set("Content-Type", contentType)
+		for _, chunk := range chunks {
+			io.WriteString(w, chunk)
+			w.(http.Flusher).Flush()
+			time.Sleep(pause)
+		}
+	}))
+}
+
+func TestStreamMessage(t *testing.T) {
+	expectStream := func(body map[string]interface{}) {
+		if body["stream"] != true {
+			t.Errorf("Expected stream: true, got %v", body["stream"])
+		}
+	}
+
+	tests := []struct {
+		name   string
+		server *httptest.Server
+		client func(url string) Provider
+	}{
+		{
+			name: "openai",
+			server: streamServer(t, "text/event-stream", 0, []string{
+				": keep-alive\n\n",
+				`data: {"choices":[{"delta":{"role":"assistant"}}]}` + "\n\n",
+				`data: {"choices":[{"delta":{"content":"Hello"}}]}` + "\n\n",
+				`data: {"choices":[{"delta":{"content":", world"}}]}` + "\n\n",
+				"data: [DONE]\n\n",
+			}, expectStream),
+			client: func(url string) Provider { return NewClient(domain.LLMConfig{BaseURL: url, Model: "gpt-test"}) },
+		},
+		{
+			name: "anthropic",
+			server: streamServer(t, "text/event-stream", 0, []string{
+				"event: message_start\ndata: {\"type\":\"message_start\",\"message\":{}}\n\n",
+				"event: content_block_start\ndata: {\"type\":\"content_block_start\",\"index\":0,\"content_block\":{\"type\":\"text\",\"text\":\"\"}}\n\n",
+				"event: ping\ndata: {\"type\":\"ping\"}\n\n",
+				"event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"text_delta\",\"text\":\"Hello\"}}\n\n",
+				"event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"text_delta\",\"text\":\", world\"}}\n\n",
+				"event: message_stop\ndata: {\"type\":\"message_stop\"}\n\n",
+			}, expectStream),
+			client: func(url string) Provider {
+				return NewAnthropicClient(domain.LLMConfig{BaseURL: url, APIKey: "k", Model: "claude-test"})
+			},
+		},
+		{
+			name: "ollama",
+			server: streamServer(t, "application/x-ndjson", 0, []string{
+				`{"message":{"role":"assistant","content":"Hello"},"done":false}` + "\n",
+				`{"message":{"role":"assistant","content":", world"},"done":false}` + "\n",
+				`{"message":{"role":"assistant","content":""},"done":true}` + "\n",
+			}, expectStream),
+			client: func(url string) Provider { return NewOllamaClient(domain.LLMConfig{BaseURL: url, Model: "llama3.2"}) },
+		},
+	}
+
+	for _, tt := range tests {
+		defer tt.server.Close()
+		var tokens []string
+		answer, err := tt.client(tt.server.URL).StreamMessage(context.Background(),
+			domain.AnalysisRequest{Messages: []domain.Message{{Role: "user", Content: "Hi"}}},
+			func(token string) { tokens = append(tokens, token) })
+		if err != nil {
+			t.Errorf("%s: StreamMessage failed: %v", tt.name, err)
+			continue
+		}
+		if answer != "Hello, world" || strings.Join(tokens, "|") != "Hello|, world" {
+			t.Errorf("%s: got answer %q from tokens %q", tt.name, answer, tokens)
+		}
+	}
+}
+
+func TestStreamMessage_ErrorEvent(t *testing.T) {
+	server := streamServer(t, "text/event-stream", 0, []string{
+		"event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"delta\":{\"type\":\"text_delta\",\"text\":\"Hel\"}}\n\n",
+		"event: error\ndata: {\"type\":\"error\",\"error\":{\"type\":\"overloaded_error\",\"message\":\"Overloaded\"}}\n\n",
+	}, nil)
+	defer server.Close()
+
+	client := NewAnthropicClient(domain.LLMConfig{BaseURL: server.URL, APIKey: "k"})
+	answer, err := client.StreamMessage(context.Background(), domain.AnalysisRequest{Messages: []domain.Message{{Role: "user", Content: "Hi"}}}, func(string) {})
+	if err == nil || !strings.Contains(err.Error(), "Overloaded") {
+		t.Errorf("Expected the stream error, got %v", err)
+	}
+	if answer != "Hel" {
+		t.Errorf("Expected the partial answer, got %q", answer)
+	}
+}
+
+func TestDoIdle(t *testing.T) {
+	chunks := []string{"a", "b", "c", "d"}
+
+	// Steady data keeps the request alive past the idle timeout in total.
+	server := streamServer(t, "text/plain", 40*time.Millisecond, chunks, nil)
+	defer server.Close()
+	body := fetchIdle(t, server.URL, 100*time.Millisecond)
+	if body.err != nil || body.text != "abcd" {
+		t.Errorf("Expected the full body, got %q, %v", body.text, body.err)
+	}
+
+	// A stall longer than the idle timeout abandons the request.
+	stalled := streamServer(t, "text/plain", 300*time.Millisecond, chunks, nil)
+	defer stalled.Close()
+	body = fetchIdle(t, stalled.URL, 100*time.Millisecond)
+	if body.err == nil || !strings.Contains(body.err.Error(), "stopped responding") {
+		t.Errorf("Expected an idle timeout, got %q, %v", body.text, body.err)
+	}
+}
+
+type idleResult struct {
+	text string
+	err  error
+}
+
+func fetchIdle(t *testing.T, url string, idle time.Duration) idleResult {
+	req, err := http.NewRequest("POST", url, strings.NewReader("{}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := doIdle(newHTTPClient(), req, idle)
+	if err != nil {
+		return idleResult{err: err}
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	return idleResult{text: string(data), err: err}
+}
+
+func TestReadSSE(t *testing.T) {
+	input := "event: a\ndata: one\ndata: two\n\n: comment\n\ndata:three\n"
+	var got []string
+	err := readSSE(strings.NewReader(input), func(event string, data string) error {
+		got = append(got, fmt.Sprintf("%s=%s", event, data))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("readSSE failed: %v", err)
+	}
+	if strings.Join(got, ";") != "a=one\ntwo;=three" {
+		t.Errorf("Unexpected events: %q", got)
+	}
+}
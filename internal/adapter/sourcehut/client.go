@@ -0,0 +1,119 @@
+package sourcehut
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/brianluby/karakeep-extractor/internal/core/domain"
+)
+
+// Client fetches repository metadata from the git.sr.ht GraphQL API.
+// sourcehut has no stars or forks, so only description and last update are filled in.
+type Client struct {
+	token      string
+	baseURL    string
+	httpClient *http.Client
+}
+
+func NewClient(token string) *Client {
+	return &Client{
+		token:   token,
+		baseURL: "https://git.sr.ht",
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+// WithBaseURL allows overriding the base URL for testing.
+func (c *Client) WithBaseURL(url string) *Client {
+	c.baseURL = url
+	return c
+}
+
+const repositoryQuery = `query($owner: String!, $name: String!) {
+  user(username: $owner) {
+    repository(name: $name) {
+      description
+      updated
+    }
+  }
+}`
+
+type graphQLRequest struct {
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables"`
+}
+
+type repositoryResponse struct {
+	Data struct {
+		User *struct {
+			Repository *struct {
+				Description *string   `json:"description"`
+				Updated     time.Time `json:"updated"`
+			} `json:"repository"`
+		} `json:"user"`
+	} `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// GetRepoStats fetches stats for ~owner/name. The rate limit is not reported, so -1 is returned.
+func (c *Client) GetRepoStats(ctx context.Context, owner, name string) (*domain.RepoStats, int, error) {
+	payload, err := json.Marshal(graphQLRequest{
+		Query: repositoryQuery,
+		Variables: map[string]interface{}{
+			"owner": strings.TrimPrefix(owner, "~"),
+			"name":  name,
+		},
+	})
+	if err != nil {
+		return nil, -1, fmt.Errorf("failed to marshal query: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/query", bytes.NewReader(payload))
+	if err != nil {
+		return nil, -1, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, -1, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return nil, 0, domain.ErrRateLimitExceeded
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, -1, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	var result repositoryResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, -1, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if result.Data.User == nil || result.Data.User.Repository == nil {
+		if len(result.Errors) > 0 && result.Data.User != nil {
+			return nil, -1, fmt.Errorf("sourcehut API error: %s", result.Errors[0].Message)
+		}
+		return nil, -1, domain.ErrRepoNotFound
+	}
+
+	repo := result.Data.User.Repository
+	stats := &domain.RepoStats{LastPushed: repo.Updated}
+	if repo.Description != nil {
+		stats.Description = *repo.Description
+	}
+	return stats, -1, nil
+}
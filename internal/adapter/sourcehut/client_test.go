@@ -0,0 +1,48 @@
+package sourcehut
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/brianluby/karakeep-extractor/internal/core/domain"
+)
+
+func TestClient_GetRepoStats(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/query" || r.Method != http.MethodPost {
+			t.Errorf("Expected POST /query, got %s %s", r.Method, r.URL.Path)
+		}
+		var req graphQLRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("Failed to decode request: %v", err)
+		}
+		if req.Variables["owner"] != "sircmpwn" {
+			t.Errorf("Expected owner without ~, got %v", req.Variables["owner"])
+		}
+		if req.Variables["name"] == "missing" {
+			w.Write([]byte(`{"data": {"user": {"repository": null}}}`))
+			return
+		}
+		w.Write([]byte(`{"data": {"user": {"repository": {"description": "Static site generator", "updated": "2024-04-01T12:00:00Z"}}}}`))
+	}))
+	defer ts.Close()
+
+	client := NewClient("token").WithBaseURL(ts.URL)
+
+	stats, _, err := client.GetRepoStats(context.Background(), "~sircmpwn", "repo")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if stats.Description != "Static site generator" || stats.LastPushed.IsZero() {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+
+	_, _, err = client.GetRepoStats(context.Background(), "~sircmpwn", "missing")
+	if !errors.Is(err, domain.ErrRepoNotFound) {
+		t.Errorf("Expected ErrRepoNotFound, got %v", err)
+	}
+}
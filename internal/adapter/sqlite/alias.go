@@ -0,0 +1,115 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// queryRower is satisfied by both *sql.DB and *sql.Tx.
+type queryRower interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// resolveAlias returns the ID that repoID is stored under: the repo it was renamed or transferred
+// to, if any, and the stored casing of that ID. A repo that isn't stored yet keeps its ID.
+func resolveAlias(ctx context.Context, q queryRower, repoID string) (string, error) {
+	var canonical string
+	err := q.QueryRowContext(ctx, `SELECT repo_id FROM repo_aliases WHERE alias_id = ?1 COLLATE NOCASE
+		ORDER BY alias_id = ?1 DESC LIMIT 1;`, repoID).Scan(&canonical)
+	if errors.Is(err, sql.ErrNoRows) {
+		canonical = repoID
+	} else if err != nil {
+		return "", fmt.Errorf("failed to resolve alias for %s: %w", repoID, err)
+	}
+
+	stored, err := storedRepoID(ctx, q, canonical)
+	if err != nil || stored == "" {
+		return canonical, err
+	}
+	return stored, nil
+}
+
+// storedRepoID returns the ID of the stored repo that matches repoID apart from case, preferring an
+// exact match, or "" if there is none. Forges treat owner and name case-insensitively, but repo_id
+// compares case-sensitively, so "Owner/Name" and "owner/name" would otherwise be two repos.
+func storedRepoID(ctx context.Context, q queryRower, repoID string) (string, error) {
+	var stored string
+	err := q.QueryRowContext(ctx, `SELECT repo_id FROM extracted_repos WHERE repo_id = ?1 COLLATE NOCASE
+		ORDER BY repo_id = ?1 DESC LIMIT 1;`, repoID).Scan(&stored)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to look up %s: %w", repoID, err)
+	}
+	return stored, nil
+}
+
+// moveRepo moves a repo to its canonical ID and records the old ID as an alias. If the canonical
+// repo is already stored, the two are merged: tags, bookmark links and history are combined, and
+// the canonical row takes the stats of the repo being moved, which were just fetched.
+func (r *SQLiteRepository) moveRepo(ctx context.Context, tx *sql.Tx, from, to, url string) error {
+	stored, err := storedRepoID(ctx, tx, to)
+	if err != nil {
+		return err
+	}
+	// Merge into the stored repo even if the forge spells its ID in another case.
+	exists := stored != ""
+	if exists {
+		to = stored
+	}
+
+	// Statements take ?1 = old ID, ?2 = canonical ID, ?3 = canonical URL.
+	var stmts []string
+	if !exists {
+		stmts = []string{
+			// Tags move first: renaming the repo refreshes its search entry, which reads them.
+			`UPDATE repo_tags SET repo_id = ?2 WHERE repo_id = ?1;`,
+			`UPDATE bookmark_repos SET repo_id = ?2 WHERE repo_id = ?1;`,
+			`UPDATE extracted_repos SET repo_id = ?2, url = ?3 WHERE repo_id = ?1;`,
+		}
+	} else {
+		stmts = []string{
+			// A tag both repos carry counts as human-attached if either has it that way.
+			`INSERT INTO repo_tags (repo_id, tag_id, attached_by)
+			SELECT ?2, tag_id, attached_by FROM repo_tags WHERE repo_id = ?1
+			ON CONFLICT(repo_id, tag_id) DO UPDATE SET attached_by = MAX(attached_by, excluded.attached_by);`,
+			`INSERT INTO bookmark_repos (bookmark_id, repo_id, kind, linked_at)
+			SELECT bookmark_id, ?2, kind, linked_at FROM bookmark_repos WHERE repo_id = ?1
+			ON CONFLICT(bookmark_id, repo_id) DO UPDATE SET kind = 'primary' WHERE excluded.kind = 'primary';`,
+			`UPDATE extracted_repos SET
+				(stars, forks, last_pushed_at, description, language, archived, enrichment_status, etag, last_modified, last_checked_at,
+					topics, license, is_fork, parent, open_issues, homepage, created_at, default_branch, size_kb,
+					readme_sha, readme_text, readme_checked_at,
+					release_tag, release_published_at, release_prerelease, release_from_tag, release_checked_at) = (
+					SELECT stars, forks, last_pushed_at, description, language, archived, enrichment_status, etag, last_modified, last_checked_at,
+						topics, license, is_fork, parent, open_issues, homepage, created_at, default_branch, size_kb,
+						readme_sha, readme_text, readme_checked_at,
+						release_tag, release_published_at, release_prerelease, release_from_tag, release_checked_at
+					FROM extracted_repos WHERE repo_id = ?1),
+				found_at = MIN(found_at, (SELECT found_at FROM extracted_repos WHERE repo_id = ?1)),
+				orphaned = orphaned AND (SELECT orphaned FROM extracted_repos WHERE repo_id = ?1),
+				bookmark_archived = bookmark_archived AND (SELECT bookmark_archived FROM extracted_repos WHERE repo_id = ?1)
+			WHERE repo_id = ?2;`,
+			`DELETE FROM repo_tags WHERE repo_id = ?1;`,
+			`DELETE FROM bookmark_repos WHERE repo_id = ?1;`,
+			`DELETE FROM extracted_repos WHERE repo_id = ?1;`,
+		}
+	}
+	stmts = append(stmts,
+		`UPDATE repo_stats_history SET repo_id = ?2 WHERE repo_id = ?1;`,
+		// Earlier aliases follow the repo to its new home.
+		`UPDATE repo_aliases SET repo_id = ?2 WHERE repo_id = ?1;`,
+		`DELETE FROM repo_aliases WHERE alias_id = ?2;`,
+		`INSERT OR REPLACE INTO repo_aliases (alias_id, repo_id) VALUES (?1, ?2);`,
+	)
+
+	for _, stmt := range stmts {
+		if _, err := tx.ExecContext(ctx, stmt, from, to, url); err != nil {
+			return fmt.Errorf("failed to move %s to %s: %w", from, to, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package sqlite
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/brianluby/karakeep-extractor/internal/core/domain"
+)
+
+// filterColumns maps numeric and date filter fields onto SQL expressions over extracted_repos er.
+var filterColumns = map[domain.FilterField]string{
+	domain.FilterStars:     "er.stars",
+	domain.FilterForks:     "er.forks",
+	domain.FilterIssues:    "er.open_issues",
+	domain.FilterSize:      "er.size_kb",
+	domain.FilterBookmarks: "(SELECT COUNT(*) FROM bookmark_repos br WHERE br.repo_id = er.repo_id)",
+	domain.FilterPushed:    "julianday(er.last_pushed_at)",
+	domain.FilterCreated:   "julianday(er.created_at)",
+}
+
+// filterSQL turns parsed --where terms into SQL conditions to AND onto a query over
+// extracted_repos er. Every value is passed as a parameter.
+func filterSQL(terms []domain.FilterTerm) (string, []interface{}, error) {
+	var b strings.Builder
+	var args []interface{}
+	for _, term := range terms {
+		cond, condArgs, err := termSQL(term)
+		if err != nil {
+			return "", nil, err
+		}
+		// NULLs (e.g. no language) count as not matching, so negated terms include them.
+		if term.Negate {
+			cond = "NOT COALESCE((" + cond + "), 0)"
+		}
+		b.WriteString(" AND " + cond)
+		args = append(args, condArgs...)
+	}
+	return b.String(), args, nil
+}
+
+func termSQL(term domain.FilterTerm) (string, []interface{}, error) {
+	switch term.Field {
+	case domain.FilterText:
+		pattern := "%" + escapeLike(term.Value) + "%"
+		return `(er.repo_id LIKE ? ESCAPE '\' OR er.title LIKE ? ESCAPE '\' OR er.description LIKE ? ESCAPE '\')`,
+			[]interface{}{pattern, pattern, pattern}, nil
+	case domain.FilterLang:
+		return "er.language = ? COLLATE NOCASE", []interface{}{term.Value}, nil
+	case domain.FilterLicense:
+		return "er.license = ? COLLATE NOCASE", []interface{}{term.Value}, nil
+	case domain.FilterForge:
+		return "er.forge = ? COLLATE NOCASE", []interface{}{term.Value}, nil
+	case domain.FilterTopic:
+		return "EXISTS (SELECT 1 FROM json_each(er.topics) WHERE json_each.value = ? COLLATE NOCASE)", []interface{}{term.Value}, nil
+	case domain.FilterTag:
+		return `EXISTS (SELECT 1 FROM repo_tags rt JOIN tags t ON rt.tag_id = t.id
+			WHERE rt.repo_id = er.repo_id AND t.name = ? COLLATE NOCASE)`, []interface{}{term.Value}, nil
+	case domain.FilterIs:
+		switch term.Value {
+		case domain.FilterIsFork:
+			return "er.is_fork = 1", nil, nil
+		case domain.FilterIsArchived:
+			return "er.archived = 1", nil, nil
+		}
+		return "", nil, fmt.Errorf("unsupported filter is:%s", term.Value)
+	case domain.FilterPushed, domain.FilterCreated:
+		return dateSQL(filterColumns[term.Field], term)
+	}
+
+	column, ok := filterColumns[term.Field]
+	if !ok {
+		return "", nil, fmt.Errorf("unsupported filter field %q", term.Field)
+	}
+	if term.Op == domain.OpRange {
+		var conds []string
+		var args []interface{}
+		if term.Number != nil {
+			conds, args = append(conds, column+" >= ?"), append(args, *term.Number)
+		}
+		if term.Upper != nil {
+			conds, args = append(conds, column+" <= ?"), append(args, *term.Upper)
+		}
+		return "(" + strings.Join(conds, " AND ") + ")", args, nil
+	}
+	if term.Number == nil {
+		return "", nil, fmt.Errorf("missing value for filter %s", term.Field)
+	}
+	switch term.Op {
+	case domain.OpEq, domain.OpGt, domain.OpGte, domain.OpLt, domain.OpLte:
+		return fmt.Sprintf("%s %s ?", column, term.Op), []interface{}{*term.Number}, nil
+	}
+	return "", nil, fmt.Errorf("unsupported operator %q for filter %s", term.Op, term.Field)
+}
+
+// dateSQL compares at day granularity: a date stands for the whole day, so "pushed:>2024-01-01"
+// starts on the 2nd and "pushed:2024-01-01" covers the 1st.
+func dateSQL(column string, term domain.FilterTerm) (string, []interface{}, error) {
+	day := func(t time.Time) string { return t.UTC().Format(time.RFC3339) }
+	nextDay := func(t time.Time) string { return t.AddDate(0, 0, 1).UTC().Format(time.RFC3339) }
+
+	switch term.Op {
+	case domain.OpEq:
+		return fmt.Sprintf("(%s >= julianday(?) AND %s < julianday(?))", column, column), []interface{}{day(term.Date), nextDay(term.Date)}, nil
+	case domain.OpGt:
+		return column + " >= julianday(?)", []interface{}{nextDay(term.Date)}, nil
+	case domain.OpGte:
+		return column + " >= julianday(?)", []interface{}{day(term.Date)}, nil
+	case domain.OpLt:
+		return column + " < julianday(?)", []interface{}{day(term.Date)}, nil
+	case domain.OpLte:
+		return column + " < julianday(?)", []interface{}{nextDay(term.Date)}, nil
+	case domain.OpRange:
+		var conds []string
+		var args []interface{}
+		if !term.Date.IsZero() {
+			conds, args = append(conds, column+" >= julianday(?)"), append(args, day(term.Date))
+		}
+		if !term.DateUpper.IsZero() {
+			conds, args = append(conds, column+" < julianday(?)"), append(args, nextDay(term.DateUpper))
+		}
+		return "(" + strings.Join(conds, " AND ") + ")", args, nil
+	}
+	return "", nil, fmt.Errorf("unsupported operator %q for filter %s", term.Op, term.Field)
+}
+
+// escapeLike escapes LIKE wildcards so text terms match literally.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -0,0 +1,377 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// migration is one numbered, forward-only schema change. Each runs in its own transaction.
+// Databases created before versioning have no schema_migrations rows, so every migration
+// must tolerate the objects it creates already existing (IF NOT EXISTS, addColumnIfMissing).
+type migration struct {
+	version int
+	name    string
+	up      func(ctx context.Context, tx *sql.Tx) error
+}
+
+// migrations must stay ordered by version; never edit or renumber one that has shipped.
+var migrations = []migration{
+	{1, "create extracted_repos and tags", func(ctx context.Context, tx *sql.Tx) error {
+		return execAll(ctx, tx,
+			`CREATE TABLE IF NOT EXISTS extracted_repos (
+				repo_id TEXT PRIMARY KEY,
+				url TEXT NOT NULL,
+				source_id TEXT,
+				title TEXT,
+				found_at DATETIME DEFAULT CURRENT_TIMESTAMP
+			);`,
+			`CREATE TABLE IF NOT EXISTS tags (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				name TEXT UNIQUE NOT NULL
+			);`,
+			`CREATE TABLE IF NOT EXISTS repo_tags (
+				repo_id TEXT NOT NULL,
+				tag_id INTEGER NOT NULL,
+				PRIMARY KEY (repo_id, tag_id),
+				FOREIGN KEY (repo_id) REFERENCES extracted_repos(repo_id) ON DELETE CASCADE,
+				FOREIGN KEY (tag_id) REFERENCES tags(id) ON DELETE CASCADE
+			);`,
+		)
+	}},
+	{2, "add enrichment columns", func(ctx context.Context, tx *sql.Tx) error {
+		return addColumnsIfMissing(ctx, tx, "extracted_repos", []columnDef{
+			{"stars", "INTEGER"},
+			{"forks", "INTEGER"},
+			{"last_pushed_at", "DATETIME"},
+			{"description", "TEXT"},
+			{"language", "TEXT"},
+			{"enrichment_status", "TEXT DEFAULT 'PENDING'"},
+		})
+	}},
+	{3, "create sync_state", func(ctx context.Context, tx *sql.Tx) error {
+		return execAll(ctx, tx,
+			`CREATE TABLE IF NOT EXISTS sync_state (
+				source TEXT PRIMARY KEY,
+				last_bookmark_id TEXT,
+				last_bookmark_at DATETIME,
+				last_run_at DATETIME
+			);`,
+		)
+	}},
+	{4, "add bookmark state columns", func(ctx context.Context, tx *sql.Tx) error {
+		return addColumnsIfMissing(ctx, tx, "extracted_repos", []columnDef{
+			{"orphaned", "INTEGER NOT NULL DEFAULT 0"},
+			{"bookmark_archived", "INTEGER NOT NULL DEFAULT 0"},
+		})
+	}},
+	{5, "add forge column", func(ctx context.Context, tx *sql.Tx) error {
+		return addColumnsIfMissing(ctx, tx, "extracted_repos", []columnDef{
+			{"forge", "TEXT NOT NULL DEFAULT 'github'"},
+		})
+	}},
+	{6, "add cache validator columns", func(ctx context.Context, tx *sql.Tx) error {
+		return addColumnsIfMissing(ctx, tx, "extracted_repos", []columnDef{
+			{"etag", "TEXT"},
+			{"last_modified", "TEXT"},
+			{"last_checked_at", "DATETIME"},
+		})
+	}},
+	{7, "create repo_stats_history", func(ctx context.Context, tx *sql.Tx) error {
+		return execAll(ctx, tx,
+			`CREATE TABLE IF NOT EXISTS repo_stats_history (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				repo_id TEXT NOT NULL,
+				recorded_at DATETIME NOT NULL,
+				stars INTEGER,
+				forks INTEGER,
+				pushed_at DATETIME,
+				FOREIGN KEY (repo_id) REFERENCES extracted_repos(repo_id) ON DELETE CASCADE
+			);`,
+			`CREATE INDEX IF NOT EXISTS idx_repo_stats_history_repo ON repo_stats_history(repo_id, recorded_at);`,
+		)
+	}},
+	{8, "add archived column", func(ctx context.Context, tx *sql.Tx) error {
+		return addColumnsIfMissing(ctx, tx, "extracted_repos", []columnDef{
+			{"archived", "INTEGER NOT NULL DEFAULT 0"},
+		})
+	}},
+	{9, "add tag attribution", func(ctx context.Context, tx *sql.Tx) error {
+		return addColumnsIfMissing(ctx, tx, "repo_tags", []columnDef{
+			{"attached_by", "TEXT NOT NULL DEFAULT 'human'"},
+		})
+	}},
+	{10, "create bookmark_repos", func(ctx context.Context, tx *sql.Tx) error {
+		return execAll(ctx, tx,
+			`CREATE TABLE IF NOT EXISTS bookmark_repos (
+				bookmark_id TEXT NOT NULL,
+				repo_id TEXT NOT NULL,
+				kind TEXT NOT NULL,
+				linked_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+				PRIMARY KEY (bookmark_id, repo_id),
+				FOREIGN KEY (repo_id) REFERENCES extracted_repos(repo_id) ON DELETE CASCADE
+			);`,
+			`CREATE INDEX IF NOT EXISTS idx_bookmark_repos_repo ON bookmark_repos(repo_id);`,
+			// Existing repos only remember their first bookmark, and not how it linked the repo.
+			`INSERT OR IGNORE INTO bookmark_repos (bookmark_id, repo_id, kind)
+			SELECT source_id, repo_id, 'primary' FROM extracted_repos
+			WHERE source_id IS NOT NULL AND source_id != '';`,
+		)
+	}},
+	{11, "create repo_aliases", func(ctx context.Context, tx *sql.Tx) error {
+		return execAll(ctx, tx,
+			`CREATE TABLE IF NOT EXISTS repo_aliases (
+				alias_id TEXT PRIMARY KEY,
+				repo_id TEXT NOT NULL,
+				created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+			);`,
+			`CREATE INDEX IF NOT EXISTS idx_repo_aliases_repo ON repo_aliases(repo_id);`,
+		)
+	}},
+	{12, "add repository metadata columns", func(ctx context.Context, tx *sql.Tx) error {
+		err := addColumnsIfMissing(ctx, tx, "extracted_repos", []columnDef{
+			{"topics", "TEXT"}, // JSON array
+			{"license", "TEXT"},
+			{"is_fork", "INTEGER NOT NULL DEFAULT 0"},
+			{"parent", "TEXT"},
+			{"open_issues", "INTEGER"},
+			{"homepage", "TEXT"},
+			{"created_at", "DATETIME"},
+			{"default_branch", "TEXT"},
+			{"size_kb", "INTEGER"},
+		})
+		if err != nil {
+			return err
+		}
+		// Drop cache validators so the next enrich refetches instead of getting 304s without the new fields.
+		return execAll(ctx, tx, `UPDATE extracted_repos SET etag = NULL, last_modified = NULL;`)
+	}},
+	{13, "add readme columns", func(ctx context.Context, tx *sql.Tx) error {
+		return addColumnsIfMissing(ctx, tx, "extracted_repos", []columnDef{
+			{"readme_sha", "TEXT"},
+			{"readme_text", "TEXT"},
+			{"readme_checked_at", "DATETIME"},
+		})
+	}},
+	{14, "add release columns", func(ctx context.Context, tx *sql.Tx) error {
+		return addColumnsIfMissing(ctx, tx, "extracted_repos", []columnDef{
+			{"release_tag", "TEXT"},
+			{"release_published_at", "DATETIME"},
+			{"release_prerelease", "INTEGER NOT NULL DEFAULT 0"},
+			{"release_from_tag", "INTEGER NOT NULL DEFAULT 0"},
+			{"release_checked_at", "DATETIME"},
+		})
+	}},
+	{15, "add release_seen_at", func(ctx context.Context, tx *sql.Tx) error {
+		if err := addColumnsIfMissing(ctx, tx, "extracted_repos", []columnDef{
+			{"release_seen_at", "DATETIME"},
+		}); err != nil {
+			return err
+		}
+		// When the tag was first recorded is unknown; its publish date is the closest guess.
+		return execAll(ctx, tx, `UPDATE extracted_repos SET release_seen_at = release_published_at WHERE release_tag IS NOT NULL;`)
+	}},
+	{16, "create bookmark_tags", func(ctx context.Context, tx *sql.Tx) error {
+		return execAll(ctx, tx,
+			`CREATE TABLE IF NOT EXISTS bookmark_tags (
+				bookmark_id TEXT NOT NULL,
+				tag_id INTEGER NOT NULL,
+				attached_by TEXT NOT NULL DEFAULT 'human',
+				PRIMARY KEY (bookmark_id, tag_id),
+				FOREIGN KEY (tag_id) REFERENCES tags(id) ON DELETE CASCADE
+			);`,
+			// Which bookmark contributed which tag is unknown, so every bookmark of a repo gets
+			// all of its tags until the bookmark is saved again.
+			`INSERT OR IGNORE INTO bookmark_tags (bookmark_id, tag_id, attached_by)
+			SELECT br.bookmark_id, rt.tag_id, rt.attached_by
+			FROM bookmark_repos br JOIN repo_tags rt ON rt.repo_id = br.repo_id;`,
+		)
+	}},
+	{17, "index repo ids case-insensitively", func(ctx context.Context, tx *sql.Tx) error {
+		// Repo IDs are looked up regardless of case, since forges treat owner and name that way.
+		return execAll(ctx, tx,
+			`CREATE INDEX IF NOT EXISTS idx_extracted_repos_repo_id_nocase ON extracted_repos(repo_id COLLATE NOCASE);`,
+			`CREATE INDEX IF NOT EXISTS idx_repo_aliases_alias_nocase ON repo_aliases(alias_id COLLATE NOCASE);`,
+		)
+	}},
+}
+
+// MigrationStatus describes one known migration and whether it has been applied.
+type MigrationStatus struct {
+	Version   int
+	Name      string
+	AppliedAt *time.Time // Nil if pending.
+}
+
+// LatestSchemaVersion is the version the database reaches after Migrate.
+func LatestSchemaVersion() int {
+	return migrations[len(migrations)-1].version
+}
+
+// Migrate applies every pending migration in order and returns the ones it ran.
+// It stops at the first failure, leaving earlier migrations committed.
+func (r *SQLiteRepository) Migrate(ctx context.Context) ([]MigrationStatus, error) {
+	if err := r.ensureMigrationsTable(ctx); err != nil {
+		return nil, err
+	}
+	current, err := r.SchemaVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var applied []MigrationStatus
+	for _, m := range migrations {
+		if m.version <= current {
+			continue
+		}
+		appliedAt, err := r.applyMigration(ctx, m)
+		if err != nil {
+			return applied, err
+		}
+		applied = append(applied, MigrationStatus{Version: m.version, Name: m.name, AppliedAt: &appliedAt})
+	}
+	return applied, nil
+}
+
+func (r *SQLiteRepository) applyMigration(ctx context.Context, m migration) (time.Time, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("migration %d (%s): failed to begin transaction: %w", m.version, m.name, err)
+	}
+	defer tx.Rollback()
+
+	if err := m.up(ctx, tx); err != nil {
+		return time.Time{}, fmt.Errorf("migration %d (%s) failed: %w", m.version, m.name, err)
+	}
+
+	appliedAt := time.Now().UTC()
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?);`,
+		m.version, m.name, appliedAt.Format(time.RFC3339),
+	); err != nil {
+		return time.Time{}, fmt.Errorf("migration %d (%s): failed to record version: %w", m.version, m.name, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return time.Time{}, fmt.Errorf("migration %d (%s): failed to commit: %w", m.version, m.name, err)
+	}
+	return appliedAt, nil
+}
+
+// SchemaVersion returns the highest applied migration, or 0 for a new or pre-versioning database.
+func (r *SQLiteRepository) SchemaVersion(ctx context.Context) (int, error) {
+	if err := r.ensureMigrationsTable(ctx); err != nil {
+		return 0, err
+	}
+	var version int
+	if err := r.db.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations;`).Scan(&version); err != nil {
+		return 0, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	return version, nil
+}
+
+// MigrationStatuses lists every known migration with its applied time, if any.
+func (r *SQLiteRepository) MigrationStatuses(ctx context.Context) ([]MigrationStatus, error) {
+	if err := r.ensureMigrationsTable(ctx); err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, `SELECT version, applied_at FROM schema_migrations;`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query schema_migrations: %w", err)
+	}
+	defer rows.Close()
+
+	appliedAt := make(map[int]time.Time)
+	for rows.Next() {
+		var version int
+		var at string
+		if err := rows.Scan(&version, &at); err != nil {
+			return nil, fmt.Errorf("failed to scan schema_migrations row: %w", err)
+		}
+		t, err := parseTime(at)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse applied_at for migration %d: %w", version, err)
+		}
+		appliedAt[version] = t
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	statuses := make([]MigrationStatus, len(migrations))
+	for i, m := range migrations {
+		statuses[i] = MigrationStatus{Version: m.version, Name: m.name}
+		if t, ok := appliedAt[m.version]; ok {
+			statuses[i].AppliedAt = &t
+		}
+	}
+	return statuses, nil
+}
+
+func (r *SQLiteRepository) ensureMigrationsTable(ctx context.Context) error {
+	_, err := r.db.ExecContext(ctx, `
+	CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at DATETIME NOT NULL
+	);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to initialize schema (schema_migrations): %w", err)
+	}
+	return nil
+}
+
+func execAll(ctx context.Context, tx *sql.Tx, statements ...string) error {
+	for _, stmt := range statements {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type columnDef struct {
+	name       string
+	definition string
+}
+
+// addColumnsIfMissing adds the columns a table doesn't have yet, so migrations can be
+// replayed over databases that gained them before schema versioning existed.
+func addColumnsIfMissing(ctx context.Context, tx *sql.Tx, table string, columns []columnDef) error {
+	existing, err := tableColumns(ctx, tx, table)
+	if err != nil {
+		return err
+	}
+	for _, col := range columns {
+		if existing[col.name] {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s;", table, col.name, col.definition)); err != nil {
+			return fmt.Errorf("failed to add column %s.%s: %w", table, col.name, err)
+		}
+	}
+	return nil
+}
+
+func tableColumns(ctx context.Context, tx *sql.Tx, table string) (map[string]bool, error) {
+	rows, err := tx.QueryContext(ctx, fmt.Sprintf("PRAGMA table_info(%s);", table))
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect table %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	columns := make(map[string]bool)
+	for rows.Next() {
+		var cid, notNull, pk int
+		var name, colType string
+		var dflt sql.NullString
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &dflt, &pk); err != nil {
+			return nil, fmt.Errorf("failed to scan column info for %s: %w", table, err)
+		}
+		columns[name] = true
+	}
+	return columns, rows.Err()
+}
@@ -0,0 +1,136 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/brianluby/karakeep-extractor/internal/core/domain"
+)
+
+func TestMigrate_FreshDatabase(t *testing.T) {
+	db, dbPath := newTestDB(t)
+	defer os.Remove(dbPath)
+	defer db.Close()
+
+	repo := NewSQLiteRepository(db)
+	ctx := context.Background()
+
+	applied, err := repo.Migrate(ctx)
+	if err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+	if len(applied) != len(migrations) {
+		t.Errorf("Expected %d migrations applied, got %d", len(migrations), len(applied))
+	}
+
+	version, err := repo.SchemaVersion(ctx)
+	if err != nil {
+		t.Fatalf("SchemaVersion failed: %v", err)
+	}
+	if version != LatestSchemaVersion() {
+		t.Errorf("Expected version %d, got %d", LatestSchemaVersion(), version)
+	}
+
+	// Second run is a no-op.
+	applied, err = repo.Migrate(ctx)
+	if err != nil || len(applied) != 0 {
+		t.Errorf("Expected no-op migrate, got %d applied, err %v", len(applied), err)
+	}
+}
+
+func TestMigrate_LegacyDatabase(t *testing.T) {
+	db, dbPath := newTestDB(t)
+	defer os.Remove(dbPath)
+	defer db.Close()
+
+	// A database from before schema versioning: some columns added by the old ALTER list.
+	_, err := db.Exec(`
+		CREATE TABLE extracted_repos (
+			repo_id TEXT PRIMARY KEY,
+			url TEXT NOT NULL,
+			source_id TEXT,
+			title TEXT,
+			found_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			stars INTEGER,
+			forks INTEGER
+		);
+		INSERT INTO extracted_repos (repo_id, url, source_id, stars) VALUES ('owner/repo', 'https://github.com/owner/repo', 'bm-1', 7);
+	`)
+	if err != nil {
+		t.Fatalf("Failed to create legacy schema: %v", err)
+	}
+
+	repo := NewSQLiteRepository(db)
+	ctx := context.Background()
+	if _, err := repo.Migrate(ctx); err != nil {
+		t.Fatalf("Migrate failed on legacy database: %v", err)
+	}
+
+	var stars int
+	var forge, status string
+	if err := db.QueryRow(`SELECT stars, forge, enrichment_status FROM extracted_repos WHERE repo_id = 'owner/repo'`).Scan(&stars, &forge, &status); err != nil {
+		t.Fatalf("Failed to read migrated row: %v", err)
+	}
+	if stars != 7 || forge != "github" || status != "PENDING" {
+		t.Errorf("Unexpected migrated row: stars=%d forge=%s status=%s", stars, forge, status)
+	}
+
+	// The single remembered bookmark is carried over into the link table.
+	var kind string
+	if err := db.QueryRow(`SELECT kind FROM bookmark_repos WHERE bookmark_id = 'bm-1' AND repo_id = 'owner/repo'`).Scan(&kind); err != nil {
+		t.Fatalf("Expected backfilled bookmark link: %v", err)
+	}
+	if kind != string(domain.LinkPrimary) {
+		t.Errorf("Expected backfilled link to be primary, got %s", kind)
+	}
+}
+
+func TestMigrate_FailureIsReportedAndRolledBack(t *testing.T) {
+	db, dbPath := newTestDB(t)
+	defer os.Remove(dbPath)
+	defer db.Close()
+
+	original := migrations
+	defer func() { migrations = original }()
+	migrations = append(append([]migration{}, original...), migration{
+		version: LatestSchemaVersion() + 1,
+		name:    "broken",
+		up: func(ctx context.Context, tx *sql.Tx) error {
+			if _, err := tx.ExecContext(ctx, `CREATE TABLE half_done (id INTEGER);`); err != nil {
+				return err
+			}
+			return errors.New("boom")
+		},
+	})
+
+	repo := NewSQLiteRepository(db)
+	ctx := context.Background()
+
+	_, err := repo.Migrate(ctx)
+	if err == nil || !strings.Contains(err.Error(), "broken") {
+		t.Fatalf("Expected failure naming the migration, got %v", err)
+	}
+
+	version, _ := repo.SchemaVersion(ctx)
+	if version != original[len(original)-1].version {
+		t.Errorf("Expected earlier migrations committed at version %d, got %d", original[len(original)-1].version, version)
+	}
+
+	var name string
+	err = db.QueryRow(`SELECT name FROM sqlite_master WHERE name = 'half_done'`).Scan(&name)
+	if err != sql.ErrNoRows {
+		t.Errorf("Expected failed migration to be rolled back, got %v", err)
+	}
+
+	statuses, err := repo.MigrationStatuses(ctx)
+	if err != nil {
+		t.Fatalf("MigrationStatuses failed: %v", err)
+	}
+	if last := statuses[len(statuses)-1]; last.AppliedAt != nil {
+		t.Errorf("Expected broken migration to be pending, got %+v", last)
+	}
+}
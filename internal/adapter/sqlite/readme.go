@@ -0,0 +1,110 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/brianluby/karakeep-extractor/internal/core/domain"
+)
+
+// GetReposForReadme returns enriched GitHub repos whose README was never fetched, or that were
+// pushed to since it was last checked. With force, every enriched GitHub repo is returned.
+// The least recently checked come first.
+func (r *SQLiteRepository) GetReposForReadme(ctx context.Context, limit int, force bool) ([]*domain.ExtractedRepo, error) {
+	querySQL := `SELECT ` + repoColumns + ` FROM extracted_repos er
+	WHERE er.enrichment_status = 'SUCCESS' AND er.forge = 'github'`
+	if !force {
+		querySQL += ` AND (er.readme_checked_at IS NULL OR julianday(er.last_pushed_at) > julianday(er.readme_checked_at))`
+	}
+	querySQL += ` ORDER BY er.readme_checked_at IS NOT NULL, er.readme_checked_at ASC LIMIT ?;`
+
+	if limit <= 0 {
+		limit = -1
+	}
+	rows, err := r.db.QueryContext(ctx, querySQL, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query repos for readme: %w", err)
+	}
+	defer rows.Close()
+
+	var repos []*domain.ExtractedRepo
+	for rows.Next() {
+		repo, err := scanRepo(rows)
+		if err != nil {
+			return nil, err
+		}
+		repos = append(repos, &repo)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return repos, nil
+}
+
+// UpdateRepoReadme stores a fetched README. An Unchanged update only bumps readme_checked_at.
+func (r *SQLiteRepository) UpdateRepoReadme(ctx context.Context, update domain.ReadmeUpdate) error {
+	checkedAt := time.Now().UTC().Format(time.RFC3339)
+
+	var err error
+	if update.Unchanged {
+		_, err = r.db.ExecContext(ctx, `UPDATE extracted_repos SET readme_checked_at = ? WHERE repo_id = ?;`,
+			checkedAt, update.RepoID)
+	} else {
+		_, err = r.db.ExecContext(ctx, `
+		UPDATE extracted_repos SET readme_sha = ?, readme_text = ?, readme_checked_at = ? WHERE repo_id = ?;`,
+			nullIfEmpty(update.SHA), nullIfEmpty(update.Text), checkedAt, update.RepoID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update readme for %s: %w", update.RepoID, err)
+	}
+	return nil
+}
+
+// HydrateReadmes fills in the Readme text of each repo. README text is kept out of repoColumns
+// so rankings don't load it for every row.
+func (r *SQLiteRepository) HydrateReadmes(ctx context.Context, repos []domain.ExtractedRepo) error {
+	index := make(map[string]int, len(repos))
+	for i, repo := range repos {
+		index[repo.RepoID] = i
+	}
+
+	const batchSize = 500
+	for start := 0; start < len(repos); start += batchSize {
+		end := min(start+batchSize, len(repos))
+		args := make([]interface{}, 0, end-start)
+		for _, repo := range repos[start:end] {
+			args = append(args, repo.RepoID)
+		}
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(args)), ", ")
+
+		rows, err := r.db.QueryContext(ctx, `
+			SELECT repo_id, readme_text FROM extracted_repos
+			WHERE readme_text IS NOT NULL AND repo_id IN (`+placeholders+`);`, args...)
+		if err != nil {
+			return fmt.Errorf("failed to query readmes: %w", err)
+		}
+		for rows.Next() {
+			var repoID, text string
+			if err := rows.Scan(&repoID, &text); err != nil {
+				rows.Close()
+				return fmt.Errorf("failed to scan readme: %w", err)
+			}
+			repos[index[repoID]].Readme = text
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return fmt.Errorf("rows iteration error: %w", err)
+		}
+	}
+	return nil
+}
+
+func nullIfEmpty(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
@@ -0,0 +1,113 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/brianluby/karakeep-extractor/internal/core/domain"
+)
+
+// GetReposForReleases returns enriched GitHub repos whose releases were never checked, or that
+// were pushed to since the last check (new tags are pushes). With force, every enriched GitHub
+// repo is returned. The least recently checked come first.
+func (r *SQLiteRepository) GetReposForReleases(ctx context.Context, limit int, force bool) ([]*domain.ExtractedRepo, error) {
+	querySQL := `SELECT ` + repoColumns + ` FROM extracted_repos er
+	WHERE er.enrichment_status = 'SUCCESS' AND er.forge = 'github'`
+	if !force {
+		querySQL += ` AND (er.release_checked_at IS NULL OR julianday(er.last_pushed_at) > julianday(er.release_checked_at))`
+	}
+	querySQL += ` ORDER BY er.release_checked_at IS NOT NULL, er.release_checked_at ASC LIMIT ?;`
+
+	if limit <= 0 {
+		limit = -1
+	}
+	rows, err := r.db.QueryContext(ctx, querySQL, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query repos for releases: %w", err)
+	}
+	defer rows.Close()
+
+	var repos []*domain.ExtractedRepo
+	for rows.Next() {
+		repo, err := scanRepo(rows)
+		if err != nil {
+			return nil, err
+		}
+		repos = append(repos, &repo)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return repos, nil
+}
+
+// UpdateRepoRelease stores the latest release of a repo, clearing it when there is none.
+// release_seen_at records when the stored tag last changed, so releases published before
+// they were first recorded still count as new.
+func (r *SQLiteRepository) UpdateRepoRelease(ctx context.Context, update domain.ReleaseUpdate) error {
+	var tag, publishedAt interface{}
+	var prerelease, fromTag bool
+	if rel := update.Release; rel != nil {
+		tag = rel.Tag
+		publishedAt = rel.PublishedAt.UTC().Format(time.RFC3339)
+		prerelease, fromTag = rel.Prerelease, rel.FromTag
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	_, err := r.db.ExecContext(ctx, `
+	UPDATE extracted_repos SET
+		release_seen_at = CASE WHEN ?1 IS NULL THEN NULL WHEN release_tag IS ?1 THEN release_seen_at ELSE ?5 END,
+		release_tag = ?1, release_published_at = ?2, release_prerelease = ?3, release_from_tag = ?4,
+		release_checked_at = ?5
+	WHERE repo_id = ?6;`,
+		tag, publishedAt, prerelease, fromTag, now, update.RepoID)
+	if err != nil {
+		return fmt.Errorf("failed to update release for %s: %w", update.RepoID, err)
+	}
+	return nil
+}
+
+// GetNewReleases returns bookmarked repos whose latest release was recorded after query.SeenAfter
+// and published after query.PublishedAfter, newest first. Repos whose bookmark was deleted are left out.
+func (r *SQLiteRepository) GetNewReleases(ctx context.Context, query domain.ReleaseQuery) ([]domain.ExtractedRepo, error) {
+	querySQL := `SELECT ` + repoColumns + ` FROM extracted_repos er
+	WHERE er.release_tag IS NOT NULL AND er.orphaned = 0`
+	var args []interface{}
+	if !query.SeenAfter.IsZero() {
+		querySQL += ` AND julianday(er.release_seen_at) > julianday(?)`
+		args = append(args, query.SeenAfter.UTC().Format(time.RFC3339))
+	}
+	if !query.PublishedAfter.IsZero() {
+		querySQL += ` AND julianday(er.release_published_at) > julianday(?)`
+		args = append(args, query.PublishedAfter.UTC().Format(time.RFC3339))
+	}
+	querySQL += ` ORDER BY er.release_published_at DESC, er.repo_id ASC LIMIT ?;`
+
+	limit := query.Limit
+	if limit <= 0 {
+		limit = -1
+	}
+	args = append(args, limit)
+	rows, err := r.db.QueryContext(ctx, querySQL, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query new releases: %w", err)
+	}
+	defer rows.Close()
+
+	var repos []domain.ExtractedRepo
+	for rows.Next() {
+		repo, err := scanRepo(rows)
+		if err != nil {
+			return nil, err
+		}
+		repos = append(repos, repo)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	if err := r.hydrateTags(ctx, repos); err != nil {
+		return nil, err
+	}
+	return repos, nil
+}
@@ -3,7 +3,9 @@ package sqlite
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	_ "github.com/mattn/go-sqlite3" // SQLite driver
@@ -18,68 +20,19 @@ func NewSQLiteRepository(db *sql.DB) *SQLiteRepository {
 	return &SQLiteRepository{db: db}
 }
 
-// InitSchema initializes the database schema for ExtractedRepo and Tags.
+// InitSchema brings the database schema up to date by applying any pending migrations.
 func (r *SQLiteRepository) InitSchema(ctx context.Context) error {
-	const createTableSQL = `
-	CREATE TABLE IF NOT EXISTS extracted_repos (
-		repo_id TEXT PRIMARY KEY,
-		url TEXT NOT NULL,
-		source_id TEXT,
-		title TEXT,
-		found_at DATETIME DEFAULT CURRENT_TIMESTAMP
-	);
-	`
-	_, err := r.db.ExecContext(ctx, createTableSQL)
-	if err != nil {
-		return fmt.Errorf("failed to initialize schema (extracted_repos): %w", err)
+	if _, err := r.Migrate(ctx); err != nil {
+		return fmt.Errorf("failed to initialize schema: %w", err)
 	}
-
-	const createTagsTableSQL = `
-	CREATE TABLE IF NOT EXISTS tags (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		name TEXT UNIQUE NOT NULL
-	);
-	`
-	_, err = r.db.ExecContext(ctx, createTagsTableSQL)
-	if err != nil {
-		return fmt.Errorf("failed to initialize schema (tags): %w", err)
-	}
-
-	const createRepoTagsTableSQL = `
-	CREATE TABLE IF NOT EXISTS repo_tags (
-		repo_id TEXT NOT NULL,
-		tag_id INTEGER NOT NULL,
-		PRIMARY KEY (repo_id, tag_id),
-		FOREIGN KEY (repo_id) REFERENCES extracted_repos(repo_id) ON DELETE CASCADE,
-		FOREIGN KEY (tag_id) REFERENCES tags(id) ON DELETE CASCADE
-	);
-	`
-	_, err = r.db.ExecContext(ctx, createRepoTagsTableSQL)
-	if err != nil {
-		return fmt.Errorf("failed to initialize schema (repo_tags): %w", err)
+	if err := r.ensureSearchIndex(ctx); err != nil {
+		return fmt.Errorf("failed to initialize search index: %w", err)
 	}
-
-	// Migrations: Add new columns if they don't exist
-	migrationSQLs := []string{
-		`ALTER TABLE extracted_repos ADD COLUMN stars INTEGER;`,
-		`ALTER TABLE extracted_repos ADD COLUMN forks INTEGER;`,
-		`ALTER TABLE extracted_repos ADD COLUMN last_pushed_at DATETIME;`,
-		`ALTER TABLE extracted_repos ADD COLUMN description TEXT;`,
-		`ALTER TABLE extracted_repos ADD COLUMN language TEXT;`,
-		`ALTER TABLE extracted_repos ADD COLUMN enrichment_status TEXT DEFAULT 'PENDING';`,
-	}
-
-	for _, sql := range migrationSQLs {
-		if _, err := r.db.ExecContext(ctx, sql); err != nil {
-			// Optional: check if err.Error() contains "duplicate column"
-			// fmt.Printf("Migration warning (safe to ignore if column exists): %v\n", err)
-		}
-	}
-
 	return nil
 }
 
-// Save saves an ExtractedRepo to the database. If a repo with the same RepoID already exists, it is ignored.
+// Save saves an ExtractedRepo to the database. If a repo with the same RepoID already exists, it is kept
+// as is, but the bookmark in SourceID is still linked to it and its tags are merged in.
 func (r *SQLiteRepository) Save(ctx context.Context, repo domain.ExtractedRepo) error {
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
@@ -87,133 +40,292 @@ func (r *SQLiteRepository) Save(ctx context.Context, repo domain.ExtractedRepo)
 	}
 	defer tx.Rollback()
 
+	// A repo bookmarked under an old name belongs to the repo it was renamed to.
+	if repo.RepoID, err = resolveAlias(ctx, tx, repo.RepoID); err != nil {
+		return err
+	}
+
 	const insertRepoSQL = `
-	INSERT OR IGNORE INTO extracted_repos (repo_id, url, source_id, title, found_at)
-	VALUES (?, ?, ?, ?, ?);
+	INSERT OR IGNORE INTO extracted_repos (repo_id, forge, url, source_id, title, found_at, bookmark_archived)
+	VALUES (?, ?, ?, ?, ?, ?, ?);
 	`
+	forge := repo.Forge
+	if forge == "" {
+		forge = domain.ForgeGitHub
+	}
 	
 	_, err = tx.ExecContext(ctx, insertRepoSQL,
 		repo.RepoID,
+		forge,
 		repo.URL,
 		repo.SourceID,
 		repo.Title,
 		repo.FoundAt.Format(time.RFC3339),
+		repo.BookmarkArchived,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to save repository: %w", err)
 	}
 
-	// Handle Tags
-	if len(repo.Tags) > 0 {
-		// Note: If repo already exists, we are NOT updating tags here based on "INSERT OR IGNORE" logic above.
-		// The requirement says "When extracted again, Then the local database updates to reflect the new tag set."
-		// So we probably should update tags even if repo exists.
-		// Let's clear existing tags for this repo and re-insert to ensure sync.
-		// But wait, if we are using "INSERT OR IGNORE" for repo, we assume it might exist.
-		// If it exists, we should ensure it's there.
-		// Let's assume 'Save' is called for every extraction.
-		
-		// Ensure Repo exists (in case it was ignored above but we want to attach tags now)
-		// Actually, if it was ignored, the row exists.
-		
-		// Delete existing links for this repo (full sync strategy)
-		const deleteTagsSQL = `DELETE FROM repo_tags WHERE repo_id = ?;
+	if repo.SourceID != "" {
+		kind := repo.LinkKind
+		if kind == "" {
+			kind = domain.LinkPrimary
+		}
+		// A bookmark that links a repo both ways counts as primary.
+		const linkSQL = `
+		INSERT INTO bookmark_repos (bookmark_id, repo_id, kind) VALUES (?, ?, ?)
+		ON CONFLICT(bookmark_id, repo_id) DO UPDATE SET kind = 'primary' WHERE excluded.kind = 'primary';
 		`
-		_, err = tx.ExecContext(ctx, deleteTagsSQL, repo.RepoID)
-		if err != nil {
-			return fmt.Errorf("failed to clear old tags: %w", err)
+		if _, err := tx.ExecContext(ctx, linkSQL, repo.SourceID, repo.RepoID, kind); err != nil {
+			return fmt.Errorf("failed to link bookmark %s: %w", repo.SourceID, err)
 		}
+	}
 
-		if err := r.saveTags(ctx, tx, repo.RepoID, repo.Tags); err != nil {
-			return fmt.Errorf("failed to save tags: %w", err)
-		}
+	// A repo has the tags of every bookmark it appears in. Saving a bookmark replaces that
+	// bookmark's tags; without a bookmark, tags can only be merged.
+	if repo.SourceID != "" {
+		err = r.saveBookmarkTags(ctx, tx, repo.SourceID, repo.Tags)
+	} else {
+		err = r.saveTags(ctx, tx, repo.RepoID, repo.Tags)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save tags: %w", err)
 	}
 
 	return tx.Commit()
 }
 
-// saveTags helper to insert tags and links within a transaction.
-func (r *SQLiteRepository) saveTags(ctx context.Context, tx *sql.Tx, repoID string, tags []string) error {
+// saveBookmarkTags replaces a bookmark's tags, then rebuilds the tags of the repos it links to
+// from all of their bookmarks. A tag attached by a human on any bookmark counts as human.
+func (r *SQLiteRepository) saveBookmarkTags(ctx context.Context, tx *sql.Tx, bookmarkID string, tags []domain.Tag) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM bookmark_tags WHERE bookmark_id = ?;`, bookmarkID); err != nil {
+		return fmt.Errorf("failed to clear tags of bookmark %s: %w", bookmarkID, err)
+	}
 	for _, tag := range tags {
-		// 1. Insert Tag (Ignore if exists)
-		const insertTagSQL = `INSERT OR IGNORE INTO tags (name) VALUES (?);
-		`
-		_, err := tx.ExecContext(ctx, insertTagSQL, tag)
+		tagID, err := r.tagID(ctx, tx, tag.Name)
 		if err != nil {
-			return fmt.Errorf("failed to insert tag %s: %w", tag, err)
+			return err
 		}
-
-		// 2. Get Tag ID
-		const getTagIDSQL = `SELECT id FROM tags WHERE name = ?;
+		const linkTagSQL = `
+		INSERT INTO bookmark_tags (bookmark_id, tag_id, attached_by) VALUES (?, ?, ?)
+		ON CONFLICT(bookmark_id, tag_id) DO UPDATE SET attached_by = excluded.attached_by;
 		`
-		var tagID int64
-		err = tx.QueryRowContext(ctx, getTagIDSQL, tag).Scan(&tagID)
+		if _, err := tx.ExecContext(ctx, linkTagSQL, bookmarkID, tagID, tagSource(tag)); err != nil {
+			return fmt.Errorf("failed to link tag %s to bookmark %s: %w", tag.Name, bookmarkID, err)
+		}
+	}
+
+	// 'human' sorts after 'ai', so MAX prefers human attribution.
+	const bookmarkTagsSQL = `SELECT bt.tag_id FROM bookmark_tags bt
+		JOIN bookmark_repos br ON br.bookmark_id = bt.bookmark_id WHERE br.repo_id = repo_tags.repo_id`
+	statements := []string{
+		`DELETE FROM repo_tags
+		WHERE repo_id IN (SELECT repo_id FROM bookmark_repos WHERE bookmark_id = ?1)
+			AND tag_id NOT IN (` + bookmarkTagsSQL + `);`,
+		`INSERT INTO repo_tags (repo_id, tag_id, attached_by)
+		SELECT br.repo_id, bt.tag_id, MAX(bt.attached_by)
+		FROM bookmark_repos br
+		JOIN bookmark_repos other ON other.repo_id = br.repo_id
+		JOIN bookmark_tags bt ON bt.bookmark_id = other.bookmark_id
+		WHERE br.bookmark_id = ?1
+		GROUP BY br.repo_id, bt.tag_id
+		ON CONFLICT(repo_id, tag_id) DO UPDATE SET attached_by = excluded.attached_by;`,
+	}
+	for _, stmt := range statements {
+		if _, err := tx.ExecContext(ctx, stmt, bookmarkID); err != nil {
+			return fmt.Errorf("failed to update tags of repos in bookmark %s: %w", bookmarkID, err)
+		}
+	}
+	return nil
+}
+
+// tagID returns the ID of the named tag, creating it if needed.
+func (r *SQLiteRepository) tagID(ctx context.Context, tx *sql.Tx, name string) (int64, error) {
+	if _, err := tx.ExecContext(ctx, `INSERT OR IGNORE INTO tags (name) VALUES (?);`, name); err != nil {
+		return 0, fmt.Errorf("failed to insert tag %s: %w", name, err)
+	}
+	var id int64
+	if err := tx.QueryRowContext(ctx, `SELECT id FROM tags WHERE name = ?;`, name).Scan(&id); err != nil {
+		return 0, fmt.Errorf("failed to get id for tag %s: %w", name, err)
+	}
+	return id, nil
+}
+
+func tagSource(tag domain.Tag) domain.TagSource {
+	if tag.AttachedBy == "" {
+		return domain.TagSourceHuman
+	}
+	return tag.AttachedBy
+}
+
+// saveTags merges tags into a repo's tags; the latest attribution wins.
+func (r *SQLiteRepository) saveTags(ctx context.Context, tx *sql.Tx, repoID string, tags []domain.Tag) error {
+	for _, tag := range tags {
+		tagID, err := r.tagID(ctx, tx, tag.Name)
 		if err != nil {
-			return fmt.Errorf("failed to get id for tag %s: %w", tag, err)
+			return err
 		}
 
-		// 3. Link Repo to Tag
-		const linkTagSQL = `INSERT INTO repo_tags (repo_id, tag_id) VALUES (?, ?);
+		const linkTagSQL = `
+		INSERT INTO repo_tags (repo_id, tag_id, attached_by) VALUES (?, ?, ?)
+		ON CONFLICT(repo_id, tag_id) DO UPDATE SET attached_by = excluded.attached_by;
 		`
-		_, err = tx.ExecContext(ctx, linkTagSQL, repoID, tagID)
+		_, err = tx.ExecContext(ctx, linkTagSQL, repoID, tagID, tagSource(tag))
 		if err != nil {
-			return fmt.Errorf("failed to link tag %s to repo %s: %w", tag, repoID, err)
+			return fmt.Errorf("failed to link tag %s to repo %s: %w", tag.Name, repoID, err)
 		}
 	}
 	return nil
 }
 
-// Exists checks if an ExtractedRepo with the given RepoID already exists in the database.
+// Exists checks if an ExtractedRepo with the given RepoID already exists in the database,
+// either under that ID (in any case) or under the ID it was renamed to.
 func (r *SQLiteRepository) Exists(ctx context.Context, repoID string) (bool, error) {
-	const querySQL = `SELECT COUNT(*) FROM extracted_repos WHERE repo_id = ?;
-	`
-	var count int
-	err := r.db.QueryRowContext(ctx, querySQL, repoID).Scan(&count)
+	canonical, err := resolveAlias(ctx, r.db, repoID)
 	if err != nil {
 		return false, fmt.Errorf("failed to check if repository exists: %w", err)
 	}
-	return count > 0, nil
+	stored, err := storedRepoID(ctx, r.db, canonical)
+	if err != nil {
+		return false, fmt.Errorf("failed to check if repository exists: %w", err)
+	}
+	return stored != "", nil
+}
+
+// ExistingRepos reports which of the given repo IDs already exist, like Exists, with one query
+// per batch of IDs instead of one per ID.
+func (r *SQLiteRepository) ExistingRepos(ctx context.Context, repoIDs []string) (map[string]bool, error) {
+	existing := make(map[string]bool)
+	// IDs match regardless of case; fold them to find every given ID a stored one answers for.
+	byFolded := make(map[string][]string, len(repoIDs))
+	for _, id := range repoIDs {
+		byFolded[strings.ToLower(id)] = append(byFolded[strings.ToLower(id)], id)
+	}
+
+	// Batched to stay well under SQLite's bound-parameter limit.
+	const batchSize = 500
+	for start := 0; start < len(repoIDs); start += batchSize {
+		end := min(start+batchSize, len(repoIDs))
+		args := make([]interface{}, 0, end-start)
+		for _, id := range repoIDs[start:end] {
+			args = append(args, id)
+		}
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(args)), ", ")
+
+		rows, err := r.db.QueryContext(ctx, `
+			SELECT repo_id FROM extracted_repos WHERE repo_id COLLATE NOCASE IN (`+placeholders+`)
+			UNION
+			SELECT a.alias_id FROM repo_aliases a
+			JOIN extracted_repos e ON e.repo_id = a.repo_id
+			WHERE a.alias_id COLLATE NOCASE IN (`+placeholders+`);`, append(args, args...)...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check which repositories exist: %w", err)
+		}
+		for rows.Next() {
+			var repoID string
+			if err := rows.Scan(&repoID); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("failed to scan repo id: %w", err)
+			}
+			for _, id := range byFolded[strings.ToLower(repoID)] {
+				existing[id] = true
+			}
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, fmt.Errorf("rows iteration error: %w", err)
+		}
+	}
+	return existing, nil
 }
 
 // UpdateRepoEnrichment updates the stats and status of a repository.
+// Every update bumps last_checked_at; a NotModified update leaves the stored stats untouched.
 func (r *SQLiteRepository) UpdateRepoEnrichment(ctx context.Context, update domain.RepoEnrichmentUpdate) error {
 	var updateSQL string
 	var args []interface{}
+	checkedAt := time.Now().UTC().Format(time.RFC3339)
 
-	if update.Stats != nil {
+	switch {
+	case update.NotModified:
+		updateSQL = `
+		UPDATE extracted_repos
+		SET enrichment_status = ?, last_checked_at = ?
+		WHERE repo_id = ?;
+		`
+		args = []interface{}{
+			update.EnrichmentStatus,
+			checkedAt,
+			update.RepoID,
+		}
+	case update.Stats != nil:
 		updateSQL = `
 		UPDATE extracted_repos
-		SET stars = ?, forks = ?, last_pushed_at = ?, description = ?, language = ?, enrichment_status = ?
+		SET stars = ?, forks = ?, last_pushed_at = ?, description = ?, language = ?, archived = ?, enrichment_status = ?,
+			topics = ?, license = ?, is_fork = ?, parent = ?, open_issues = ?, homepage = ?, created_at = ?, default_branch = ?, size_kb = ?,
+			etag = ?, last_modified = ?, last_checked_at = ?
 		WHERE repo_id = ?;
 		`
+		var topics, createdAt interface{}
+		if len(update.Stats.Topics) > 0 {
+			encoded, err := json.Marshal(update.Stats.Topics)
+			if err != nil {
+				return fmt.Errorf("failed to encode topics: %w", err)
+			}
+			topics = string(encoded)
+		}
+		if !update.Stats.CreatedAt.IsZero() {
+			createdAt = update.Stats.CreatedAt.Format(time.RFC3339)
+		}
 		args = []interface{}{
 			update.Stats.Stars,
 			update.Stats.Forks,
 			update.Stats.LastPushed.Format(time.RFC3339),
 			update.Stats.Description,
 			update.Stats.Language,
+			update.Stats.Archived,
 			update.EnrichmentStatus,
+			topics,
+			update.Stats.License,
+			update.Stats.Fork,
+			update.Stats.Parent,
+			update.Stats.OpenIssues,
+			update.Stats.Homepage,
+			createdAt,
+			update.Stats.DefaultBranch,
+			update.Stats.SizeKB,
+			update.Stats.ETag,
+			update.Stats.LastModified,
+			checkedAt,
 			update.RepoID,
 		}
-	} else {
+	default:
 		updateSQL = `
 		UPDATE extracted_repos
-		SET enrichment_status = ?
+		SET enrichment_status = ?, last_checked_at = ?
 		WHERE repo_id = ?;
 		`
 		args = []interface{}{
 			update.EnrichmentStatus,
+			checkedAt,
 			update.RepoID,
 		}
 	}
 
-	result, err := r.db.ExecContext(ctx, updateSQL, args...)
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("failed to update repository enrichment: %w", err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
+	defer tx.Rollback()
 
+	result, err := tx.ExecContext(ctx, updateSQL, args...)
+	if err != nil {
+		return fmt.Errorf("failed to update repository enrichment: %w", err)
+	}
 
-rowsAffected, err := result.RowsAffected()
+	rowsAffected, err := result.RowsAffected()
 	if err != nil {
 		return fmt.Errorf("failed to get rows affected: %w", err)
 	}
@@ -221,82 +333,177 @@ rowsAffected, err := result.RowsAffected()
 		return fmt.Errorf("repository not found: %s", update.RepoID)
 	}
 
+	// Every fresh fetch is also kept as a history snapshot for trend ranking.
+	if update.Stats != nil && !update.NotModified {
+		_, err = tx.ExecContext(ctx, `
+		INSERT INTO repo_stats_history (repo_id, recorded_at, stars, forks, pushed_at)
+		VALUES (?, ?, ?, ?, ?);
+		`, update.RepoID, checkedAt, update.Stats.Stars, update.Stats.Forks, update.Stats.LastPushed.Format(time.RFC3339))
+		if err != nil {
+			return fmt.Errorf("failed to record stats history: %w", err)
+		}
+	}
+
+	// A change in case only is not a move: the repo is found under either spelling.
+	if update.CanonicalID != "" && !strings.EqualFold(update.CanonicalID, update.RepoID) {
+		if err := r.moveRepo(ctx, tx, update.RepoID, update.CanonicalID, update.CanonicalURL); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit enrichment update: %w", err)
+	}
 	return nil
 }
 
-// GetReposForEnrichment returns up to 'limit' repos that need enrichment.
-func (r *SQLiteRepository) GetReposForEnrichment(ctx context.Context, limit int, force bool) ([]*domain.ExtractedRepo, error) {
-	var querySQL string
-	if force {
-		querySQL = `SELECT repo_id, url, source_id, title, found_at, stars, forks, last_pushed_at, description, language, enrichment_status FROM extracted_repos LIMIT ?;
-	`
-	} else {
-		querySQL = `SELECT repo_id, url, source_id, title, found_at, stars, forks, last_pushed_at, description, language, enrichment_status FROM extracted_repos WHERE enrichment_status != 'SUCCESS' LIMIT ?;
-	`
-	}
+// repoColumns is the column list understood by scanRepo.
+const repoColumns = `er.repo_id, er.forge, er.url, er.source_id, er.title, er.found_at, er.stars, er.forks, er.last_pushed_at, er.description, er.language, er.enrichment_status, er.orphaned, er.bookmark_archived, er.etag, er.last_modified, er.last_checked_at, er.archived,
+	er.topics, er.license, er.is_fork, er.parent, er.open_issues, er.homepage, er.created_at, er.default_branch, er.size_kb, er.readme_sha,
+	er.release_tag, er.release_published_at, er.release_prerelease, er.release_from_tag,
+	(SELECT COUNT(*) FROM bookmark_repos br WHERE br.repo_id = er.repo_id) AS bookmark_count`
 
+// rowScanner is satisfied by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
 
-rows, err := r.db.QueryContext(ctx, querySQL, limit)
+// parseTime parses timestamps stored either as RFC3339 or SQLite's CURRENT_TIMESTAMP format.
+func parseTime(ts string) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339, ts)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query repos for enrichment: %w", err)
+		t, err = time.Parse("2006-01-02 15:04:05", ts)
 	}
-	defer rows.Close()
+	return t, err
+}
 
-	var repos []*domain.ExtractedRepo
-	for rows.Next() {
-		var r domain.ExtractedRepo
-		var foundAt string
-		var lastPushedAt sql.NullString // Use NullString for scanning
-		var stars, forks sql.NullInt64
-		var description, language, enrichmentStatus sql.NullString
-
-		err := rows.Scan(
-			&r.RepoID, &r.URL, &r.SourceID, &r.Title, &foundAt,
-			&stars, &forks, &lastPushedAt, &description, &language, &enrichmentStatus,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan repo row: %w", err)
-		}
+// scanRepo maps a row selected with repoColumns onto an ExtractedRepo.
+// Any extra destinations are scanned from columns selected after repoColumns.
+func scanRepo(row rowScanner, extra ...interface{}) (domain.ExtractedRepo, error) {
+	var r domain.ExtractedRepo
+	var forge string
+	var sourceID, title sql.NullString
+	var foundAt string
+	var lastPushedAt sql.NullString // Use NullString for scanning
+	var stars, forks sql.NullInt64
+	var description, language, enrichmentStatus sql.NullString
+	var etag, lastModified, lastCheckedAt sql.NullString
+	var topics, license, parent, homepage, createdAt, defaultBranch sql.NullString
+	var openIssues, sizeKB sql.NullInt64
+	var readmeSHA sql.NullString
+	var releaseTag, releasePublishedAt sql.NullString
+	var releasePrerelease, releaseFromTag bool
+
+	dest := []interface{}{
+		&r.RepoID, &forge, &r.URL, &sourceID, &title, &foundAt,
+		&stars, &forks, &lastPushedAt, &description, &language, &enrichmentStatus,
+		&r.Orphaned, &r.BookmarkArchived, &etag, &lastModified, &lastCheckedAt, &r.RepoArchived,
+		&topics, &license, &r.IsFork, &parent, &openIssues, &homepage, &createdAt, &defaultBranch, &sizeKB, &readmeSHA,
+		&releaseTag, &releasePublishedAt, &releasePrerelease, &releaseFromTag,
+		&r.BookmarkCount,
+	}
+	err := row.Scan(append(dest, extra...)...)
+	if err != nil {
+		return r, fmt.Errorf("failed to scan repo row: %w", err)
+	}
 
-		// Parse FoundAt
-		t, err := time.Parse(time.RFC3339, foundAt)
-		if err != nil {
-			// Try parsing as SQLite default CURRENT_TIMESTAMP format "2006-01-02 15:04:05" if RFC3339 fails
-			t, err = time.Parse("2006-01-02 15:04:05", foundAt)
-			if err != nil {
-				return nil, fmt.Errorf("failed to parse found_at time: %w", err)
-			}
+	r.Forge = domain.Forge(forge)
+	r.SourceID = sourceID.String
+	r.Title = title.String
+	r.ETag = etag.String
+	r.LastModified = lastModified.String
+	r.License = license.String
+	r.Parent = parent.String
+	r.Homepage = homepage.String
+	r.DefaultBranch = defaultBranch.String
+	r.ReadmeSHA = readmeSHA.String
+	if topics.Valid && topics.String != "" {
+		if err := json.Unmarshal([]byte(topics.String), &r.Topics); err != nil {
+			return r, fmt.Errorf("failed to decode topics: %w", err)
 		}
-		r.FoundAt = t
+	}
 
-		// Map nullable fields
-		if stars.Valid {
-			s := int(stars.Int64)
-			r.Stars = &s
-		}
-		if forks.Valid {
-			f := int(forks.Int64)
-			r.Forks = &f
-		}
-		if lastPushedAt.Valid {
-			t, err := time.Parse(time.RFC3339, lastPushedAt.String)
-			if err == nil {
-				r.LastPushedAt = &t
-			}
+	t, err := parseTime(foundAt)
+	if err != nil {
+		return r, fmt.Errorf("failed to parse found_at time: %w", err)
+	}
+	r.FoundAt = t
+
+	// Map nullable fields
+	if stars.Valid {
+		s := int(stars.Int64)
+		r.Stars = &s
+	}
+	if forks.Valid {
+		f := int(forks.Int64)
+		r.Forks = &f
+	}
+	if lastPushedAt.Valid {
+		if t, err := parseTime(lastPushedAt.String); err == nil {
+			r.LastPushedAt = &t
 		}
-		if description.Valid {
-			r.Description = &description.String
+	}
+	if description.Valid {
+		r.Description = &description.String
+	}
+	if language.Valid {
+		r.Language = &language.String
+	}
+	if openIssues.Valid {
+		n := int(openIssues.Int64)
+		r.OpenIssues = &n
+	}
+	if sizeKB.Valid {
+		n := int(sizeKB.Int64)
+		r.SizeKB = &n
+	}
+	if createdAt.Valid {
+		if t, err := parseTime(createdAt.String); err == nil {
+			r.CreatedAt = &t
 		}
-		if language.Valid {
-			r.Language = &language.String
+	}
+	if lastCheckedAt.Valid {
+		if t, err := parseTime(lastCheckedAt.String); err == nil {
+			r.LastCheckedAt = &t
 		}
-		if enrichmentStatus.Valid {
-			r.EnrichmentStatus = domain.EnrichmentStatus(enrichmentStatus.String)
-		} else {
-			r.EnrichmentStatus = domain.StatusPending // Default if null, though migration sets default
+	}
+	if releaseTag.Valid {
+		r.LatestRelease = &domain.Release{Tag: releaseTag.String, Prerelease: releasePrerelease, FromTag: releaseFromTag}
+		if t, err := parseTime(releasePublishedAt.String); err == nil {
+			r.LatestRelease.PublishedAt = t
 		}
+	}
+	if enrichmentStatus.Valid {
+		r.EnrichmentStatus = domain.EnrichmentStatus(enrichmentStatus.String)
+	} else {
+		r.EnrichmentStatus = domain.StatusPending // Default if null, though migration sets default
+	}
 
-		repos = append(repos, &r)
+	return r, nil
+}
+
+// GetReposForEnrichment returns up to 'limit' repos that need enrichment.
+func (r *SQLiteRepository) GetReposForEnrichment(ctx context.Context, limit int, force bool) ([]*domain.ExtractedRepo, error) {
+	querySQL := `SELECT ` + repoColumns + ` FROM extracted_repos er`
+	if !force {
+		querySQL += ` WHERE er.enrichment_status != 'SUCCESS'`
+	}
+	// Least recently checked first, so limited forced refreshes rotate through the whole table.
+	querySQL += ` ORDER BY er.last_checked_at IS NOT NULL, er.last_checked_at LIMIT ?;`
+
+	rows, err := r.db.QueryContext(ctx, querySQL, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query repos for enrichment: %w", err)
+	}
+	defer rows.Close()
+
+	var repos []*domain.ExtractedRepo
+	for rows.Next() {
+		repo, err := scanRepo(rows)
+		if err != nil {
+			return nil, err
+		}
+		repos = append(repos, &repo)
 	}
 
 	if err = rows.Err(); err != nil {
@@ -307,45 +514,54 @@ rows, err := r.db.QueryContext(ctx, querySQL, limit)
 }
 
 // GetRankedRepos returns a list of repos sorted by the criteria and optionally filtered by a tag.
-func (r *SQLiteRepository) GetRankedRepos(ctx context.Context, limit int, sortBy domain.RankSortOption, filterTag string) ([]domain.ExtractedRepo, error) {
-	baseQuery := `
-		SELECT er.repo_id, er.url, er.source_id, er.title, er.found_at, er.stars, er.forks, er.last_pushed_at, er.description, er.language, er.enrichment_status 
-		FROM extracted_repos er
-		WHERE er.enrichment_status = 'SUCCESS'`
-
+// Repos whose bookmarks were deleted (orphaned) or archived are hidden unless the query asks for them.
+func (r *SQLiteRepository) GetRankedRepos(ctx context.Context, query domain.RankQuery) ([]domain.ExtractedRepo, error) {
 	var args []interface{}
-	if filterTag != "" {
-		// Old behavior: search title/desc
-		// New behavior (from spec): filter by TAGs.
-		// "The karakeep rank command MUST support filtering by these locally stored tags via the --tag flag"
-		// However, users might expect existing title/desc search to work if --tag is ambiguous.
-		// But "filterTag" argument implies tag. Let's implement tag filtering via JOIN.
-		
-		baseQuery += ` AND EXISTS (
-			SELECT 1 FROM repo_tags rt
-			JOIN tags t ON rt.tag_id = t.id
-			WHERE rt.repo_id = er.repo_id AND t.name = ?
-		)`
-		args = append(args, filterTag)
+
+	// For stars-delta the baseline is the newest snapshot at or before the window start,
+	// falling back to the oldest snapshot for repos first seen inside the window.
+	deltaColumn := ", NULL"
+	if query.SortBy == domain.SortByStarsDelta {
+		deltaColumn = `, er.stars - COALESCE(
+			(SELECT h.stars FROM repo_stats_history h WHERE h.repo_id = er.repo_id AND h.recorded_at <= ? ORDER BY h.recorded_at DESC LIMIT 1),
+			(SELECT h.stars FROM repo_stats_history h WHERE h.repo_id = er.repo_id ORDER BY h.recorded_at ASC LIMIT 1),
+			er.stars) AS stars_delta`
+		args = append(args, time.Now().Add(-query.Since).UTC().Format(time.RFC3339))
+	}
+
+	from, fromArgs, err := rankedFrom(query)
+	if err != nil {
+		return nil, err
 	}
+	baseQuery := `
+		SELECT ` + repoColumns + deltaColumn + from
+	args = append(args, fromArgs...)
 
+	// repo_id breaks ties so that pages don't shuffle between calls.
 	var orderClause string
-	switch sortBy {
+	switch query.SortBy {
 	case domain.SortByStars:
-		orderClause = "ORDER BY er.stars DESC"
+		orderClause = "ORDER BY er.stars DESC, er.repo_id"
 	case domain.SortByForks:
-		orderClause = "ORDER BY er.forks DESC"
+		orderClause = "ORDER BY er.forks DESC, er.repo_id"
 	case domain.SortByUpdated:
-		orderClause = "ORDER BY er.last_pushed_at DESC"
+		orderClause = "ORDER BY er.last_pushed_at DESC, er.repo_id"
+	case domain.SortByStarsDelta:
+		orderClause = "ORDER BY stars_delta DESC, er.stars DESC, er.repo_id"
+	case domain.SortByBookmarks:
+		orderClause = "ORDER BY bookmark_count DESC, er.stars DESC, er.repo_id"
 	default:
-		orderClause = "ORDER BY er.stars DESC"
+		orderClause = "ORDER BY er.stars DESC, er.repo_id"
 	}
 
-	finalQuery := fmt.Sprintf("%s %s LIMIT ?", baseQuery, orderClause)
-	args = append(args, limit)
-
+	limit := query.Limit
+	if limit <= 0 {
+		limit = -1 // SQLite: no limit
+	}
+	finalQuery := fmt.Sprintf("%s %s LIMIT ? OFFSET ?", baseQuery, orderClause)
+	args = append(args, limit, max(query.Offset, 0))
 
-rows, err := r.db.QueryContext(ctx, finalQuery, args...)
+	rows, err := r.db.QueryContext(ctx, finalQuery, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query ranked repos: %w", err)
 	}
@@ -353,59 +569,284 @@ rows, err := r.db.QueryContext(ctx, finalQuery, args...)
 
 	var repos []domain.ExtractedRepo
 	for rows.Next() {
-		var r domain.ExtractedRepo
-		var foundAt string
-		var lastPushedAt sql.NullString
-		var stars, forks sql.NullInt64
-		var description, language, enrichmentStatus sql.NullString
-
-		err := rows.Scan(
-			&r.RepoID, &r.URL, &r.SourceID, &r.Title, &foundAt,
-			&stars, &forks, &lastPushedAt, &description, &language, &enrichmentStatus,
-		)
+		var starsDelta sql.NullInt64
+		repo, err := scanRepo(rows, &starsDelta)
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan repo row: %w", err)
+			return nil, err
 		}
-
-		// Helper to parse time or fallback
-		parseTime := func(ts string) time.Time {
-			t, err := time.Parse(time.RFC3339, ts)
-			if err != nil {
-				t, _ = time.Parse("2006-01-02 15:04:05", ts)
-			}
-			return t
+		if starsDelta.Valid {
+			d := int(starsDelta.Int64)
+			repo.StarsDelta = &d
 		}
+		repos = append(repos, repo)
+	}
 
-		r.FoundAt = parseTime(foundAt)
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	rows.Close()
+
+	if err := r.hydrateTags(ctx, repos); err != nil {
+		return nil, err
+	}
+	return repos, nil
+}
+
+// CountRankedRepos returns how many repos GetRankedRepos would return without Limit and Offset.
+func (r *SQLiteRepository) CountRankedRepos(ctx context.Context, query domain.RankQuery) (int, error) {
+	from, args, err := rankedFrom(query)
+	if err != nil {
+		return 0, err
+	}
+	var count int
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*)"+from, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count ranked repos: %w", err)
+	}
+	return count, nil
+}
 
-		if stars.Valid {
-			s := int(stars.Int64)
-			r.Stars = &s
+// rankedFrom builds the FROM and WHERE clauses selecting the repos a RankQuery ranks.
+func rankedFrom(query domain.RankQuery) (string, []interface{}, error) {
+	var args []interface{}
+	baseQuery := `
+		FROM extracted_repos er`
+	if query.StaleBefore.IsZero() {
+		baseQuery += ` WHERE er.enrichment_status = 'SUCCESS'`
+	} else {
+		baseQuery += ` WHERE (er.enrichment_status = 'NOT_FOUND'
+			OR (er.enrichment_status = 'SUCCESS' AND (er.archived = 1 OR er.last_pushed_at < ?)))`
+		args = append(args, query.StaleBefore.UTC().Format(time.RFC3339))
+	}
+
+	if !query.IncludeOrphaned {
+		baseQuery += ` AND er.orphaned = 0`
+	}
+	if !query.IncludeArchived {
+		baseQuery += ` AND er.bookmark_archived = 0`
+	}
+	if query.ExcludeRepoArchived {
+		baseQuery += ` AND er.archived = 0`
+	}
+	if query.License != "" {
+		baseQuery += ` AND er.license = ? COLLATE NOCASE`
+		args = append(args, query.License)
+	}
+	if query.Topic != "" {
+		baseQuery += ` AND EXISTS (SELECT 1 FROM json_each(er.topics) WHERE json_each.value = ? COLLATE NOCASE)`
+		args = append(args, query.Topic)
+	}
+	if query.Tag != "" {
+		// Old behavior: search title/desc
+		// New behavior (from spec): filter by TAGs.
+		// "The karakeep rank command MUST support filtering by these locally stored tags via the --tag flag"
+		// However, users might expect existing title/desc search to work if --tag is ambiguous.
+		// But "filterTag" argument implies tag. Let's implement tag filtering via JOIN.
+		
+		baseQuery += ` AND EXISTS (
+			SELECT 1 FROM repo_tags rt
+			JOIN tags t ON rt.tag_id = t.id
+			WHERE rt.repo_id = er.repo_id AND t.name = ?`
+		args = append(args, query.Tag)
+		if query.TagSource != "" {
+			baseQuery += ` AND rt.attached_by = ?`
+			args = append(args, query.TagSource)
 		}
-		if forks.Valid {
-			f := int(forks.Int64)
-			r.Forks = &f
+		baseQuery += `
+		)`
+	}
+	if len(query.Where) > 0 {
+		whereSQL, whereArgs, err := filterSQL(query.Where)
+		if err != nil {
+			return "", nil, err
 		}
-		if lastPushedAt.Valid {
-			t := parseTime(lastPushedAt.String)
-			r.LastPushedAt = &t
+		baseQuery += whereSQL
+		args = append(args, whereArgs...)
+	}
+	return baseQuery, args, nil
+}
+
+// hydrateTags fills in the Tags of each repo.
+func (r *SQLiteRepository) hydrateTags(ctx context.Context, repos []domain.ExtractedRepo) error {
+	index := make(map[string]int, len(repos))
+	for i, repo := range repos {
+		index[repo.RepoID] = i
+	}
+
+	// Batched to stay well under SQLite's bound-parameter limit.
+	const batchSize = 500
+	for start := 0; start < len(repos); start += batchSize {
+		end := min(start+batchSize, len(repos))
+		args := make([]interface{}, 0, end-start)
+		for _, repo := range repos[start:end] {
+			args = append(args, repo.RepoID)
 		}
-		if description.Valid {
-			r.Description = &description.String
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(args)), ", ")
+
+		rows, err := r.db.QueryContext(ctx, `
+			SELECT rt.repo_id, t.name, rt.attached_by
+			FROM repo_tags rt
+			JOIN tags t ON t.id = rt.tag_id
+			WHERE rt.repo_id IN (`+placeholders+`)
+			ORDER BY t.name;`, args...)
+		if err != nil {
+			return fmt.Errorf("failed to query repo tags: %w", err)
 		}
-		if language.Valid {
-			r.Language = &language.String
+		for rows.Next() {
+			var repoID string
+			var tag domain.Tag
+			if err := rows.Scan(&repoID, &tag.Name, &tag.AttachedBy); err != nil {
+				rows.Close()
+				return fmt.Errorf("failed to scan repo tag: %w", err)
+			}
+			i := index[repoID]
+			repos[i].Tags = append(repos[i].Tags, tag)
 		}
-		if enrichmentStatus.Valid {
-			r.EnrichmentStatus = domain.EnrichmentStatus(enrichmentStatus.String)
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return fmt.Errorf("rows iteration error: %w", err)
 		}
+	}
+	return nil
+}
+
+// ReconcileBookmarks compares the stored repos against the complete set of bookmarks
+// currently in Karakeep. Repos whose source bookmark is gone are marked orphaned,
+// repos whose bookmark reappeared are restored, and the bookmark archived flag is refreshed.
+func (r *SQLiteRepository) ReconcileBookmarks(ctx context.Context, bookmarks []domain.BookmarkState) (domain.ReconcileResult, error) {
+	var result domain.ReconcileResult
 
-		repos = append(repos, r)
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return result, fmt.Errorf("failed to begin transaction: %w", err)
 	}
+	defer tx.Rollback()
 
-	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("rows iteration error: %w", err)
+	// Temp tables are per-connection; the transaction pins us to one connection
+	// and rolling back discards the table along with everything else.
+	if _, err := tx.ExecContext(ctx, `CREATE TEMP TABLE IF NOT EXISTS seen_bookmarks (id TEXT PRIMARY KEY, archived INTEGER NOT NULL);`); err != nil {
+		return result, fmt.Errorf("failed to create seen_bookmarks: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM seen_bookmarks;`); err != nil {
+		return result, fmt.Errorf("failed to clear seen_bookmarks: %w", err)
 	}
 
-	return repos, nil
-}
\ No newline at end of file
+	stmt, err := tx.PrepareContext(ctx, `INSERT OR REPLACE INTO seen_bookmarks (id, archived) VALUES (?, ?);`)
+	if err != nil {
+		return result, fmt.Errorf("failed to prepare seen_bookmarks insert: %w", err)
+	}
+	defer stmt.Close()
+	for _, bm := range bookmarks {
+		if _, err := stmt.ExecContext(ctx, bm.ID, bm.Archived); err != nil {
+			return result, fmt.Errorf("failed to record bookmark %s: %w", bm.ID, err)
+		}
+	}
+
+	// A repo is orphaned once none of its bookmarks exist, and archived once all remaining ones are.
+	res, err := tx.ExecContext(ctx, `
+		UPDATE extracted_repos SET orphaned = 1
+		WHERE orphaned = 0
+		AND EXISTS (SELECT 1 FROM bookmark_repos br WHERE br.repo_id = extracted_repos.repo_id)
+		AND NOT EXISTS (
+			SELECT 1 FROM bookmark_repos br JOIN seen_bookmarks sb ON sb.id = br.bookmark_id
+			WHERE br.repo_id = extracted_repos.repo_id
+		);`)
+	if err != nil {
+		return result, fmt.Errorf("failed to mark orphaned repos: %w", err)
+	}
+	orphaned, _ := res.RowsAffected()
+	result.Orphaned = int(orphaned)
+
+	res, err = tx.ExecContext(ctx, `
+		UPDATE extracted_repos SET orphaned = 0
+		WHERE orphaned = 1 AND EXISTS (
+			SELECT 1 FROM bookmark_repos br JOIN seen_bookmarks sb ON sb.id = br.bookmark_id
+			WHERE br.repo_id = extracted_repos.repo_id
+		);`)
+	if err != nil {
+		return result, fmt.Errorf("failed to restore repos: %w", err)
+	}
+	restored, _ := res.RowsAffected()
+	result.Restored = int(restored)
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE extracted_repos
+		SET bookmark_archived = COALESCE((
+			SELECT MIN(sb.archived) FROM bookmark_repos br JOIN seen_bookmarks sb ON sb.id = br.bookmark_id
+			WHERE br.repo_id = extracted_repos.repo_id
+		), bookmark_archived)
+		WHERE orphaned = 0;`)
+	if err != nil {
+		return result, fmt.Errorf("failed to update archived state: %w", err)
+	}
+
+	err = tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM extracted_repos WHERE bookmark_archived = 1 AND orphaned = 0;`).Scan(&result.Archived)
+	if err != nil {
+		return result, fmt.Errorf("failed to count archived repos: %w", err)
+	}
+
+	stmt.Close()
+	if _, err := tx.ExecContext(ctx, `DROP TABLE temp.seen_bookmarks;`); err != nil {
+		return result, fmt.Errorf("failed to drop seen_bookmarks: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return result, fmt.Errorf("failed to commit reconciliation: %w", err)
+	}
+	return result, nil
+}
+
+// GetSyncState returns the stored checkpoint for a bookmark source, or nil if it has never been synced.
+func (r *SQLiteRepository) GetSyncState(ctx context.Context, source string) (*domain.SyncState, error) {
+	const querySQL = `SELECT source, last_bookmark_id, last_bookmark_at, last_run_at FROM sync_state WHERE source = ?;
+	`
+	var state domain.SyncState
+	var lastBookmarkID, lastBookmarkAt, lastRunAt sql.NullString
+	err := r.db.QueryRowContext(ctx, querySQL, source).Scan(&state.Source, &lastBookmarkID, &lastBookmarkAt, &lastRunAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sync state: %w", err)
+	}
+
+	state.LastBookmarkID = lastBookmarkID.String
+	if lastBookmarkAt.Valid {
+		if t, err := time.Parse(time.RFC3339Nano, lastBookmarkAt.String); err == nil {
+			state.LastBookmarkAt = t
+		}
+	}
+	if lastRunAt.Valid {
+		if t, err := time.Parse(time.RFC3339Nano, lastRunAt.String); err == nil {
+			state.LastRunAt = t
+		}
+	}
+	return &state, nil
+}
+
+// SaveSyncState creates or replaces the checkpoint for a bookmark source.
+func (r *SQLiteRepository) SaveSyncState(ctx context.Context, state domain.SyncState) error {
+	const upsertSQL = `
+	INSERT INTO sync_state (source, last_bookmark_id, last_bookmark_at, last_run_at)
+	VALUES (?, ?, ?, ?)
+	ON CONFLICT(source) DO UPDATE SET
+		last_bookmark_id = excluded.last_bookmark_id,
+		last_bookmark_at = excluded.last_bookmark_at,
+		last_run_at = excluded.last_run_at;
+	`
+	var lastBookmarkAt interface{}
+	if !state.LastBookmarkAt.IsZero() {
+		lastBookmarkAt = state.LastBookmarkAt.UTC().Format(time.RFC3339Nano)
+	}
+
+	_, err := r.db.ExecContext(ctx, upsertSQL,
+		state.Source,
+		state.LastBookmarkID,
+		lastBookmarkAt,
+		state.LastRunAt.UTC().Format(time.RFC3339Nano),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save sync state: %w", err)
+	}
+	return nil
+}
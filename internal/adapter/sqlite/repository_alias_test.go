@@ -0,0 +1,205 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brianluby/karakeep-extractor/internal/core/domain"
+)
+
+func TestSQLiteRepository_RenamedRepo(t *testing.T) {
+	db, dbPath := newTestDB(t)
+	defer os.Remove(dbPath)
+	defer db.Close()
+
+	repo := NewSQLiteRepository(db)
+	ctx := context.Background()
+
+	if err := repo.InitSchema(ctx); err != nil {
+		t.Fatalf("InitSchema failed: %v", err)
+	}
+
+	err := repo.Save(ctx, domain.ExtractedRepo{RepoID: "old/name", URL: "https://github.com/old/name", SourceID: "bm-1", FoundAt: time.Now(), Tags: []domain.Tag{{Name: "go"}}})
+	if err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	err = repo.UpdateRepoEnrichment(ctx, domain.RepoEnrichmentUpdate{
+		RepoID:           "old/name",
+		Stats:            &domain.RepoStats{Stars: 42, FullName: "new-owner/name"},
+		EnrichmentStatus: domain.StatusSuccess,
+		CanonicalID:      "new-owner/name",
+		CanonicalURL:     "https://github.com/new-owner/name",
+	})
+	if err != nil {
+		t.Fatalf("UpdateRepoEnrichment failed: %v", err)
+	}
+
+	repos, err := repo.GetRankedRepos(ctx, domain.RankQuery{SortBy: domain.SortByStars})
+	if err != nil {
+		t.Fatalf("GetRankedRepos failed: %v", err)
+	}
+	if len(repos) != 1 || repos[0].RepoID != "new-owner/name" || repos[0].URL != "https://github.com/new-owner/name" {
+		t.Fatalf("Expected repo to move to new-owner/name, got %+v", repos)
+	}
+	if len(repos[0].Tags) != 1 || repos[0].BookmarkCount != 1 {
+		t.Errorf("Expected tags and bookmark link to move along, got %+v", repos[0])
+	}
+
+	// Bookmarking the old URL again resolves to the canonical repo.
+	if exists, _ := repo.Exists(ctx, "old/name"); !exists {
+		t.Errorf("Expected old ID to resolve through its alias")
+	}
+	existing, err := repo.ExistingRepos(ctx, []string{"old/name", "new-owner/name", "other/repo"})
+	if err != nil {
+		t.Fatalf("ExistingRepos failed: %v", err)
+	}
+	if len(existing) != 2 || !existing["old/name"] || !existing["new-owner/name"] {
+		t.Errorf("Expected the old and new IDs to exist, got %v", existing)
+	}
+	if err := repo.Save(ctx, domain.ExtractedRepo{RepoID: "old/name", URL: "https://github.com/old/name", SourceID: "bm-2", FoundAt: time.Now()}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	var rows int
+	db.QueryRow(`SELECT COUNT(*) FROM extracted_repos`).Scan(&rows)
+	if rows != 1 {
+		t.Errorf("Expected no duplicate row for the old ID, got %d rows", rows)
+	}
+}
+
+func TestSQLiteRepository_RenamedRepoMergesDuplicate(t *testing.T) {
+	db, dbPath := newTestDB(t)
+	defer os.Remove(dbPath)
+	defer db.Close()
+
+	repo := NewSQLiteRepository(db)
+	ctx := context.Background()
+
+	if err := repo.InitSchema(ctx); err != nil {
+		t.Fatalf("InitSchema failed: %v", err)
+	}
+
+	// The same repo was bookmarked under both names before the rename was noticed.
+	saves := []domain.ExtractedRepo{
+		{RepoID: "old/name", SourceID: "bm-1", FoundAt: time.Now().Add(-time.Hour), Tags: []domain.Tag{{Name: "go"}, {Name: "cli", AttachedBy: domain.TagSourceAI}}},
+		{RepoID: "new/name", SourceID: "bm-2", FoundAt: time.Now(), Tags: []domain.Tag{{Name: "go", AttachedBy: domain.TagSourceAI}}},
+	}
+	for _, r := range saves {
+		r.URL = "https://github.com/" + r.RepoID
+		if err := repo.Save(ctx, r); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+	}
+	repo.UpdateRepoEnrichment(ctx, domain.RepoEnrichmentUpdate{RepoID: "new/name", Stats: &domain.RepoStats{Stars: 1}, EnrichmentStatus: domain.StatusSuccess})
+
+	err := repo.UpdateRepoEnrichment(ctx, domain.RepoEnrichmentUpdate{
+		RepoID:           "old/name",
+		Stats:            &domain.RepoStats{Stars: 2},
+		EnrichmentStatus: domain.StatusSuccess,
+		CanonicalID:      "new/name",
+		CanonicalURL:     "https://github.com/new/name",
+	})
+	if err != nil {
+		t.Fatalf("UpdateRepoEnrichment failed: %v", err)
+	}
+
+	repos, err := repo.GetRankedRepos(ctx, domain.RankQuery{SortBy: domain.SortByStars})
+	if err != nil {
+		t.Fatalf("GetRankedRepos failed: %v", err)
+	}
+	if len(repos) != 1 || repos[0].RepoID != "new/name" {
+		t.Fatalf("Expected a single merged repo, got %+v", repos)
+	}
+	merged := repos[0]
+	if *merged.Stars != 2 || merged.BookmarkCount != 2 || len(merged.Tags) != 2 {
+		t.Errorf("Expected fresh stats, both bookmarks and both tags, got %+v", merged)
+	}
+	// "go" was attached by a human on the old name, which wins over the AI attaching it to the new one.
+	for _, tag := range merged.Tags {
+		if tag.Name == "go" && tag.AttachedBy != domain.TagSourceHuman {
+			t.Errorf("Expected the merged go tag to stay human-attached, got %+v", tag)
+		}
+	}
+
+	var history int
+	db.QueryRow(`SELECT COUNT(*) FROM repo_stats_history WHERE repo_id = 'new/name'`).Scan(&history)
+	if history != 2 {
+		t.Errorf("Expected both history snapshots under new/name, got %d", history)
+	}
+}
+
+func TestSQLiteRepository_RepoIDCase(t *testing.T) {
+	db, dbPath := newTestDB(t)
+	defer os.Remove(dbPath)
+	defer db.Close()
+
+	repo := NewSQLiteRepository(db)
+	ctx := context.Background()
+
+	if err := repo.InitSchema(ctx); err != nil {
+		t.Fatalf("InitSchema failed: %v", err)
+	}
+
+	// The same repo bookmarked with differently cased URLs is stored once, under the first spelling.
+	for i, id := range []string{"BurntSushi/ripgrep", "burntsushi/ripgrep"} {
+		r := domain.ExtractedRepo{RepoID: id, URL: "https://github.com/" + id, SourceID: fmt.Sprintf("bm-%d", i), FoundAt: time.Now()}
+		if err := repo.Save(ctx, r); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+	}
+	if exists, _ := repo.Exists(ctx, "BURNTSUSHI/RIPGREP"); !exists {
+		t.Errorf("Expected Exists to ignore case")
+	}
+	existing, err := repo.ExistingRepos(ctx, []string{"burntsushi/ripgrep", "BurntSushi/ripgrep"})
+	if err != nil {
+		t.Fatalf("ExistingRepos failed: %v", err)
+	}
+	if !existing["burntsushi/ripgrep"] || !existing["BurntSushi/ripgrep"] {
+		t.Errorf("Expected ExistingRepos to ignore case, got %v", existing)
+	}
+
+	// A forge reporting the name in another case doesn't move the repo.
+	err = repo.UpdateRepoEnrichment(ctx, domain.RepoEnrichmentUpdate{
+		RepoID:           "BurntSushi/ripgrep",
+		Stats:            &domain.RepoStats{Stars: 1},
+		EnrichmentStatus: domain.StatusSuccess,
+		CanonicalID:      "burntsushi/RipGrep",
+		CanonicalURL:     "https://github.com/burntsushi/RipGrep",
+	})
+	if err != nil {
+		t.Fatalf("UpdateRepoEnrichment failed: %v", err)
+	}
+
+	// A rename onto a stored repo spelled in another case merges into it.
+	for _, id := range []string{"old/tool", "New/Tool"} {
+		if err := repo.Save(ctx, domain.ExtractedRepo{RepoID: id, URL: "https://github.com/" + id, SourceID: id, FoundAt: time.Now()}); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+	}
+	err = repo.UpdateRepoEnrichment(ctx, domain.RepoEnrichmentUpdate{
+		RepoID:           "old/tool",
+		Stats:            &domain.RepoStats{Stars: 2},
+		EnrichmentStatus: domain.StatusSuccess,
+		CanonicalID:      "new/tool",
+		CanonicalURL:     "https://github.com/new/tool",
+	})
+	if err != nil {
+		t.Fatalf("UpdateRepoEnrichment failed: %v", err)
+	}
+
+	repos, err := repo.GetRankedRepos(ctx, domain.RankQuery{SortBy: domain.SortByStars})
+	if err != nil {
+		t.Fatalf("GetRankedRepos failed: %v", err)
+	}
+	var ids []string
+	for _, r := range repos {
+		ids = append(ids, fmt.Sprintf("%s(%d)", r.RepoID, r.BookmarkCount))
+	}
+	if got := strings.Join(ids, " "); got != "New/Tool(2) BurntSushi/ripgrep(2)" {
+		t.Errorf("Expected one row per repo, got %s", got)
+	}
+}
@@ -0,0 +1,70 @@
+package sqlite
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/brianluby/karakeep-extractor/internal/core/domain"
+)
+
+func TestSQLiteRepository_CacheValidators(t *testing.T) {
+	db, dbPath := newTestDB(t)
+	defer os.Remove(dbPath)
+	defer db.Close()
+
+	repo := NewSQLiteRepository(db)
+	ctx := context.Background()
+
+	if err := repo.InitSchema(ctx); err != nil {
+		t.Fatalf("InitSchema failed: %v", err)
+	}
+	if err := repo.Save(ctx, domain.ExtractedRepo{RepoID: "owner/repo", URL: "https://github.com/owner/repo", FoundAt: time.Now()}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	if err := repo.UpdateRepoEnrichment(ctx, domain.RepoEnrichmentUpdate{
+		RepoID:           "owner/repo",
+		Stats:            &domain.RepoStats{Stars: 42, LastPushed: time.Now(), ETag: `W/"abc"`, LastModified: "Mon, 01 Jan 2024 00:00:00 GMT"},
+		EnrichmentStatus: domain.StatusSuccess,
+	}); err != nil {
+		t.Fatalf("UpdateRepoEnrichment failed: %v", err)
+	}
+
+	repos, err := repo.GetReposForEnrichment(ctx, 10, true)
+	if err != nil || len(repos) != 1 {
+		t.Fatalf("GetReposForEnrichment returned %d repos, err %v", len(repos), err)
+	}
+	got := repos[0]
+	if got.ETag != `W/"abc"` || got.LastModified != "Mon, 01 Jan 2024 00:00:00 GMT" {
+		t.Errorf("Cache validators not stored: %q, %q", got.ETag, got.LastModified)
+	}
+	if got.LastCheckedAt == nil {
+		t.Fatal("Expected last_checked_at to be set")
+	}
+
+	// Back-date the check, then record a 304.
+	if _, err := db.ExecContext(ctx, `UPDATE extracted_repos SET last_checked_at = '2020-01-01T00:00:00Z'`); err != nil {
+		t.Fatalf("Failed to back-date last_checked_at: %v", err)
+	}
+	if err := repo.UpdateRepoEnrichment(ctx, domain.RepoEnrichmentUpdate{
+		RepoID:           "owner/repo",
+		NotModified:      true,
+		EnrichmentStatus: domain.StatusSuccess,
+	}); err != nil {
+		t.Fatalf("UpdateRepoEnrichment (not modified) failed: %v", err)
+	}
+
+	repos, _ = repo.GetReposForEnrichment(ctx, 10, true)
+	got = repos[0]
+	if got.Stars == nil || *got.Stars != 42 {
+		t.Errorf("Expected stats to be kept on 304, got %v", got.Stars)
+	}
+	if got.ETag != `W/"abc"` {
+		t.Errorf("Expected ETag to be kept on 304, got %q", got.ETag)
+	}
+	if got.LastCheckedAt == nil || got.LastCheckedAt.Year() == 2020 {
+		t.Errorf("Expected last_checked_at to be bumped, got %v", got.LastCheckedAt)
+	}
+}
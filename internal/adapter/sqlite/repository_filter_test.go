@@ -28,7 +28,7 @@ func TestSQLiteRepository_GetRankedRepos_Filter(t *testing.T) {
 		URL: "url1", 
 		Title: "Python Tool", 
 		FoundAt: time.Now(),
-		Tags: []string{"python", "data"},
+		Tags: []domain.Tag{{Name: "python"}, {Name: "data"}},
 	}
 	repo.Save(ctx, repo1)
 	repo.UpdateRepoEnrichment(ctx, domain.RepoEnrichmentUpdate{
@@ -42,7 +42,7 @@ func TestSQLiteRepository_GetRankedRepos_Filter(t *testing.T) {
 		URL: "url2", 
 		Title: "Go CLI", 
 		FoundAt: time.Now(),
-		Tags: []string{"golang", "cli"},
+		Tags: []domain.Tag{{Name: "golang"}, {Name: "cli"}},
 	}
 	repo.Save(ctx, repo2)
 	repo.UpdateRepoEnrichment(ctx, domain.RepoEnrichmentUpdate{
@@ -52,7 +52,7 @@ func TestSQLiteRepository_GetRankedRepos_Filter(t *testing.T) {
 	})
 
 	// Test Filter by Tag ("python")
-	repos, err := repo.GetRankedRepos(ctx, 10, domain.SortByStars, "python")
+	repos, err := repo.GetRankedRepos(ctx, domain.RankQuery{Limit: 10, SortBy: domain.SortByStars, Tag: "python"})
 	if err != nil {
 		t.Fatalf("Filter(python) failed: %v", err)
 	}
@@ -61,7 +61,7 @@ func TestSQLiteRepository_GetRankedRepos_Filter(t *testing.T) {
 	}
 
 	// Test Filter by Tag ("cli")
-	repos, err = repo.GetRankedRepos(ctx, 10, domain.SortByStars, "cli")
+	repos, err = repo.GetRankedRepos(ctx, domain.RankQuery{Limit: 10, SortBy: domain.SortByStars, Tag: "cli"})
 	if err != nil {
 		t.Fatalf("Filter(cli) failed: %v", err)
 	}
@@ -70,7 +70,7 @@ func TestSQLiteRepository_GetRankedRepos_Filter(t *testing.T) {
 	}
 
 	// Test No Match
-	repos, err = repo.GetRankedRepos(ctx, 10, domain.SortByStars, "java")
+	repos, err = repo.GetRankedRepos(ctx, domain.RankQuery{Limit: 10, SortBy: domain.SortByStars, Tag: "java"})
 	if err != nil {
 		t.Fatalf("Filter(java) failed: %v", err)
 	}
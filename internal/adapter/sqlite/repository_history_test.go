@@ -0,0 +1,97 @@
+package sqlite
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/brianluby/karakeep-extractor/internal/core/domain"
+)
+
+func TestSQLiteRepository_StatsHistory(t *testing.T) {
+	db, dbPath := newTestDB(t)
+	defer os.Remove(dbPath)
+	defer db.Close()
+
+	repo := NewSQLiteRepository(db)
+	ctx := context.Background()
+
+	if err := repo.InitSchema(ctx); err != nil {
+		t.Fatalf("InitSchema failed: %v", err)
+	}
+
+	for _, id := range []string{"owner/steady", "owner/rising", "owner/new"} {
+		if err := repo.Save(ctx, domain.ExtractedRepo{RepoID: id, URL: "https://github.com/" + id, FoundAt: time.Now()}); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+	}
+
+	enrich := func(id string, stars int) {
+		t.Helper()
+		if err := repo.UpdateRepoEnrichment(ctx, domain.RepoEnrichmentUpdate{
+			RepoID:           id,
+			Stats:            &domain.RepoStats{Stars: stars, LastPushed: time.Now()},
+			EnrichmentStatus: domain.StatusSuccess,
+		}); err != nil {
+			t.Fatalf("UpdateRepoEnrichment failed: %v", err)
+		}
+	}
+
+	// Snapshots from 40 days ago, back-dated after insertion.
+	enrich("owner/steady", 1000)
+	enrich("owner/rising", 100)
+	old := time.Now().AddDate(0, 0, -40).UTC().Format(time.RFC3339)
+	if _, err := db.ExecContext(ctx, `UPDATE repo_stats_history SET recorded_at = ?`, old); err != nil {
+		t.Fatalf("Failed to back-date history: %v", err)
+	}
+
+	enrich("owner/steady", 1010)
+	enrich("owner/rising", 400)
+	enrich("owner/new", 50)
+
+	var count int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM repo_stats_history`).Scan(&count); err != nil {
+		t.Fatalf("Failed to count history: %v", err)
+	}
+	if count != 5 {
+		t.Errorf("Expected 5 history rows, got %d", count)
+	}
+
+	// A 304 doesn't add a snapshot.
+	if err := repo.UpdateRepoEnrichment(ctx, domain.RepoEnrichmentUpdate{RepoID: "owner/new", NotModified: true, EnrichmentStatus: domain.StatusSuccess}); err != nil {
+		t.Fatalf("UpdateRepoEnrichment (not modified) failed: %v", err)
+	}
+
+	repos, err := repo.GetRankedRepos(ctx, domain.RankQuery{Limit: 10, SortBy: domain.SortByStarsDelta, Since: 30 * 24 * time.Hour})
+	if err != nil {
+		t.Fatalf("GetRankedRepos failed: %v", err)
+	}
+	if len(repos) != 3 {
+		t.Fatalf("Expected 3 repos, got %d", len(repos))
+	}
+
+	want := []struct {
+		id    string
+		delta int
+	}{
+		{"owner/rising", 300},
+		{"owner/steady", 10},
+		{"owner/new", 0}, // Only snapshot is inside the window.
+	}
+	for i, w := range want {
+		if repos[i].RepoID != w.id {
+			t.Errorf("Rank %d: expected %s, got %s", i+1, w.id, repos[i].RepoID)
+			continue
+		}
+		if repos[i].StarsDelta == nil || *repos[i].StarsDelta != w.delta {
+			t.Errorf("%s: expected delta %d, got %v", w.id, w.delta, repos[i].StarsDelta)
+		}
+	}
+
+	// Other sorts leave StarsDelta unset.
+	repos, _ = repo.GetRankedRepos(ctx, domain.RankQuery{Limit: 10, SortBy: domain.SortByStars})
+	if repos[0].StarsDelta != nil {
+		t.Errorf("Expected no delta for stars sort, got %d", *repos[0].StarsDelta)
+	}
+}
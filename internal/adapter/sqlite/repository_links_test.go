@@ -0,0 +1,77 @@
+package sqlite
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/brianluby/karakeep-extractor/internal/core/domain"
+)
+
+func TestSQLiteRepository_BookmarkLinks(t *testing.T) {
+	db, dbPath := newTestDB(t)
+	defer os.Remove(dbPath)
+	defer db.Close()
+
+	repo := NewSQLiteRepository(db)
+	ctx := context.Background()
+
+	if err := repo.InitSchema(ctx); err != nil {
+		t.Fatalf("InitSchema failed: %v", err)
+	}
+
+	saves := []domain.ExtractedRepo{
+		{RepoID: "owner/popular", SourceID: "bm-1", LinkKind: domain.LinkContent},
+		{RepoID: "owner/popular", SourceID: "bm-2"},
+		{RepoID: "owner/popular", SourceID: "bm-3", LinkKind: domain.LinkContent},
+		// The same bookmark linking the repo directly upgrades the link to primary.
+		{RepoID: "owner/popular", SourceID: "bm-1", LinkKind: domain.LinkPrimary},
+		{RepoID: "owner/starred", SourceID: "bm-4"},
+	}
+	for _, r := range saves {
+		r.URL = "https://github.com/" + r.RepoID
+		r.FoundAt = time.Now()
+		if err := repo.Save(ctx, r); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+	}
+	repo.UpdateRepoEnrichment(ctx, domain.RepoEnrichmentUpdate{RepoID: "owner/popular", Stats: &domain.RepoStats{Stars: 10}, EnrichmentStatus: domain.StatusSuccess})
+	repo.UpdateRepoEnrichment(ctx, domain.RepoEnrichmentUpdate{RepoID: "owner/starred", Stats: &domain.RepoStats{Stars: 5000}, EnrichmentStatus: domain.StatusSuccess})
+
+	var kind string
+	if err := db.QueryRow(`SELECT kind FROM bookmark_repos WHERE bookmark_id = 'bm-1'`).Scan(&kind); err != nil {
+		t.Fatalf("Failed to read link: %v", err)
+	}
+	if kind != string(domain.LinkPrimary) {
+		t.Errorf("Expected bm-1 link to be upgraded to primary, got %s", kind)
+	}
+
+	repos, err := repo.GetRankedRepos(ctx, domain.RankQuery{SortBy: domain.SortByBookmarks})
+	if err != nil {
+		t.Fatalf("GetRankedRepos failed: %v", err)
+	}
+	if len(repos) != 2 || repos[0].RepoID != "owner/popular" || repos[0].BookmarkCount != 3 || repos[1].BookmarkCount != 1 {
+		t.Fatalf("Expected owner/popular first with 3 bookmarks, got %+v", repos)
+	}
+
+	// A repo stays visible while any of its bookmarks exists, and is archived only when all are.
+	result, err := repo.ReconcileBookmarks(ctx, []domain.BookmarkState{
+		{ID: "bm-2", Archived: true},
+		{ID: "bm-3"},
+		{ID: "bm-4", Archived: true},
+	})
+	if err != nil {
+		t.Fatalf("ReconcileBookmarks failed: %v", err)
+	}
+	if result.Orphaned != 0 || result.Archived != 1 {
+		t.Errorf("Unexpected reconcile result: %+v", result)
+	}
+	repos, err = repo.GetRankedRepos(ctx, domain.RankQuery{SortBy: domain.SortByBookmarks})
+	if err != nil {
+		t.Fatalf("GetRankedRepos failed: %v", err)
+	}
+	if len(repos) != 1 || repos[0].RepoID != "owner/popular" {
+		t.Errorf("Expected only owner/popular to remain visible, got %+v", repos)
+	}
+}
@@ -0,0 +1,85 @@
+package sqlite
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/brianluby/karakeep-extractor/internal/core/domain"
+)
+
+func TestSQLiteRepository_Metadata(t *testing.T) {
+	db, dbPath := newTestDB(t)
+	defer os.Remove(dbPath)
+	defer db.Close()
+
+	repo := NewSQLiteRepository(db)
+	ctx := context.Background()
+
+	if err := repo.InitSchema(ctx); err != nil {
+		t.Fatalf("InitSchema failed: %v", err)
+	}
+
+	created := time.Date(2015, 3, 1, 0, 0, 0, 0, time.UTC)
+	updates := []domain.RepoEnrichmentUpdate{
+		{RepoID: "owner/cli", Stats: &domain.RepoStats{
+			Stars: 30, Topics: []string{"cli", "golang"}, License: "MIT", Fork: true, Parent: "upstream/cli",
+			OpenIssues: 4, Homepage: "https://cli.dev", CreatedAt: created, DefaultBranch: "main", SizeKB: 1200,
+		}},
+		{RepoID: "owner/lib", Stats: &domain.RepoStats{Stars: 20, Topics: []string{"golang"}, License: "Apache-2.0"}},
+		{RepoID: "owner/old", Stats: &domain.RepoStats{Stars: 10, License: "mit", Archived: true}},
+	}
+	for _, u := range updates {
+		if err := repo.Save(ctx, domain.ExtractedRepo{RepoID: u.RepoID, URL: "https://github.com/" + u.RepoID, FoundAt: time.Now()}); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+		u.EnrichmentStatus = domain.StatusSuccess
+		if err := repo.UpdateRepoEnrichment(ctx, u); err != nil {
+			t.Fatalf("UpdateRepoEnrichment failed: %v", err)
+		}
+	}
+
+	repos, err := repo.GetRankedRepos(ctx, domain.RankQuery{SortBy: domain.SortByStars, Limit: 1})
+	if err != nil {
+		t.Fatalf("GetRankedRepos failed: %v", err)
+	}
+	got := repos[0]
+	if len(got.Topics) != 2 || got.License != "MIT" || !got.IsFork || got.Parent != "upstream/cli" ||
+		got.OpenIssues == nil || *got.OpenIssues != 4 || got.Homepage != "https://cli.dev" ||
+		got.CreatedAt == nil || !got.CreatedAt.Equal(created) || got.DefaultBranch != "main" ||
+		got.SizeKB == nil || *got.SizeKB != 1200 {
+		t.Errorf("Metadata did not round-trip: %+v", got)
+	}
+
+	tests := []struct {
+		name  string
+		query domain.RankQuery
+		want  []string
+	}{
+		{"license is case-insensitive", domain.RankQuery{License: "MIT"}, []string{"owner/cli", "owner/old"}},
+		{"topic", domain.RankQuery{Topic: "golang"}, []string{"owner/cli", "owner/lib"}},
+		{"exclude archived", domain.RankQuery{License: "mit", ExcludeRepoArchived: true}, []string{"owner/cli"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.query.SortBy = domain.SortByStars
+			repos, err := repo.GetRankedRepos(ctx, tc.query)
+			if err != nil {
+				t.Fatalf("GetRankedRepos failed: %v", err)
+			}
+			var ids []string
+			for _, r := range repos {
+				ids = append(ids, r.RepoID)
+			}
+			if len(ids) != len(tc.want) {
+				t.Fatalf("Expected %v, got %v", tc.want, ids)
+			}
+			for i := range ids {
+				if ids[i] != tc.want[i] {
+					t.Errorf("Expected %v, got %v", tc.want, ids)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package sqlite
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/brianluby/karakeep-extractor/internal/core/domain"
+)
+
+func TestSQLiteRepository_GetRankedRepos_Paging(t *testing.T) {
+	db, dbPath := newTestDB(t)
+	defer os.Remove(dbPath)
+	defer db.Close()
+
+	repo := NewSQLiteRepository(db)
+	ctx := context.Background()
+
+	if err := repo.InitSchema(ctx); err != nil {
+		t.Fatalf("InitSchema failed: %v", err)
+	}
+
+	// Saved out of order, with tied star counts, to exercise the repo_id tiebreak.
+	seed := []struct {
+		id    string
+		stars int
+		lang  string
+	}{
+		{"owner/d", 100, "Go"},
+		{"owner/b", 100, "Go"},
+		{"owner/top", 500, "Rust"},
+		{"owner/c", 100, "Go"},
+		{"owner/a", 100, "Go"},
+	}
+	for _, s := range seed {
+		if err := repo.Save(ctx, domain.ExtractedRepo{RepoID: s.id, URL: "https://github.com/" + s.id, FoundAt: time.Now()}); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+		stats := domain.RepoStats{Stars: s.stars, Language: s.lang, LastPushed: time.Now()}
+		if err := repo.UpdateRepoEnrichment(ctx, domain.RepoEnrichmentUpdate{RepoID: s.id, Stats: &stats, EnrichmentStatus: domain.StatusSuccess}); err != nil {
+			t.Fatalf("UpdateRepoEnrichment failed: %v", err)
+		}
+	}
+
+	pages := [][]string{
+		{"owner/top", "owner/a"},
+		{"owner/b", "owner/c"},
+		{"owner/d"},
+		nil,
+	}
+	for i, want := range pages {
+		repos, err := repo.GetRankedRepos(ctx, domain.RankQuery{SortBy: domain.SortByStars, Limit: 2, Offset: i * 2})
+		if err != nil {
+			t.Fatalf("GetRankedRepos failed: %v", err)
+		}
+		if len(repos) != len(want) {
+			t.Fatalf("page %d: got %d repos, want %v", i+1, len(repos), want)
+		}
+		for j := range want {
+			if repos[j].RepoID != want[j] {
+				t.Errorf("page %d: got %s at %d, want %s", i+1, repos[j].RepoID, j, want[j])
+			}
+		}
+	}
+
+	// The count ignores Limit and Offset but honors the filters.
+	count, err := repo.CountRankedRepos(ctx, domain.RankQuery{SortBy: domain.SortByStars, Limit: 2, Offset: 2})
+	if err != nil {
+		t.Fatalf("CountRankedRepos failed: %v", err)
+	}
+	if count != 5 {
+		t.Errorf("expected 5 repos, got %d", count)
+	}
+	count, err = repo.CountRankedRepos(ctx, domain.RankQuery{Where: []domain.FilterTerm{{Field: domain.FilterLang, Op: domain.OpEq, Value: "go"}}})
+	if err != nil {
+		t.Fatalf("CountRankedRepos failed: %v", err)
+	}
+	if count != 4 {
+		t.Errorf("expected 4 Go repos, got %d", count)
+	}
+}
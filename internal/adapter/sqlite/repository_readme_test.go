@@ -0,0 +1,74 @@
+package sqlite
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/brianluby/karakeep-extractor/internal/core/domain"
+)
+
+func TestSQLiteRepository_Readme(t *testing.T) {
+	db, dbPath := newTestDB(t)
+	defer os.Remove(dbPath)
+	defer db.Close()
+
+	repo := NewSQLiteRepository(db)
+	ctx := context.Background()
+
+	if err := repo.InitSchema(ctx); err != nil {
+		t.Fatalf("InitSchema failed: %v", err)
+	}
+
+	for _, id := range []string{"owner/streamer", "owner/other"} {
+		if err := repo.Save(ctx, domain.ExtractedRepo{RepoID: id, URL: "https://github.com/" + id, Title: id, FoundAt: time.Now()}); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+		if err := repo.UpdateRepoEnrichment(ctx, domain.RepoEnrichmentUpdate{RepoID: id, Stats: &domain.RepoStats{LastPushed: time.Now().Add(-time.Hour)}, EnrichmentStatus: domain.StatusSuccess}); err != nil {
+			t.Fatalf("UpdateRepoEnrichment failed: %v", err)
+		}
+	}
+
+	pending, err := repo.GetReposForReadme(ctx, 0, false)
+	if err != nil {
+		t.Fatalf("GetReposForReadme failed: %v", err)
+	}
+	if len(pending) != 2 {
+		t.Fatalf("Expected 2 repos needing a README, got %d", len(pending))
+	}
+
+	if err := repo.UpdateRepoReadme(ctx, domain.ReadmeUpdate{RepoID: "owner/streamer", SHA: "sha1", Text: "Supports zerocopy streaming."}); err != nil {
+		t.Fatalf("UpdateRepoReadme failed: %v", err)
+	}
+	if err := repo.UpdateRepoReadme(ctx, domain.ReadmeUpdate{RepoID: "owner/other"}); err != nil {
+		t.Fatalf("UpdateRepoReadme failed: %v", err)
+	}
+
+	// Checked READMEs are skipped until the repo is pushed to again, unless forced.
+	if pending, _ = repo.GetReposForReadme(ctx, 0, false); len(pending) != 0 {
+		t.Errorf("Expected no repos needing a README, got %d", len(pending))
+	}
+	if pending, _ = repo.GetReposForReadme(ctx, 0, true); len(pending) != 2 {
+		t.Errorf("Expected force to return both repos, got %d", len(pending))
+	}
+
+	repos, err := repo.SearchRepos(ctx, domain.SearchQuery{Text: "zerocopy"})
+	if err != nil {
+		t.Fatalf("SearchRepos failed: %v", err)
+	}
+	if len(repos) != 1 || repos[0].RepoID != "owner/streamer" || repos[0].ReadmeSHA != "sha1" {
+		t.Fatalf("Expected README text to be searchable, got %+v", repos)
+	}
+
+	// README text is only loaded on request.
+	if repos[0].Readme != "" {
+		t.Errorf("Expected README text not to be loaded by search")
+	}
+	if err := repo.HydrateReadmes(ctx, repos); err != nil {
+		t.Fatalf("HydrateReadmes failed: %v", err)
+	}
+	if repos[0].Readme != "Supports zerocopy streaming." {
+		t.Errorf("Unexpected README text: %q", repos[0].Readme)
+	}
+}
@@ -0,0 +1,85 @@
+package sqlite
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/brianluby/karakeep-extractor/internal/core/domain"
+)
+
+func TestSQLiteRepository_ReconcileBookmarks(t *testing.T) {
+	db, dbPath := newTestDB(t)
+	defer os.Remove(dbPath)
+	defer db.Close()
+
+	repo := NewSQLiteRepository(db)
+	ctx := context.Background()
+
+	if err := repo.InitSchema(ctx); err != nil {
+		t.Fatalf("InitSchema failed: %v", err)
+	}
+
+	// Seed: one repo per bookmark, all enriched so they are rankable.
+	for _, seed := range []struct{ repoID, sourceID string }{
+		{"owner/kept", "bm-kept"},
+		{"owner/deleted", "bm-deleted"},
+		{"owner/archived", "bm-archived"},
+	} {
+		if err := repo.Save(ctx, domain.ExtractedRepo{RepoID: seed.repoID, URL: "url", SourceID: seed.sourceID, FoundAt: time.Now()}); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+		repo.UpdateRepoEnrichment(ctx, domain.RepoEnrichmentUpdate{
+			RepoID:           seed.repoID,
+			Stats:            &domain.RepoStats{Stars: 10},
+			EnrichmentStatus: domain.StatusSuccess,
+		})
+	}
+
+	result, err := repo.ReconcileBookmarks(ctx, []domain.BookmarkState{
+		{ID: "bm-kept"},
+		{ID: "bm-archived", Archived: true},
+	})
+	if err != nil {
+		t.Fatalf("ReconcileBookmarks failed: %v", err)
+	}
+	if result.Orphaned != 1 || result.Restored != 0 || result.Archived != 1 {
+		t.Errorf("Unexpected reconcile result: %+v", result)
+	}
+
+	// Default ranking hides orphaned and archived repos.
+	repos, err := repo.GetRankedRepos(ctx, domain.RankQuery{Limit: 10, SortBy: domain.SortByStars})
+	if err != nil {
+		t.Fatalf("GetRankedRepos failed: %v", err)
+	}
+	if len(repos) != 1 || repos[0].RepoID != "owner/kept" {
+		t.Errorf("Expected only owner/kept, got %+v", repos)
+	}
+
+	// Including them flags each row.
+	repos, err = repo.GetRankedRepos(ctx, domain.RankQuery{Limit: 10, SortBy: domain.SortByStars, IncludeOrphaned: true, IncludeArchived: true})
+	if err != nil {
+		t.Fatalf("GetRankedRepos failed: %v", err)
+	}
+	flags := map[string][2]bool{}
+	for _, r := range repos {
+		flags[r.RepoID] = [2]bool{r.Orphaned, r.BookmarkArchived}
+	}
+	if len(flags) != 3 || !flags["owner/deleted"][0] || !flags["owner/archived"][1] || flags["owner/kept"] != [2]bool{} {
+		t.Errorf("Unexpected flags: %+v", flags)
+	}
+
+	// A bookmark that reappears restores its repo.
+	result, err = repo.ReconcileBookmarks(ctx, []domain.BookmarkState{
+		{ID: "bm-kept"},
+		{ID: "bm-deleted"},
+		{ID: "bm-archived"},
+	})
+	if err != nil {
+		t.Fatalf("ReconcileBookmarks failed: %v", err)
+	}
+	if result.Orphaned != 0 || result.Restored != 1 || result.Archived != 0 {
+		t.Errorf("Unexpected reconcile result: %+v", result)
+	}
+}
@@ -0,0 +1,94 @@
+package sqlite
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/brianluby/karakeep-extractor/internal/core/domain"
+)
+
+func TestSQLiteRepository_Releases(t *testing.T) {
+	db, dbPath := newTestDB(t)
+	defer os.Remove(dbPath)
+	defer db.Close()
+
+	repo := NewSQLiteRepository(db)
+	ctx := context.Background()
+
+	if err := repo.InitSchema(ctx); err != nil {
+		t.Fatalf("InitSchema failed: %v", err)
+	}
+
+	for _, id := range []string{"owner/fresh", "owner/old", "owner/none"} {
+		if err := repo.Save(ctx, domain.ExtractedRepo{RepoID: id, URL: "https://github.com/" + id, Title: id, FoundAt: time.Now()}); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+		if err := repo.UpdateRepoEnrichment(ctx, domain.RepoEnrichmentUpdate{RepoID: id, Stats: &domain.RepoStats{LastPushed: time.Now().Add(-time.Hour)}, EnrichmentStatus: domain.StatusSuccess}); err != nil {
+			t.Fatalf("UpdateRepoEnrichment failed: %v", err)
+		}
+	}
+
+	if pending, err := repo.GetReposForReleases(ctx, 0, false); err != nil || len(pending) != 3 {
+		t.Fatalf("Expected 3 repos to check, got %d (%v)", len(pending), err)
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	updates := []domain.ReleaseUpdate{
+		{RepoID: "owner/fresh", Release: &domain.Release{Tag: "v2.0.0-rc1", PublishedAt: now.Add(-2 * time.Hour), Prerelease: true}},
+		{RepoID: "owner/old", Release: &domain.Release{Tag: "v0.1", PublishedAt: now.AddDate(0, -2, 0), FromTag: true}},
+		{RepoID: "owner/none"},
+	}
+	for _, u := range updates {
+		if err := repo.UpdateRepoRelease(ctx, u); err != nil {
+			t.Fatalf("UpdateRepoRelease failed: %v", err)
+		}
+	}
+
+	// Checked repos are skipped until they are pushed to again, unless forced.
+	if pending, _ := repo.GetReposForReleases(ctx, 0, false); len(pending) != 0 {
+		t.Errorf("Expected no repos to check, got %d", len(pending))
+	}
+	if pending, _ := repo.GetReposForReleases(ctx, 0, true); len(pending) != 3 {
+		t.Errorf("Expected force to return all repos, got %d", len(pending))
+	}
+
+	repos, err := repo.GetNewReleases(ctx, domain.ReleaseQuery{PublishedAfter: now.AddDate(0, 0, -7)})
+	if err != nil {
+		t.Fatalf("GetNewReleases failed: %v", err)
+	}
+	if len(repos) != 1 || repos[0].RepoID != "owner/fresh" {
+		t.Fatalf("Expected only owner/fresh, got %+v", repos)
+	}
+	want := domain.Release{Tag: "v2.0.0-rc1", PublishedAt: now.Add(-2 * time.Hour), Prerelease: true}
+	if got := repos[0].LatestRelease; got == nil || got.Tag != want.Tag || !got.PublishedAt.Equal(want.PublishedAt) || !got.Prerelease || got.FromTag {
+		t.Errorf("Expected release %+v, got %+v", want, got)
+	}
+
+	repos, err = repo.GetNewReleases(ctx, domain.ReleaseQuery{PublishedAfter: now.AddDate(-1, 0, 0)})
+	if err != nil {
+		t.Fatalf("GetNewReleases failed: %v", err)
+	}
+	if len(repos) != 2 || repos[0].RepoID != "owner/fresh" || repos[1].RepoID != "owner/old" || !repos[1].LatestRelease.FromTag {
+		t.Errorf("Expected owner/fresh then owner/old, got %+v", repos)
+	}
+
+	// A release published before the last run but recorded after it is still new.
+	lastRun := time.Now().Add(-time.Minute)
+	db.Exec(`UPDATE extracted_repos SET release_seen_at = ?;`, lastRun.Add(-time.Hour).UTC().Format(time.RFC3339))
+	if err := repo.UpdateRepoRelease(ctx, domain.ReleaseUpdate{RepoID: "owner/none", Release: &domain.Release{Tag: "v1.0", PublishedAt: now.AddDate(0, -1, 0)}}); err != nil {
+		t.Fatalf("UpdateRepoRelease failed: %v", err)
+	}
+	// Checking again without a new tag doesn't make the release new.
+	if err := repo.UpdateRepoRelease(ctx, updates[1]); err != nil {
+		t.Fatalf("UpdateRepoRelease failed: %v", err)
+	}
+	repos, err = repo.GetNewReleases(ctx, domain.ReleaseQuery{SeenAfter: lastRun})
+	if err != nil {
+		t.Fatalf("GetNewReleases failed: %v", err)
+	}
+	if len(repos) != 1 || repos[0].RepoID != "owner/none" {
+		t.Errorf("Expected only the newly recorded owner/none, got %+v", repos)
+	}
+}
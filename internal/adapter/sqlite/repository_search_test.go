@@ -0,0 +1,153 @@
+package sqlite
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brianluby/karakeep-extractor/internal/core/domain"
+)
+
+func newSearchTestRepo(t *testing.T) *SQLiteRepository {
+	t.Helper()
+	db, dbPath := newTestDB(t)
+	t.Cleanup(func() {
+		db.Close()
+		os.Remove(dbPath)
+	})
+
+	repo := NewSQLiteRepository(db)
+	ctx := context.Background()
+	if err := repo.InitSchema(ctx); err != nil {
+		t.Fatalf("InitSchema failed: %v", err)
+	}
+
+	seed := []domain.ExtractedRepo{
+		{RepoID: "tokio-rs/tokio", Title: "Tokio async runtime", Tags: []domain.Tag{{Name: "rust"}, {Name: "async"}}},
+		{RepoID: "spf13/cobra", Title: "Cobra CLI library", Tags: []domain.Tag{{Name: "go"}, {Name: "cli"}}},
+		{RepoID: "clap-rs/clap", Title: "Command line argument parser", Tags: []domain.Tag{{Name: "rust"}, {Name: "cli"}}},
+	}
+	for _, r := range seed {
+		r.URL = "https://github.com/" + r.RepoID
+		r.FoundAt = time.Now()
+		if err := repo.Save(ctx, r); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+	}
+	return repo
+}
+
+func searchIDs(t *testing.T, repo *SQLiteRepository, text string) []string {
+	t.Helper()
+	repos, err := repo.SearchRepos(context.Background(), domain.SearchQuery{Text: text, Limit: 10})
+	if err != nil {
+		t.Fatalf("SearchRepos(%q) failed: %v", text, err)
+	}
+	var ids []string
+	for _, r := range repos {
+		ids = append(ids, r.RepoID)
+	}
+	return ids
+}
+
+func TestSQLiteRepository_SearchRepos(t *testing.T) {
+	repo := newSearchTestRepo(t)
+
+	ids := searchIDs(t, repo, "rust cli")
+	if len(ids) != 1 || ids[0] != "clap-rs/clap" {
+		t.Errorf("Expected only clap-rs/clap for 'rust cli', got %v", ids)
+	}
+
+	ids = searchIDs(t, repo, "cobra")
+	if len(ids) != 1 || ids[0] != "spf13/cobra" {
+		t.Errorf("Expected spf13/cobra, got %v", ids)
+	}
+
+	if _, err := repo.SearchRepos(context.Background(), domain.SearchQuery{Text: "  "}); err == nil {
+		t.Error("Expected error for empty query")
+	}
+}
+
+func TestSQLiteRepository_SearchRepos_FTS(t *testing.T) {
+	repo := newSearchTestRepo(t)
+	ctx := context.Background()
+	if !repo.HasFullTextSearch(ctx) {
+		t.Skip("SQLite built without FTS5; run with -tags sqlite_fts5")
+	}
+
+	// Stemming via the porter tokenizer.
+	if ids := searchIDs(t, repo, "parsers"); len(ids) != 1 || ids[0] != "clap-rs/clap" {
+		t.Errorf("Expected stemmed match on clap-rs/clap, got %v", ids)
+	}
+
+	// Punctuation is matched literally rather than parsed as query syntax.
+	if _, err := repo.SearchRepos(ctx, domain.SearchQuery{Text: "tokio-rs c++ \"quoted"}); err != nil {
+		t.Errorf("Expected punctuation to be escaped, got %v", err)
+	}
+
+	// Triggers keep the index in sync with enrichment and tag changes.
+	if err := repo.UpdateRepoEnrichment(ctx, domain.RepoEnrichmentUpdate{
+		RepoID:           "spf13/cobra",
+		Stats:            &domain.RepoStats{Description: "A commander for modern applications", LastPushed: time.Now()},
+		EnrichmentStatus: domain.StatusSuccess,
+	}); err != nil {
+		t.Fatalf("UpdateRepoEnrichment failed: %v", err)
+	}
+	if ids := searchIDs(t, repo, "modern"); len(ids) != 1 || ids[0] != "spf13/cobra" {
+		t.Errorf("Expected description update to be indexed, got %v", ids)
+	}
+
+	if err := repo.Save(ctx, domain.ExtractedRepo{RepoID: "spf13/cobra", URL: "https://github.com/spf13/cobra", Tags: []domain.Tag{{Name: "favourite"}}}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if ids := searchIDs(t, repo, "favourite"); len(ids) != 1 || ids[0] != "spf13/cobra" {
+		t.Errorf("Expected new tag to be indexed, got %v", ids)
+	}
+	if ids := searchIDs(t, repo, "go cli"); len(ids) != 1 || ids[0] != "spf13/cobra" {
+		t.Errorf("Expected merged tags to stay indexed, got %v", ids)
+	}
+}
+
+func TestSQLiteRepository_SearchRepos_Renamed(t *testing.T) {
+	repo := newSearchTestRepo(t)
+	ctx := context.Background()
+	if !repo.HasFullTextSearch(ctx) {
+		t.Skip("SQLite built without FTS5; run with -tags sqlite_fts5")
+	}
+
+	err := repo.UpdateRepoEnrichment(ctx, domain.RepoEnrichmentUpdate{
+		RepoID:           "spf13/cobra",
+		Stats:            &domain.RepoStats{FullName: "cobra-cli/cobra"},
+		EnrichmentStatus: domain.StatusSuccess,
+		CanonicalID:      "cobra-cli/cobra",
+		CanonicalURL:     "https://github.com/cobra-cli/cobra",
+	})
+	if err != nil {
+		t.Fatalf("UpdateRepoEnrichment failed: %v", err)
+	}
+
+	if ids := searchIDs(t, repo, "go cli"); len(ids) != 1 || ids[0] != "cobra-cli/cobra" {
+		t.Errorf("Expected the moved repo's tags to stay indexed, got %v", ids)
+	}
+	if ids := searchIDs(t, repo, "cobra-cli"); len(ids) != 1 || ids[0] != "cobra-cli/cobra" {
+		t.Errorf("Expected the new ID to be indexed, got %v", ids)
+	}
+}
+
+func TestFTSQuery(t *testing.T) {
+	tests := map[string]string{
+		"rust cli": `"rust" "cli"`,
+		"c++":      `"c++"`,
+		`say "hi"`: `"say" """hi"""`,
+		"tok*":     `"tok"*`,
+		"*":        `"*"`,
+	}
+	for in, want := range tests {
+		got := ftsQuery(strings.Fields(in))
+		if got != want {
+			t.Errorf("ftsQuery(%q) = %s, want %s", in, got, want)
+		}
+	}
+}
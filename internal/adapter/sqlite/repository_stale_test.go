@@ -0,0 +1,61 @@
+package sqlite
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/brianluby/karakeep-extractor/internal/core/domain"
+)
+
+func TestSQLiteRepository_GetRankedRepos_Stale(t *testing.T) {
+	db, dbPath := newTestDB(t)
+	defer os.Remove(dbPath)
+	defer db.Close()
+
+	repo := NewSQLiteRepository(db)
+	ctx := context.Background()
+
+	if err := repo.InitSchema(ctx); err != nil {
+		t.Fatalf("InitSchema failed: %v", err)
+	}
+
+	now := time.Now()
+	updates := []domain.RepoEnrichmentUpdate{
+		{RepoID: "owner/active", EnrichmentStatus: domain.StatusSuccess, Stats: &domain.RepoStats{LastPushed: now.AddDate(0, -1, 0)}},
+		{RepoID: "owner/dormant", EnrichmentStatus: domain.StatusSuccess, Stats: &domain.RepoStats{LastPushed: now.AddDate(-2, 0, 0)}},
+		{RepoID: "owner/archived", EnrichmentStatus: domain.StatusSuccess, Stats: &domain.RepoStats{LastPushed: now.AddDate(0, -1, 0), Archived: true}},
+		{RepoID: "owner/gone", EnrichmentStatus: domain.StatusNotFound},
+		{RepoID: "owner/failing", EnrichmentStatus: domain.StatusAPIError},
+	}
+	for _, u := range updates {
+		if err := repo.Save(ctx, domain.ExtractedRepo{RepoID: u.RepoID, URL: "https://github.com/" + u.RepoID, FoundAt: now}); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+		if err := repo.UpdateRepoEnrichment(ctx, u); err != nil {
+			t.Fatalf("UpdateRepoEnrichment failed: %v", err)
+		}
+	}
+
+	repos, err := repo.GetRankedRepos(ctx, domain.RankQuery{SortBy: domain.SortByUpdated, StaleBefore: now.AddDate(0, -12, 0)})
+	if err != nil {
+		t.Fatalf("GetRankedRepos failed: %v", err)
+	}
+
+	got := make(map[string]domain.ExtractedRepo)
+	for _, r := range repos {
+		got[r.RepoID] = r
+	}
+	if len(got) != 3 {
+		t.Fatalf("Expected 3 stale repos, got %v", repos)
+	}
+	for _, id := range []string{"owner/dormant", "owner/archived", "owner/gone"} {
+		if _, ok := got[id]; !ok {
+			t.Errorf("Expected %s in stale repos", id)
+		}
+	}
+	if !got["owner/archived"].RepoArchived {
+		t.Error("Expected owner/archived to have RepoArchived set")
+	}
+}
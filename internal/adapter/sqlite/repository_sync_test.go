@@ -0,0 +1,65 @@
+package sqlite
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/brianluby/karakeep-extractor/internal/core/domain"
+)
+
+func TestSQLiteRepository_SyncState(t *testing.T) {
+	db, dbPath := newTestDB(t)
+	defer os.Remove(dbPath)
+	defer db.Close()
+
+	repo := NewSQLiteRepository(db)
+	ctx := context.Background()
+
+	if err := repo.InitSchema(ctx); err != nil {
+		t.Fatalf("InitSchema failed: %v", err)
+	}
+
+	// Never synced
+	state, err := repo.GetSyncState(ctx, "karakeep")
+	if err != nil {
+		t.Fatalf("GetSyncState failed: %v", err)
+	}
+	if state != nil {
+		t.Fatalf("Expected no sync state, got %+v", state)
+	}
+
+	lastBookmarkAt := time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC)
+	runAt := time.Date(2024, 5, 2, 8, 0, 0, 0, time.UTC)
+	if err := repo.SaveSyncState(ctx, domain.SyncState{
+		Source:         "karakeep",
+		LastBookmarkID: "bm1",
+		LastBookmarkAt: lastBookmarkAt,
+		LastRunAt:      runAt,
+	}); err != nil {
+		t.Fatalf("SaveSyncState failed: %v", err)
+	}
+
+	// Overwrite with a newer run time
+	runAt = runAt.Add(24 * time.Hour)
+	if err := repo.SaveSyncState(ctx, domain.SyncState{
+		Source:         "karakeep",
+		LastBookmarkID: "bm1",
+		LastBookmarkAt: lastBookmarkAt,
+		LastRunAt:      runAt,
+	}); err != nil {
+		t.Fatalf("SaveSyncState (update) failed: %v", err)
+	}
+
+	state, err = repo.GetSyncState(ctx, "karakeep")
+	if err != nil {
+		t.Fatalf("GetSyncState failed: %v", err)
+	}
+	if state == nil {
+		t.Fatal("Expected sync state, got nil")
+	}
+	if state.LastBookmarkID != "bm1" || !state.LastBookmarkAt.Equal(lastBookmarkAt) || !state.LastRunAt.Equal(runAt) {
+		t.Errorf("Unexpected sync state: %+v", state)
+	}
+}
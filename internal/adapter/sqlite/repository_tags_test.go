@@ -0,0 +1,123 @@
+package sqlite
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/brianluby/karakeep-extractor/internal/core/domain"
+)
+
+func TestSQLiteRepository_Tags(t *testing.T) {
+	db, dbPath := newTestDB(t)
+	defer os.Remove(dbPath)
+	defer db.Close()
+
+	repo := NewSQLiteRepository(db)
+	ctx := context.Background()
+
+	if err := repo.InitSchema(ctx); err != nil {
+		t.Fatalf("InitSchema failed: %v", err)
+	}
+
+	now := time.Now()
+	saves := []domain.ExtractedRepo{
+		{RepoID: "owner/one", URL: "url1", FoundAt: now, Tags: []domain.Tag{{Name: "rust", AttachedBy: domain.TagSourceHuman}}},
+		{RepoID: "owner/two", URL: "url2", FoundAt: now, Tags: []domain.Tag{{Name: "rust", AttachedBy: domain.TagSourceAI}}},
+		// A second bookmark of the same repo adds tags rather than replacing them.
+		{RepoID: "owner/one", URL: "url1", FoundAt: now, Tags: []domain.Tag{{Name: "cli", AttachedBy: domain.TagSourceAI}}},
+	}
+	for _, r := range saves {
+		if err := repo.Save(ctx, r); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+		if err := repo.UpdateRepoEnrichment(ctx, domain.RepoEnrichmentUpdate{RepoID: r.RepoID, EnrichmentStatus: domain.StatusSuccess, Stats: &domain.RepoStats{}}); err != nil {
+			t.Fatalf("UpdateRepoEnrichment failed: %v", err)
+		}
+	}
+
+	all, err := repo.GetRankedRepos(ctx, domain.RankQuery{SortBy: domain.SortByStars})
+	if err != nil {
+		t.Fatalf("GetRankedRepos failed: %v", err)
+	}
+	tags := make(map[string][]domain.Tag)
+	for _, r := range all {
+		tags[r.RepoID] = r.Tags
+	}
+	want := []domain.Tag{{Name: "cli", AttachedBy: domain.TagSourceAI}, {Name: "rust", AttachedBy: domain.TagSourceHuman}}
+	if got := tags["owner/one"]; len(got) != 2 || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("Expected hydrated tags %+v, got %+v", want, got)
+	}
+
+	tests := []struct {
+		source domain.TagSource
+		want   string
+	}{
+		{domain.TagSourceHuman, "owner/one"},
+		{domain.TagSourceAI, "owner/two"},
+	}
+	for _, tc := range tests {
+		repos, err := repo.GetRankedRepos(ctx, domain.RankQuery{SortBy: domain.SortByStars, Tag: "rust", TagSource: tc.source})
+		if err != nil {
+			t.Fatalf("GetRankedRepos(%s) failed: %v", tc.source, err)
+		}
+		if len(repos) != 1 || repos[0].RepoID != tc.want {
+			t.Errorf("Expected only %s for %s-attached rust tag, got %+v", tc.want, tc.source, repos)
+		}
+	}
+}
+
+func TestSQLiteRepository_BookmarkTags(t *testing.T) {
+	db, dbPath := newTestDB(t)
+	defer os.Remove(dbPath)
+	defer db.Close()
+
+	repo := NewSQLiteRepository(db)
+	ctx := context.Background()
+	if err := repo.InitSchema(ctx); err != nil {
+		t.Fatalf("InitSchema failed: %v", err)
+	}
+
+	save := func(bookmarkID string, tags ...domain.Tag) {
+		t.Helper()
+		err := repo.Save(ctx, domain.ExtractedRepo{RepoID: "owner/repo", URL: "https://github.com/owner/repo", SourceID: bookmarkID, FoundAt: time.Now(), Tags: tags})
+		if err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+	}
+	repoTags := func() map[string]domain.TagSource {
+		t.Helper()
+		repos, err := repo.GetRankedRepos(ctx, domain.RankQuery{SortBy: domain.SortByStars})
+		if err != nil || len(repos) != 1 {
+			t.Fatalf("GetRankedRepos failed: %v (%d repos)", err, len(repos))
+		}
+		tags := make(map[string]domain.TagSource)
+		for _, tag := range repos[0].Tags {
+			tags[tag.Name] = tag.AttachedBy
+		}
+		return tags
+	}
+
+	save("bm-1", domain.Tag{Name: "rust", AttachedBy: domain.TagSourceAI}, domain.Tag{Name: "cli", AttachedBy: domain.TagSourceHuman})
+	save("bm-2", domain.Tag{Name: "cli", AttachedBy: domain.TagSourceAI}, domain.Tag{Name: "tui", AttachedBy: domain.TagSourceAI})
+	if err := repo.UpdateRepoEnrichment(ctx, domain.RepoEnrichmentUpdate{RepoID: "owner/repo", EnrichmentStatus: domain.StatusSuccess, Stats: &domain.RepoStats{}}); err != nil {
+		t.Fatalf("UpdateRepoEnrichment failed: %v", err)
+	}
+	if tags := repoTags(); len(tags) != 3 || tags["cli"] != domain.TagSourceHuman {
+		t.Fatalf("Expected the tags of both bookmarks, human cli, got %v", tags)
+	}
+
+	// Re-saving a bookmark replaces its tags: rust was removed, and cli is re-attributed.
+	save("bm-1", domain.Tag{Name: "cli", AttachedBy: domain.TagSourceAI})
+	tags := repoTags()
+	if _, ok := tags["rust"]; ok || len(tags) != 2 || tags["cli"] != domain.TagSourceAI || tags["tui"] != domain.TagSourceAI {
+		t.Errorf("Expected rust removed and cli re-attributed to AI, got %v", tags)
+	}
+
+	// Tags only go once no bookmark of the repo has them.
+	save("bm-2")
+	if tags := repoTags(); len(tags) != 1 || tags["cli"] != domain.TagSourceAI {
+		t.Errorf("Expected only bm-1's cli tag, got %v", tags)
+	}
+}
@@ -0,0 +1,117 @@
+package sqlite
+
+import (
+	"context"
+	"os"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/brianluby/karakeep-extractor/internal/core/domain"
+)
+
+func TestSQLiteRepository_GetRankedRepos_Where(t *testing.T) {
+	db, dbPath := newTestDB(t)
+	defer os.Remove(dbPath)
+	defer db.Close()
+
+	repo := NewSQLiteRepository(db)
+	ctx := context.Background()
+
+	if err := repo.InitSchema(ctx); err != nil {
+		t.Fatalf("InitSchema failed: %v", err)
+	}
+
+	seed := []struct {
+		id    string
+		title string
+		tags  []domain.Tag
+		stats domain.RepoStats
+	}{
+		{"owner/go-cli", "A 100% Go CLI", []domain.Tag{{Name: "CLI"}},
+			domain.RepoStats{Stars: 5000, Forks: 100, Language: "Go", LastPushed: time.Date(2024, 3, 1, 15, 0, 0, 0, time.UTC), Topics: []string{"terminal"}, License: "MIT"}},
+		{"owner/old-go", "Old Go library", []domain.Tag{{Name: "archived"}},
+			domain.RepoStats{Stars: 2000, Language: "go", LastPushed: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC), Archived: true, Fork: true}},
+		{"owner/rusty", "Rust parser", nil,
+			domain.RepoStats{Stars: 50, Language: "Rust", LastPushed: time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)}},
+		// No language, to check that negated terms keep NULLs.
+		{"owner/docs", "Docs", nil,
+			domain.RepoStats{Stars: 10, LastPushed: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)}},
+	}
+	for _, s := range seed {
+		if err := repo.Save(ctx, domain.ExtractedRepo{RepoID: s.id, URL: "https://github.com/" + s.id, Title: s.title, FoundAt: time.Now(), Tags: s.tags}); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+		stats := s.stats
+		if err := repo.UpdateRepoEnrichment(ctx, domain.RepoEnrichmentUpdate{RepoID: s.id, Stats: &stats, EnrichmentStatus: domain.StatusSuccess}); err != nil {
+			t.Fatalf("UpdateRepoEnrichment failed: %v", err)
+		}
+	}
+
+	num := func(n int) *int { return &n }
+	day := func(s string) time.Time {
+		d, _ := time.Parse("2006-01-02", s)
+		return d
+	}
+
+	tests := []struct {
+		name  string
+		where []domain.FilterTerm
+		want  []string
+	}{
+		{"language is case-insensitive", []domain.FilterTerm{{Field: domain.FilterLang, Op: domain.OpEq, Value: "GO"}},
+			[]string{"owner/go-cli", "owner/old-go"}},
+		{"stars comparison", []domain.FilterTerm{{Field: domain.FilterStars, Op: domain.OpGt, Number: num(1000)}},
+			[]string{"owner/go-cli", "owner/old-go"}},
+		{"stars range", []domain.FilterTerm{{Field: domain.FilterStars, Op: domain.OpRange, Number: num(10), Upper: num(2000)}},
+			[]string{"owner/docs", "owner/old-go", "owner/rusty"}},
+		{"pushed after a day excludes that day", []domain.FilterTerm{{Field: domain.FilterPushed, Op: domain.OpGt, Date: day("2024-01-01")}},
+			[]string{"owner/go-cli"}},
+		{"pushed on a day", []domain.FilterTerm{{Field: domain.FilterPushed, Op: domain.OpEq, Date: day("2024-03-01")}},
+			[]string{"owner/go-cli"}},
+		{"pushed up to a day includes it", []domain.FilterTerm{{Field: domain.FilterPushed, Op: domain.OpLte, Date: day("2024-01-01")}},
+			[]string{"owner/docs", "owner/old-go", "owner/rusty"}},
+		{"tag and negated tag", []domain.FilterTerm{
+			{Field: domain.FilterLang, Op: domain.OpEq, Value: "go"},
+			{Field: domain.FilterTag, Op: domain.OpEq, Negate: true, Value: "archived"},
+		}, []string{"owner/go-cli"}},
+		{"tag is case-insensitive", []domain.FilterTerm{{Field: domain.FilterTag, Op: domain.OpEq, Value: "cli"}},
+			[]string{"owner/go-cli"}},
+		{"negation keeps repos with no value", []domain.FilterTerm{{Field: domain.FilterLang, Op: domain.OpEq, Negate: true, Value: "go"}},
+			[]string{"owner/docs", "owner/rusty"}},
+		{"topic and license", []domain.FilterTerm{
+			{Field: domain.FilterTopic, Op: domain.OpEq, Value: "Terminal"},
+			{Field: domain.FilterLicense, Op: domain.OpEq, Value: "mit"},
+		}, []string{"owner/go-cli"}},
+		{"is flags", []domain.FilterTerm{{Field: domain.FilterIs, Op: domain.OpEq, Value: domain.FilterIsFork}},
+			[]string{"owner/old-go"}},
+		{"text matches literally", []domain.FilterTerm{{Field: domain.FilterText, Op: domain.OpEq, Value: "100%"}},
+			[]string{"owner/go-cli"}},
+		{"text wildcards are escaped", []domain.FilterTerm{{Field: domain.FilterText, Op: domain.OpEq, Value: "_"}},
+			nil},
+		{"value is a parameter, not SQL", []domain.FilterTerm{{Field: domain.FilterLang, Op: domain.OpEq, Value: "go' OR '1'='1"}},
+			nil},
+	}
+
+	for _, tt := range tests {
+		repos, err := repo.GetRankedRepos(ctx, domain.RankQuery{SortBy: domain.SortByStars, Where: tt.where})
+		if err != nil {
+			t.Fatalf("%s: GetRankedRepos failed: %v", tt.name, err)
+		}
+		var got []string
+		for _, r := range repos {
+			got = append(got, r.RepoID)
+		}
+		sort.Strings(got)
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,232 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/brianluby/karakeep-extractor/internal/core/domain"
+)
+
+// The full-text index lives outside the versioned migrations: FTS5 is only compiled into
+// go-sqlite3 with the sqlite_fts5 build tag, and a migration recorded as applied by a build
+// without it would never be retried. ensureSearchIndex runs on every InitSchema instead.
+//
+// searchIndexVersion is part of the trigger names; bump it when the table or triggers
+// change and the index is rebuilt from scratch on the next start.
+const searchIndexVersion = 2
+
+var searchTriggerPrefix = fmt.Sprintf("trg_repo_search_v%d_", searchIndexVersion)
+
+// tagsForRepoSQL concatenates a repo's tag names; %s is the repo_id expression.
+const tagsForRepoSQL = `(SELECT group_concat(t.name, ' ') FROM repo_tags rt JOIN tags t ON t.id = rt.tag_id WHERE rt.repo_id = %s)`
+
+// HasFullTextSearch reports whether the linked SQLite has FTS5 compiled in.
+func (r *SQLiteRepository) HasFullTextSearch(ctx context.Context) bool {
+	var enabled bool
+	if err := r.db.QueryRowContext(ctx, `SELECT sqlite_compileoption_used('ENABLE_FTS5');`).Scan(&enabled); err != nil {
+		return false
+	}
+	return enabled
+}
+
+// ensureSearchIndex creates (or rebuilds) the repo_search FTS5 table and the triggers that keep it
+// in sync. Without FTS5 it drops the triggers, which would otherwise fail on every write.
+func (r *SQLiteRepository) ensureSearchIndex(ctx context.Context) error {
+	if !r.HasFullTextSearch(ctx) {
+		return r.dropSearchTriggers(ctx)
+	}
+
+	var current int
+	err := r.db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM sqlite_master WHERE type = 'trigger' AND name LIKE ? || '%';`, searchTriggerPrefix,
+	).Scan(&current)
+	if err != nil {
+		return fmt.Errorf("failed to inspect search triggers: %w", err)
+	}
+	if current > 0 {
+		return nil
+	}
+
+	// First start with this index version (or FTS5 was missing before): rebuild everything.
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := dropSearchTriggersTx(ctx, tx); err != nil {
+		return err
+	}
+
+	tagsNew := fmt.Sprintf(tagsForRepoSQL, "NEW.repo_id")
+	tagsOld := fmt.Sprintf(tagsForRepoSQL, "OLD.repo_id")
+	// The FTS rowid mirrors the extracted_repos rowid so triggers can update a single row.
+	statements := []string{
+		`DROP TABLE IF EXISTS repo_search;`,
+		`CREATE VIRTUAL TABLE repo_search USING fts5(repo_id, title, description, tags, readme, tokenize = 'porter unicode61');`,
+		`CREATE TRIGGER ` + searchTriggerPrefix + `repo_insert AFTER INSERT ON extracted_repos BEGIN
+			INSERT INTO repo_search (rowid, repo_id, title, description, tags, readme)
+			VALUES (NEW.rowid, NEW.repo_id, NEW.title, NEW.description, ` + tagsNew + `, NEW.readme_text);
+		END;`,
+		`CREATE TRIGGER ` + searchTriggerPrefix + `repo_update AFTER UPDATE OF repo_id, title, description, readme_text ON extracted_repos BEGIN
+			DELETE FROM repo_search WHERE rowid = OLD.rowid;
+			INSERT INTO repo_search (rowid, repo_id, title, description, tags, readme)
+			VALUES (NEW.rowid, NEW.repo_id, NEW.title, NEW.description, ` + tagsNew + `, NEW.readme_text);
+		END;`,
+		`CREATE TRIGGER ` + searchTriggerPrefix + `repo_delete AFTER DELETE ON extracted_repos BEGIN
+			DELETE FROM repo_search WHERE rowid = OLD.rowid;
+		END;`,
+		`CREATE TRIGGER ` + searchTriggerPrefix + `tag_insert AFTER INSERT ON repo_tags BEGIN
+			UPDATE repo_search SET tags = ` + tagsNew + `
+			WHERE rowid = (SELECT rowid FROM extracted_repos WHERE repo_id = NEW.repo_id);
+		END;`,
+		`CREATE TRIGGER ` + searchTriggerPrefix + `tag_delete AFTER DELETE ON repo_tags BEGIN
+			UPDATE repo_search SET tags = ` + tagsOld + `
+			WHERE rowid = (SELECT rowid FROM extracted_repos WHERE repo_id = OLD.repo_id);
+		END;`,
+		`INSERT INTO repo_search (rowid, repo_id, title, description, tags, readme)
+			SELECT er.rowid, er.repo_id, er.title, er.description, ` + fmt.Sprintf(tagsForRepoSQL, "er.repo_id") + `, er.readme_text
+			FROM extracted_repos er;`,
+	}
+	if err := execAll(ctx, tx, statements...); err != nil {
+		return fmt.Errorf("failed to build search index: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+func (r *SQLiteRepository) dropSearchTriggers(ctx context.Context) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+	if err := dropSearchTriggersTx(ctx, tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// dropSearchTriggersTx removes the search triggers of every index version.
+func dropSearchTriggersTx(ctx context.Context, tx *sql.Tx) error {
+	rows, err := tx.QueryContext(ctx, `SELECT name FROM sqlite_master WHERE type = 'trigger' AND name LIKE 'trg_repo_search_%';`)
+	if err != nil {
+		return fmt.Errorf("failed to list search triggers: %w", err)
+	}
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan trigger name: %w", err)
+		}
+		names = append(names, name)
+	}
+	rows.Close()
+
+	for _, name := range names {
+		if _, err := tx.ExecContext(ctx, `DROP TRIGGER IF EXISTS `+name+`;`); err != nil {
+			return fmt.Errorf("failed to drop trigger %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// SearchRepos runs a full-text search over repo ID, title, description, tags and README text,
+// ranked by BM25. Without FTS5 it falls back to substring matching ordered by stars.
+func (r *SQLiteRepository) SearchRepos(ctx context.Context, query domain.SearchQuery) ([]domain.ExtractedRepo, error) {
+	terms := strings.Fields(query.Text)
+	if len(terms) == 0 {
+		return nil, fmt.Errorf("empty search query")
+	}
+
+	fts := r.HasFullTextSearch(ctx)
+
+	var sqlQuery string
+	var args []interface{}
+	if fts {
+		sqlQuery = `
+		SELECT ` + repoColumns + `
+		FROM repo_search
+		JOIN extracted_repos er ON er.rowid = repo_search.rowid
+		WHERE repo_search MATCH ?`
+		args = append(args, ftsQuery(terms))
+	} else {
+		sqlQuery = `
+		SELECT ` + repoColumns + `
+		FROM extracted_repos er
+		WHERE 1 = 1`
+		for _, term := range terms {
+			sqlQuery += ` AND (er.repo_id LIKE ? OR er.title LIKE ? OR er.description LIKE ? OR er.readme_text LIKE ? OR EXISTS (
+				SELECT 1 FROM repo_tags rt JOIN tags t ON t.id = rt.tag_id
+				WHERE rt.repo_id = er.repo_id AND t.name LIKE ?
+			))`
+			like := "%" + term + "%"
+			args = append(args, like, like, like, like, like)
+		}
+	}
+
+	if !query.IncludeOrphaned {
+		sqlQuery += ` AND er.orphaned = 0`
+	}
+	if !query.IncludeArchived {
+		sqlQuery += ` AND er.bookmark_archived = 0`
+	}
+
+	if fts {
+		// Column weights: repo_id, title, description, tags, readme.
+		sqlQuery += ` ORDER BY bm25(repo_search, 4.0, 3.0, 2.0, 3.0, 1.0)`
+	} else {
+		sqlQuery += ` ORDER BY er.stars IS NULL, er.stars DESC`
+	}
+
+	limit := query.Limit
+	if limit <= 0 {
+		limit = -1
+	}
+	sqlQuery += ` LIMIT ?;`
+	args = append(args, limit)
+
+	rows, err := r.db.QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search repos: %w", err)
+	}
+	defer rows.Close()
+
+	var repos []domain.ExtractedRepo
+	for rows.Next() {
+		repo, err := scanRepo(rows)
+		if err != nil {
+			return nil, err
+		}
+		repos = append(repos, repo)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	rows.Close()
+
+	if err := r.hydrateTags(ctx, repos); err != nil {
+		return nil, err
+	}
+	return repos, nil
+}
+
+// ftsQuery quotes each term so punctuation in user input (e.g. "foo-bar", "c++") is matched
+// literally instead of being parsed as FTS5 syntax. A trailing '*' keeps prefix matching.
+func ftsQuery(terms []string) string {
+	quoted := make([]string, len(terms))
+	for i, term := range terms {
+		prefix := strings.HasSuffix(term, "*") && len(term) > 1
+		if prefix {
+			term = strings.TrimSuffix(term, "*")
+		}
+		quoted[i] = `"` + strings.ReplaceAll(term, `"`, `""`) + `"`
+		if prefix {
+			quoted[i] += "*"
+		}
+	}
+	return strings.Join(quoted, " ")
+}
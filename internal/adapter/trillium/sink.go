@@ -12,10 +12,17 @@ import (
 // TrilliumSink sends repositories to Trillium as a note.
 type TrilliumSink struct {
 	client *TrilliumClient
+	title  string
 }
 
 func NewSink(client *TrilliumClient) *TrilliumSink {
-	return &TrilliumSink{client: client}
+	return &TrilliumSink{client: client, title: "GitHub Rankings"}
+}
+
+// WithTitle sets the note title; the current time is appended to it.
+func (s *TrilliumSink) WithTitle(title string) *TrilliumSink {
+	s.title = title
+	return s
 }
 
 func (s *TrilliumSink) Send(ctx context.Context, repos []domain.ExtractedRepo) error {
@@ -24,7 +31,7 @@ func (s *TrilliumSink) Send(ctx context.Context, repos []domain.ExtractedRepo) e
 	content := formatter.FormatTable(repos)
 
 	// 2. Create Note
-	title := fmt.Sprintf("GitHub Rankings - %s", time.Now().Format("2006-01-02 15:04:05"))
+	title := fmt.Sprintf("%s - %s", s.title, time.Now().Format("2006-01-02 15:04:05"))
 	
 	return s.client.CreateNote(ctx, title, content)
 }
@@ -15,6 +15,12 @@ type Config struct {
 	TrilliumURL   string           `yaml:"trillium_url,omitempty"`
 	TrilliumToken string           `yaml:"trillium_token,omitempty"`
 	LLM           domain.LLMConfig `yaml:"llm,omitempty"`
+	// Forges lists extra or self-hosted forge hosts (and API tokens) beyond the built-in defaults.
+	Forges []domain.ForgeConfig `yaml:"forges,omitempty"`
+	// Score tunes the weights of 'rank --sort score'.
+	Score domain.ScoreConfig `yaml:"score,omitempty"`
+	// Views are saved rank queries, keyed by name (see 'karakeep-extractor views').
+	Views map[string]domain.View `yaml:"views,omitempty"`
 }
 
 func Load() *Config {
@@ -79,6 +79,20 @@ func (l *ConfigLoader) LoadConfig(flagConfig *Config) (*Config, error) {
 			if fileConfig.LLM.MaxTokens != 0 {
 				finalConfig.LLM.MaxTokens = fileConfig.LLM.MaxTokens
 			}
+			if len(fileConfig.LLM.Options) > 0 {
+				finalConfig.LLM.Options = fileConfig.LLM.Options
+			}
+			if fileConfig.LLM.IdleTimeout != 0 {
+				finalConfig.LLM.IdleTimeout = fileConfig.LLM.IdleTimeout
+			}
+			if fileConfig.LLM.ContextTokens != 0 {
+				finalConfig.LLM.ContextTokens = fileConfig.LLM.ContextTokens
+			}
+			if len(fileConfig.Forges) > 0 {
+				finalConfig.Forges = fileConfig.Forges
+			}
+			finalConfig.Score = fileConfig.Score
+			finalConfig.Views = fileConfig.Views
 		}
 	}
 
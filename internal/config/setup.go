@@ -0,0 +1,18 @@
+package config
+
+// SaveSetup writes the settings prompted for by `setup` into the config file.
+// Everything else in the file (views, score weights, forges, the llm block) is
+// kept as it is.
+func (l *ConfigLoader) SaveSetup(settings *Config) error {
+	cfg, err := l.readFileConfig()
+	if err != nil {
+		return err
+	}
+	cfg.KarakeepURL = settings.KarakeepURL
+	cfg.KarakeepToken = settings.KarakeepToken
+	cfg.GitHubToken = settings.GitHubToken
+	cfg.DBPath = settings.DBPath
+	cfg.TrilliumURL = settings.TrilliumURL
+	cfg.TrilliumToken = settings.TrilliumToken
+	return l.SaveConfig(cfg)
+}
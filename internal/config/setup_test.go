@@ -0,0 +1,63 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestConfigLoader_SaveSetup(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	configPath, err := GetConfigPath()
+	if err != nil {
+		t.Fatalf("GetConfigPath failed: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(configPath), 0700); err != nil {
+		t.Fatal(err)
+	}
+	existing := `karakeep_url: http://old.local
+karakeep_token: old-token
+llm:
+  provider: ollama
+  model: llama3
+forges:
+  - host: git.example.com
+    type: gitea
+    token: forge-token
+score:
+  tags: [cli]
+views:
+  go-cli:
+    where: "lang:go tag:cli"
+    sort: score
+`
+	if err := os.WriteFile(configPath, []byte(existing), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	loader := NewConfigLoader()
+	if err := loader.SaveSetup(&Config{KarakeepURL: "http://new.local", KarakeepToken: "new-token", DBPath: "./new.db"}); err != nil {
+		t.Fatalf("SaveSetup failed: %v", err)
+	}
+
+	cfg, err := loader.LoadConfig(nil)
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	if cfg.KarakeepURL != "http://new.local" || cfg.KarakeepToken != "new-token" || cfg.DBPath != "./new.db" {
+		t.Errorf("prompted settings not saved: %+v", cfg)
+	}
+	if view, ok := cfg.Views["go-cli"]; !ok || view.Where != "lang:go tag:cli" || view.Sort != "score" {
+		t.Errorf("view lost: %+v", cfg.Views)
+	}
+	if cfg.LLM.Provider != "ollama" || cfg.LLM.Model != "llama3" {
+		t.Errorf("llm block lost: %+v", cfg.LLM)
+	}
+	if len(cfg.Forges) != 1 || cfg.Forges[0].Token != "forge-token" {
+		t.Errorf("forges lost: %+v", cfg.Forges)
+	}
+	if len(cfg.Score.Tags) != 1 || cfg.Score.Tags[0] != "cli" {
+		t.Errorf("score config lost: %+v", cfg.Score)
+	}
+}
@@ -0,0 +1,79 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+
+	"github.com/brianluby/karakeep-extractor/internal/core/domain"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	ErrViewExists   = errors.New("view already exists")
+	ErrViewNotFound = errors.New("view not found")
+)
+
+var viewNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+// ValidateViewName checks that name can be typed after --view without quoting.
+func ValidateViewName(name string) error {
+	if !viewNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid view name %q (use letters, digits, '-', '_' and '.')", name)
+	}
+	return nil
+}
+
+// SaveView stores a view in the config file, replacing one of the same name only if replace is set.
+func (l *ConfigLoader) SaveView(name string, view domain.View, replace bool) error {
+	if err := ValidateViewName(name); err != nil {
+		return err
+	}
+	cfg, err := l.readFileConfig()
+	if err != nil {
+		return err
+	}
+	if _, ok := cfg.Views[name]; ok && !replace {
+		return fmt.Errorf("%w: %s", ErrViewExists, name)
+	}
+	if cfg.Views == nil {
+		cfg.Views = make(map[string]domain.View)
+	}
+	cfg.Views[name] = view
+	return l.SaveConfig(cfg)
+}
+
+// DeleteView removes a view from the config file.
+func (l *ConfigLoader) DeleteView(name string) error {
+	cfg, err := l.readFileConfig()
+	if err != nil {
+		return err
+	}
+	if _, ok := cfg.Views[name]; !ok {
+		return fmt.Errorf("%w: %s", ErrViewNotFound, name)
+	}
+	delete(cfg.Views, name)
+	return l.SaveConfig(cfg)
+}
+
+// readFileConfig reads the config file alone, without env overrides, so that
+// writing it back doesn't persist them. A missing file yields an empty config.
+func (l *ConfigLoader) readFileConfig() (*Config, error) {
+	configPath, err := GetConfigPath()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user config dir: %w", err)
+	}
+	cfg := &Config{}
+	data, err := os.ReadFile(configPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	}
+	return cfg, nil
+}
@@ -0,0 +1,78 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/brianluby/karakeep-extractor/internal/core/domain"
+)
+
+func TestConfigLoader_Views(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("GITHUB_TOKEN", "env-token")
+
+	configPath, err := GetConfigPath()
+	if err != nil {
+		t.Fatalf("GetConfigPath failed: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(configPath), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(configPath, []byte("karakeep_url: http://karakeep.local\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	loader := NewConfigLoader()
+	view := domain.View{
+		Where: "lang:go tag:cli stars:>500",
+		Sort:  "score",
+		Limit: 30,
+		Sink:  &domain.ViewSink{URL: "http://example.com/hook", Headers: []string{"X-Token: abc"}},
+	}
+	if err := loader.SaveView("go-cli", view, false); err != nil {
+		t.Fatalf("SaveView failed: %v", err)
+	}
+	if err := loader.SaveView("go-cli", domain.View{Sort: "forks"}, false); !errors.Is(err, ErrViewExists) {
+		t.Errorf("expected ErrViewExists, got %v", err)
+	}
+	if err := loader.SaveView("rust db", domain.View{}, false); err == nil {
+		t.Error("expected an error for a view name with a space")
+	}
+
+	cfg, err := loader.LoadConfig(nil)
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	got, ok := cfg.Views["go-cli"]
+	if !ok {
+		t.Fatalf("view not loaded: %+v", cfg.Views)
+	}
+	if got.Where != view.Where || got.Sort != "score" || got.Limit != 30 || got.Sink == nil || got.Sink.Headers[0] != "X-Token: abc" {
+		t.Errorf("unexpected view: %+v", got)
+	}
+	if cfg.KarakeepURL != "http://karakeep.local" {
+		t.Errorf("existing settings lost: %+v", cfg)
+	}
+
+	// Env overrides must not be written to the file.
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "env-token") {
+		t.Errorf("config file picked up an env override:\n%s", data)
+	}
+
+	if err := loader.SaveView("go-cli", domain.View{Sort: "forks"}, true); err != nil {
+		t.Fatalf("SaveView with replace failed: %v", err)
+	}
+	if err := loader.DeleteView("go-cli"); err != nil {
+		t.Fatalf("DeleteView failed: %v", err)
+	}
+	if err := loader.DeleteView("go-cli"); !errors.Is(err, ErrViewNotFound) {
+		t.Errorf("expected ErrViewNotFound, got %v", err)
+	}
+}
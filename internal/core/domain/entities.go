@@ -1,6 +1,9 @@
 package domain
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 // KarakeepConfig Configuration for connecting to the source.
 type KarakeepConfig struct {
@@ -18,7 +21,41 @@ type RawBookmark struct {
 		Description string `json:"description"`
 		HTMLContent string `json:"htmlContent"`
 	} `json:"content"`
-	Tags []string `json:"tags"`
+	Tags       []Tag      `json:"tags"`
+	Archived   bool       `json:"archived"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	ModifiedAt *time.Time `json:"modifiedAt"` // Null until the bookmark is edited
+}
+
+// LinkKind records where in a bookmark a repository link was found.
+type LinkKind string
+
+const (
+	LinkPrimary LinkKind = "primary" // The bookmark's own URL.
+	LinkContent LinkKind = "content" // A link inside the bookmarked page's HTML.
+)
+
+// TagSource records who attached a tag to a bookmark in Karakeep.
+type TagSource string
+
+const (
+	TagSourceAI    TagSource = "ai"
+	TagSourceHuman TagSource = "human"
+)
+
+// Tag is a Karakeep bookmark tag with its attribution.
+type Tag struct {
+	Name       string    `json:"name"`
+	AttachedBy TagSource `json:"attachedBy"`
+}
+
+// TagNames returns just the names of the tags.
+func TagNames(tags []Tag) []string {
+	names := make([]string, len(tags))
+	for i, t := range tags {
+		names[i] = t.Name
+	}
+	return names
 }
 
 type EnrichmentStatus string
@@ -32,21 +69,45 @@ const (
 
 // RepoStats represents the metadata fetched from GitHub
 type RepoStats struct {
+	FullName    string // Current "owner/name" on the forge; differs from the repo ID after a rename or transfer.
 	Stars       int
 	Forks       int
 	LastPushed  time.Time
 	Description string
 	Language    string
+	Archived    bool // Archived (read-only) on the forge.
+
+	Topics        []string
+	License       string // SPDX identifier, e.g. "MIT".
+	Fork          bool
+	Parent        string // "owner/name" of the upstream repo when Fork is set.
+	OpenIssues    int // The REST API counts open pull requests too.
+	Homepage      string
+	CreatedAt     time.Time
+	DefaultBranch string
+	SizeKB        int
+
+	// HTTP cache validators returned with the stats, sent back on the next conditional request.
+	ETag         string
+	LastModified string
+}
+
+// Readme is a repository README as returned by the forge.
+type Readme struct {
+	SHA     string // Git blob SHA; changes whenever the README does.
+	Content string // Raw file content, usually Markdown.
 }
 
-// ExtractedRepo The refined domain entity representing a GitHub repository found in bookmarks.
+// ExtractedRepo The refined domain entity representing a repository found in bookmarks.
 type ExtractedRepo struct {
-	RepoID   string    // Canonical "owner/name" (Primary Key in DB).
+	RepoID   string    // Canonical "owner/name", or "host:owner/name" off GitHub (Primary Key in DB).
+	Forge    Forge     // Hosting platform; empty means GitHub.
 	URL      string    // Normalized HTTPS URL.
-	SourceID string    // ID of the original Karakeep bookmark.
+	SourceID string    // ID of the first Karakeep bookmark; Save also links any later one passed here.
+	LinkKind LinkKind  // How the SourceID bookmark references the repo; empty means primary.
 	Title    string    // Title from the bookmark.
 	FoundAt  time.Time // Timestamp of extraction.
-	Tags     []string  // Tags from Karakeep.
+	Tags     []Tag     // Tags from Karakeep, with who attached them.
 
 	// Enrichment Data
 	Stars            *int             // Nullable
@@ -54,5 +115,98 @@ type ExtractedRepo struct {
 	LastPushedAt     *time.Time       // Nullable
 	Description      *string          // Nullable
 	Language         *string          // Nullable
+	RepoArchived     bool             // Archived on the forge (not to be confused with BookmarkArchived).
+	Topics           []string
+	License          string     // SPDX identifier; empty if unknown or unlicensed.
+	IsFork           bool
+	Parent           string     // Upstream "owner/name" of a fork.
+	OpenIssues       *int       // Nullable
+	Homepage         string
+	CreatedAt        *time.Time // Nullable; when the repo was created on the forge.
+	DefaultBranch    string
+	SizeKB           *int       // Nullable
+	// Kept out of JSON exports: a hydrated README would add its full text to every repo.
+	ReadmeSHA        string     `json:"-"` // Git blob SHA of the stored README; empty if not fetched.
+	Readme           string     `json:"-"` // Plain-text README; only loaded on request (ReadmeRepository.HydrateReadmes).
+	LatestRelease    *Release   // Nullable; set once releases have been fetched (enrich --releases).
 	EnrichmentStatus EnrichmentStatus
-}
\ No newline at end of file
+	// Bookkeeping for conditional requests; left out of JSON exports.
+	ETag             string     `json:"-"` // Cache validator from the last successful fetch.
+	LastModified     string     `json:"-"` // Cache validator from the last successful fetch.
+	LastCheckedAt    *time.Time `json:"-"` // When the forge was last asked about this repo.
+	StarsDelta       *int       // Stars gained over the ranking window; only set for stars-delta ranking.
+	Score            *ScoreBreakdown // Interest score; only set for score ranking.
+
+	// Bookmark State
+	BookmarkCount    int  // Number of bookmarks that reference the repo.
+	Orphaned         bool // Source bookmark no longer exists in Karakeep.
+	BookmarkArchived bool // Source bookmark is archived in Karakeep.
+}
+
+// extractedRepoJSON is the JSON form of ExtractedRepo. Tags stay a list of names, as they were
+// before tags carried attribution; who attached each one is reported separately.
+type extractedRepoJSON struct {
+	extractedRepoFields
+	Tags       []string             `json:"Tags"`
+	TagSources map[string]TagSource `json:"tag_sources,omitempty"`
+}
+
+// extractedRepoFields has ExtractedRepo's fields without its JSON methods.
+type extractedRepoFields ExtractedRepo
+
+func (r ExtractedRepo) MarshalJSON() ([]byte, error) {
+	out := extractedRepoJSON{extractedRepoFields: extractedRepoFields(r)}
+	if r.Tags != nil {
+		out.Tags = TagNames(r.Tags)
+	}
+	if len(r.Tags) > 0 {
+		out.TagSources = make(map[string]TagSource, len(r.Tags))
+		for _, tag := range r.Tags {
+			out.TagSources[tag.Name] = tag.AttachedBy
+		}
+	}
+	return json.Marshal(out)
+}
+
+func (r *ExtractedRepo) UnmarshalJSON(data []byte) error {
+	var in extractedRepoJSON
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	*r = ExtractedRepo(in.extractedRepoFields)
+	r.Tags = nil
+	for _, name := range in.Tags {
+		r.Tags = append(r.Tags, Tag{Name: name, AttachedBy: in.TagSources[name]})
+	}
+	return nil
+}
+
+// Release is the newest release of a repository, or its newest tag when it publishes no releases.
+type Release struct {
+	Tag         string
+	PublishedAt time.Time // Release publish date, or the tagged commit's date for tags.
+	Prerelease  bool
+	FromTag     bool // No GitHub release exists; taken from the repository's tags.
+}
+
+// BookmarkState is the minimal per-bookmark information needed to reconcile the local database.
+type BookmarkState struct {
+	ID       string
+	Archived bool
+}
+
+// ReconcileResult summarises a reconciliation pass.
+type ReconcileResult struct {
+	Orphaned int // Repos newly marked orphaned.
+	Restored int // Previously orphaned repos whose bookmark reappeared.
+	Archived int // Repos whose bookmark is currently archived.
+}
+
+// SyncState records how far a bookmark source has been synchronised, so that
+// subsequent extractions only need to fetch new or changed bookmarks.
+type SyncState struct {
+	Source         string    // Name of the bookmark source (e.g. "karakeep").
+	LastBookmarkID string    // ID of the newest bookmark seen.
+	LastBookmarkAt time.Time // Created/modified timestamp of the newest bookmark seen.
+	LastRunAt      time.Time // Time of the last successful run.
+}
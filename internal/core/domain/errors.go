@@ -1,8 +1,37 @@
 package domain
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"time"
+)
 
 var (
 	ErrRateLimitExceeded = errors.New("rate limit exceeded")
 	ErrRepoNotFound      = errors.New("repository not found")
+	ErrNotModified       = errors.New("repository not modified")
+	ErrNoReadme          = errors.New("repository has no README")
+	ErrNoRelease         = errors.New("repository has no releases or tags")
 )
+
+// RateLimitError is a rate limit response that says when requests may resume.
+// It matches ErrRateLimitExceeded with errors.Is.
+type RateLimitError struct {
+	ResetAt   time.Time // Zero if the API didn't say.
+	Secondary bool      // GitHub secondary (abuse) limit rather than the hourly quota.
+}
+
+func (e *RateLimitError) Error() string {
+	kind := "rate limit exceeded"
+	if e.Secondary {
+		kind = "secondary rate limit exceeded"
+	}
+	if e.ResetAt.IsZero() {
+		return kind
+	}
+	return fmt.Sprintf("%s (resets at %s)", kind, e.ResetAt.Format(time.RFC3339))
+}
+
+func (e *RateLimitError) Unwrap() error {
+	return ErrRateLimitExceeded
+}
@@ -6,3 +6,35 @@ import "io"
 type Exporter interface {
 	Export(repos []ExtractedRepo, w io.Writer) error
 }
+
+// PagedExporter is an Exporter that can also describe where a page sits in the full ranking.
+type PagedExporter interface {
+	Exporter
+	ExportPage(page Page, w io.Writer) error
+}
+
+// Page is one slice of a ranking.
+type Page struct {
+	Repos  []ExtractedRepo
+	Total  int // Matching repos across all pages.
+	Offset int // Rank of the first repo, minus one.
+	Limit  int // Page size; <= 0 means the rest of the ranking.
+}
+
+// Number is the 1-based page number, or 0 when there is no page size or the
+// offset does not fall on a page boundary.
+func (p Page) Number() int {
+	if p.Limit <= 0 || p.Offset%p.Limit != 0 {
+		return 0
+	}
+	return p.Offset/p.Limit + 1
+}
+
+// NextOffset is the offset of the following page, or -1 on the last page.
+func (p Page) NextOffset() int {
+	next := p.Offset + len(p.Repos)
+	if p.Limit <= 0 || next >= p.Total {
+		return -1
+	}
+	return next
+}
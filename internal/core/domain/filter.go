@@ -0,0 +1,58 @@
+package domain
+
+import "time"
+
+// FilterField is a repository attribute the --where language can test.
+type FilterField string
+
+const (
+	FilterText      FilterField = "text" // Bare word: the name, bookmark title or description contains it.
+	FilterLang      FilterField = "lang"
+	FilterStars     FilterField = "stars"
+	FilterForks     FilterField = "forks"
+	FilterIssues    FilterField = "issues"
+	FilterBookmarks FilterField = "bookmarks"
+	FilterSize      FilterField = "size" // KB
+	FilterPushed    FilterField = "pushed"
+	FilterCreated   FilterField = "created"
+	FilterTag       FilterField = "tag"
+	FilterTopic     FilterField = "topic"
+	FilterLicense   FilterField = "license"
+	FilterForge     FilterField = "forge"
+	FilterIs        FilterField = "is" // Value is one of the FilterIs* flags.
+)
+
+// Values of the "is" field.
+const (
+	FilterIsFork     = "fork"
+	FilterIsArchived = "archived"
+)
+
+// FilterOp compares a numeric or date field with the term's value.
+type FilterOp string
+
+const (
+	OpEq    FilterOp = "="
+	OpGt    FilterOp = ">"
+	OpGte   FilterOp = ">="
+	OpLt    FilterOp = "<"
+	OpLte   FilterOp = "<="
+	OpRange FilterOp = ".." // Inclusive; an open end is unset (nil Number/Upper, zero Date/DateUpper).
+)
+
+// FilterTerm is one parsed --where condition. A query matches when every term does.
+type FilterTerm struct {
+	Field  FilterField
+	Op     FilterOp // OpEq for text fields.
+	Negate bool     // "-field:value"
+
+	Value string // Text fields and "is".
+
+	// Numeric fields.
+	Number *int
+	Upper  *int // Upper bound of an OpRange.
+
+	// Date fields, at day granularity: "pushed:2024-01-01" matches the whole day.
+	Date      time.Time
+	DateUpper time.Time // Upper bound of an OpRange.
+}
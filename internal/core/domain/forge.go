@@ -0,0 +1,59 @@
+package domain
+
+import "strings"
+
+// Forge identifies the kind of code hosting platform a repository lives on.
+type Forge string
+
+const (
+	ForgeGitHub    Forge = "github"
+	ForgeGitLab    Forge = "gitlab"
+	ForgeGitea     Forge = "gitea" // Gitea, Forgejo and Codeberg share one API.
+	ForgeBitbucket Forge = "bitbucket"
+	ForgeSourceHut Forge = "sourcehut"
+)
+
+// GitHubHost is the implicit host of repo IDs without a host qualifier.
+const GitHubHost = "github.com"
+
+// ForgeConfig describes a forge host, e.g. a self-hosted GitLab or Gitea instance.
+type ForgeConfig struct {
+	Host   string `yaml:"host"`              // e.g. "git.example.com"
+	Type   Forge  `yaml:"type"`              // gitlab, gitea, bitbucket, sourcehut
+	Token  string `yaml:"token,omitempty"`   // Optional API token.
+	APIURL string `yaml:"api_url,omitempty"` // Overrides the API base URL derived from Host.
+}
+
+// RepoRef identifies a repository on a specific forge.
+type RepoRef struct {
+	Forge Forge
+	Host  string // e.g. "gitlab.com"
+	Owner string // May contain "/" for GitLab subgroups, or start with "~" on sourcehut.
+	Name  string
+}
+
+// ID returns the forge-qualified repo ID, e.g. "gitlab.com:group/sub/project".
+// GitHub repos keep their bare "owner/name" ID so existing databases stay valid.
+func (r RepoRef) ID() string {
+	path := r.Owner + "/" + r.Name
+	if r.Host == "" || r.Host == GitHubHost {
+		return path
+	}
+	return r.Host + ":" + path
+}
+
+// SplitRepoID splits a repo ID produced by RepoRef.ID into host, owner and name.
+// Returns false if the ID has no owner/name path.
+func SplitRepoID(id string) (host, owner, name string, ok bool) {
+	host = GitHubHost
+	path := id
+	if i := strings.Index(id, ":"); i >= 0 {
+		host, path = id[:i], id[i+1:]
+	}
+
+	i := strings.LastIndex(path, "/")
+	if i <= 0 || i == len(path)-1 {
+		return "", "", "", false
+	}
+	return host, path[:i], path[i+1:], true
+}
@@ -2,17 +2,37 @@ package domain
 
 import (
 	"context"
+	"time"
 )
 
 // BookmarkSource Interface for fetching bookmarks.
+// A zero 'since' fetches every bookmark; otherwise only bookmarks created or
+// modified at or after 'since' are returned.
 type BookmarkSource interface {
-	FetchBookmarks(ctx context.Context) ([]RawBookmark, error)
+	FetchBookmarks(ctx context.Context, since time.Time) ([]RawBookmark, error)
+}
+
+// BookmarkStateSource is implemented by bookmark sources that walk every bookmark even on an
+// incremental fetch. They return the state of each bookmark from that same pass, so deletions
+// and archiving can be reconciled without listing the account a second time.
+type BookmarkStateSource interface {
+	BookmarkSource
+	FetchBookmarksWithStates(ctx context.Context, since time.Time) ([]RawBookmark, []BookmarkState, error)
+}
+
+// SyncStateRepository Interface for persisting the sync checkpoint of a bookmark source.
+type SyncStateRepository interface {
+	// GetSyncState returns nil if the source has never been synced.
+	GetSyncState(ctx context.Context, source string) (*SyncState, error)
+	SaveSyncState(ctx context.Context, state SyncState) error
 }
 
 // RepoRepository Interface for persisting extracted repositories.
 type RepoRepository interface {
 	Save(ctx context.Context, repo ExtractedRepo) error
 	Exists(ctx context.Context, repoID string) (bool, error)
+	// ExistingRepos reports which of repoIDs exist, keyed by the given ID.
+	ExistingRepos(ctx context.Context, repoIDs []string) (map[string]bool, error)
 	GetReposForEnrichment(ctx context.Context, limit int, force bool) ([]*ExtractedRepo, error)
 	UpdateRepoEnrichment(ctx context.Context, update RepoEnrichmentUpdate) error
 }
@@ -21,6 +41,12 @@ type RepoEnrichmentUpdate struct {
 	RepoID           string
 	Stats            *RepoStats
 	EnrichmentStatus EnrichmentStatus
+	NotModified      bool // The forge reported no change; only the last-checked time is bumped.
+
+	// Set when the forge reports the repo under another name. The repo is moved to
+	// CanonicalID (merging into it if it already exists) and the old ID becomes an alias.
+	CanonicalID  string
+	CanonicalURL string
 }
 
 // GitHubClient Interface for fetching metadata from GitHub.
@@ -28,9 +54,131 @@ type GitHubClient interface {
 	GetRepoStats(ctx context.Context, owner, name string) (*RepoStats, int, error)
 }
 
+// ConditionalGitHubClient is a GitHubClient that can revalidate cached stats.
+// It returns ErrNotModified when the repo is unchanged since the given validators.
+type ConditionalGitHubClient interface {
+	GitHubClient
+	GetRepoStatsIfChanged(ctx context.Context, owner, name, etag, lastModified string) (*RepoStats, int, error)
+}
+
+// BatchGitHubClient is a GitHubClient that can also fetch many repositories in one request.
+// Results are keyed by "owner/name"; a per-repo Err (e.g. ErrRepoNotFound) doesn't fail the batch.
+type BatchGitHubClient interface {
+	GitHubClient
+	BatchSize() int
+	GetRepoStatsBatch(ctx context.Context, repos []RepoRef) (map[string]RepoStatsResult, int, error)
+}
+
+// RepoStatsResult is the outcome for one repository of a batched stats request.
+type RepoStatsResult struct {
+	Stats *RepoStats
+	Err   error
+}
+
+// ForgeClient Interface for fetching metadata from any other forge (GitLab, Gitea, ...).
+// 'owner' may contain "/" for GitLab subgroups or start with "~" on sourcehut.
+// The returned int is the remaining rate limit, or -1 if the forge doesn't report one.
+type ForgeClient interface {
+	GetRepoStats(ctx context.Context, owner, name string) (*RepoStats, int, error)
+}
+
+// BookmarkReconciler Interface for reconciling stored repos against the bookmarks that still exist.
+type BookmarkReconciler interface {
+	ReconcileBookmarks(ctx context.Context, bookmarks []BookmarkState) (ReconcileResult, error)
+}
+
 // RankingRepository interface for querying ranked repos (ReadOnly usually)
 type RankingRepository interface {
-	GetRankedRepos(ctx context.Context, limit int, sortBy RankSortOption, tagFilter string) ([]ExtractedRepo, error)
+	// GetRankedRepos returns matching repos in rank order, ties broken by repo ID.
+	GetRankedRepos(ctx context.Context, query RankQuery) ([]ExtractedRepo, error)
+	// CountRankedRepos returns the number of matching repos, ignoring Limit and Offset.
+	CountRankedRepos(ctx context.Context, query RankQuery) (int, error)
+}
+
+// RankQuery describes which repositories to rank and how.
+type RankQuery struct {
+	Limit               int // <= 0 means no limit.
+	Offset              int // Number of ranked repos to skip, for paging.
+	SortBy              RankSortOption
+	Tag                 string        // Optional tag filter.
+	TagSource           TagSource     // Only match Tag when attached by this source ("" = any).
+	IncludeOrphaned     bool          // Include repos whose bookmark was deleted.
+	IncludeArchived     bool          // Include repos whose bookmark was archived.
+	Since               time.Duration // Window for SortByStarsDelta.
+	License             string        // Optional SPDX license filter, case-insensitive.
+	Topic               string        // Optional forge topic filter.
+	ExcludeRepoArchived bool          // Hide repos archived on their forge.
+	Where               []FilterTerm  // Parsed --where conditions; all must match.
+
+	// StaleBefore, when set, selects only stale repos instead: no push since StaleBefore,
+	// archived on their forge, or no longer found (StatusNotFound).
+	StaleBefore time.Time
+}
+
+// ReadmeClient fetches a repository's README. It returns ErrNoReadme when there is none.
+type ReadmeClient interface {
+	GetReadme(ctx context.Context, owner, name string) (*Readme, error)
+}
+
+// ReadmeRepository stores README text for enriched repos.
+type ReadmeRepository interface {
+	// GetReposForReadme returns GitHub repos whose README was never fetched or may have
+	// changed since (pushed after the last check); force returns every enriched GitHub repo.
+	GetReposForReadme(ctx context.Context, limit int, force bool) ([]*ExtractedRepo, error)
+	UpdateRepoReadme(ctx context.Context, update ReadmeUpdate) error
+	// HydrateReadmes fills in the Readme text of each repo.
+	HydrateReadmes(ctx context.Context, repos []ExtractedRepo) error
+}
+
+// ReadmeUpdate records the result of a README fetch.
+type ReadmeUpdate struct {
+	RepoID    string
+	SHA       string // Empty when the repo has no README.
+	Text      string // Plain text, already size-capped.
+	Unchanged bool   // Same SHA as stored; only the check time is bumped.
+}
+
+// ReleaseClient fetches the latest release of a repository, falling back to its newest tag.
+// It returns ErrNoRelease when the repository has neither.
+type ReleaseClient interface {
+	GetLatestRelease(ctx context.Context, owner, name string) (*Release, error)
+}
+
+// ReleaseRepository stores the latest release of enriched repos.
+type ReleaseRepository interface {
+	// GetReposForReleases returns GitHub repos whose releases were never checked or that were
+	// pushed to since the last check; force returns every enriched GitHub repo.
+	GetReposForReleases(ctx context.Context, limit int, force bool) ([]*ExtractedRepo, error)
+	UpdateRepoRelease(ctx context.Context, update ReleaseUpdate) error
+	// GetNewReleases returns repos whose latest release was recorded after query.SeenAfter and
+	// published after query.PublishedAfter, newest first.
+	GetNewReleases(ctx context.Context, query ReleaseQuery) ([]ExtractedRepo, error)
+}
+
+// ReleaseUpdate records the result of a release check.
+type ReleaseUpdate struct {
+	RepoID  string
+	Release *Release // Nil when the repo has no releases or tags.
+}
+
+// ReleaseQuery selects repos with recent releases.
+type ReleaseQuery struct {
+	SeenAfter      time.Time // When the release was first recorded; zero means any time.
+	PublishedAfter time.Time // Zero means any time.
+	Limit          int       // <= 0 means no limit.
+}
+
+// SearchRepository interface for full-text search over stored repos.
+type SearchRepository interface {
+	SearchRepos(ctx context.Context, query SearchQuery) ([]ExtractedRepo, error)
+}
+
+// SearchQuery describes a full-text search.
+type SearchQuery struct {
+	Text            string // Space-separated terms; all must match.
+	Limit           int    // <= 0 means no limit.
+	IncludeOrphaned bool   // Include repos whose bookmark was deleted.
+	IncludeArchived bool   // Include repos whose bookmark was archived.
 }
 
 type RankSortOption string
@@ -39,6 +187,12 @@ const (
 	SortByStars   RankSortOption = "stars"
 	SortByForks   RankSortOption = "forks"
 	SortByUpdated RankSortOption = "updated"
+	// SortByStarsDelta ranks by stars gained over RankQuery.Since, from the stats history.
+	SortByStarsDelta RankSortOption = "stars-delta"
+	// SortByBookmarks ranks by how many bookmarks reference the repo.
+	SortByBookmarks RankSortOption = "bookmarks"
+	// SortByScore ranks by a weighted interest score computed over all matching repos.
+	SortByScore RankSortOption = "score"
 )
 
 // Sink interface for exporting data to external services
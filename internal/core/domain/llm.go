@@ -7,6 +7,12 @@ type LLMConfig struct {
 	APIKey    string `yaml:"api_key"`
 	Model     string `yaml:"model"`
 	MaxTokens int    `yaml:"max_tokens,omitempty"`
+	// Options are sent as Ollama model options, e.g. num_ctx: 8192.
+	Options map[string]interface{} `yaml:"options,omitempty"`
+	// IdleTimeout is how many seconds a response may stall before it is abandoned (0 = default).
+	IdleTimeout int `yaml:"idle_timeout,omitempty"`
+	// ContextTokens is the model's context window, which analyze fits its prompts into (0 = default).
+	ContextTokens int `yaml:"context_tokens,omitempty"`
 }
 
 // RepositoryContext represents a subset of repository data for LLM analysis.
@@ -19,6 +25,14 @@ type RepositoryContext struct {
 	Forks       int      `json:"forks"`
 	LastUpdated string   `json:"last_updated,omitempty"`
 	Tags        []string `json:"tags,omitempty"`
+	Topics      []string `json:"topics,omitempty"`
+	License     string   `json:"license,omitempty"`
+	Archived    bool     `json:"archived,omitempty"`
+	ForkOf      string   `json:"fork_of,omitempty"`
+	OpenIssues  int      `json:"open_issues,omitempty"`
+	Homepage    string   `json:"homepage,omitempty"`
+	Created     string   `json:"created,omitempty"`
+	Readme      string   `json:"readme,omitempty"`
 }
 
 // Message represents a single message in the chat completion conversation.
@@ -32,5 +46,14 @@ type AnalysisRequest struct {
 	Model    string    `json:"model"`
 	Messages []Message `json:"messages"`
 	// OpenAI specific, but generic enough
-	MaxTokens int `json:"max_tokens,omitempty"`
+	MaxTokens int  `json:"max_tokens,omitempty"`
+	Stream    bool `json:"stream,omitempty"`
+}
+
+// ChatTurn is one question and answer of an analyze chat session.
+type ChatTurn struct {
+	Question  string
+	Answer    string
+	Filter    string // Description of the repo selection the question was asked about.
+	RepoCount int
 }
@@ -0,0 +1,30 @@
+package domain
+
+// ScoreConfig configures the "score" ranking (config.yaml "score" section).
+type ScoreConfig struct {
+	Weights ScoreWeights `yaml:"weights,omitempty"`
+	// Tags are the Karakeep tags that make a repo more interesting; the tags component is
+	// the share of them a repo carries.
+	Tags []string `yaml:"tags,omitempty"`
+	// RecencyHalfLifeDays is how long after its last push a repo's recency component halves.
+	RecencyHalfLifeDays int `yaml:"recency_half_life_days,omitempty"`
+}
+
+// ScoreWeights weights each score component. A nil weight uses the default; 0 disables it.
+type ScoreWeights struct {
+	Stars     *float64 `yaml:"stars,omitempty"`
+	ForkRatio *float64 `yaml:"fork_ratio,omitempty"`
+	Recency   *float64 `yaml:"recency,omitempty"`
+	Bookmarks *float64 `yaml:"bookmarks,omitempty"`
+	Tags      *float64 `yaml:"tags,omitempty"`
+}
+
+// ScoreBreakdown is a repo's interest score and each component's weighted contribution to it.
+type ScoreBreakdown struct {
+	Total     float64
+	Stars     float64 // Log-scaled stars, relative to the most starred repo ranked.
+	ForkRatio float64 // Forks per star, capped at 1.
+	Recency   float64 // Exponential decay since the last push.
+	Bookmarks float64 // Extra bookmarks, relative to the most bookmarked repo ranked.
+	Tags      float64 // Share of the configured interest tags the repo carries.
+}
@@ -0,0 +1,19 @@
+package domain
+
+// View is a saved rank query (config.yaml "views" section), run with 'rank --view <name>'.
+// Unset fields fall back to the rank flags' defaults.
+type View struct {
+	Description string    `yaml:"description,omitempty"`
+	Where       string    `yaml:"where,omitempty"` // --where expression
+	Sort        string    `yaml:"sort,omitempty"`
+	Limit       int       `yaml:"limit,omitempty"`
+	Format      string    `yaml:"format,omitempty"`
+	Sink        *ViewSink `yaml:"sink,omitempty"`
+}
+
+// ViewSink is where a view's results are also sent, like the rank --sink-* flags.
+type ViewSink struct {
+	URL      string   `yaml:"url,omitempty"`
+	Headers  []string `yaml:"headers,omitempty"` // "Key: Value"
+	Trillium bool     `yaml:"trillium,omitempty"`
+}
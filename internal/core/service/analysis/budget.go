@@ -0,0 +1,110 @@
+package analysis
+
+import (
+	"encoding/json"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/brianluby/karakeep-extractor/internal/core/domain"
+)
+
+const (
+	// DefaultContextTokens is the context window assumed when none is configured.
+	DefaultContextTokens = 32000
+	// DefaultAnswerTokens is how much of the context window is kept free for the answer
+	// when no max_tokens is configured.
+	DefaultAnswerTokens = 4096
+	// messageTokens approximates the per-message overhead of the chat format.
+	messageTokens = 4
+)
+
+// EstimateTokens approximates how many tokens text uses, at about four characters
+// per token. It is deliberately simple: providers tokenize differently, so the
+// budget leaves room for the estimate to be off.
+func EstimateTokens(text string) int {
+	return (utf8.RuneCountInString(text) + 3) / 4
+}
+
+// EstimateRepoTokens approximates how many tokens a repository adds to the prompt.
+func EstimateRepoTokens(repo domain.RepositoryContext) int {
+	data, err := json.Marshal(repo)
+	if err != nil {
+		return 0
+	}
+	return EstimateTokens(string(data)) + 1 // The separating comma.
+}
+
+// PromptBudget returns how many prompt tokens fit in a context window of contextTokens,
+// keeping answerTokens free for the answer. Zero values use the defaults.
+func PromptBudget(contextTokens int, answerTokens int) int {
+	if contextTokens <= 0 {
+		contextTokens = DefaultContextTokens
+	}
+	if answerTokens <= 0 {
+		answerTokens = DefaultAnswerTokens
+	}
+	// A max_tokens close to the window would leave no room for the repos at all.
+	if answerTokens > contextTokens/2 {
+		answerTokens = contextTokens / 2
+	}
+	return contextTokens - answerTokens
+}
+
+func estimateMessages(msgs []domain.Message) int {
+	total := 0
+	for _, msg := range msgs {
+		total += EstimateTokens(msg.Content) + messageTokens
+	}
+	return total
+}
+
+// packContexts splits contexts, in order, into parts whose repos fit in budget tokens
+// each. A repo too large for the budget on its own gets a part to itself.
+func packContexts(contexts []domain.RepositoryContext, budget int) [][]domain.RepositoryContext {
+	var parts [][]domain.RepositoryContext
+	var part []domain.RepositoryContext
+	used := 0
+	for _, repo := range contexts {
+		tokens := EstimateRepoTokens(repo)
+		if len(part) > 0 && used+tokens > budget {
+			parts = append(parts, part)
+			part, used = nil, 0
+		}
+		part = append(part, repo)
+		used += tokens
+	}
+	if len(part) > 0 {
+		parts = append(parts, part)
+	}
+	return parts
+}
+
+// packAnswers groups partial answers so that each group fits in budget tokens.
+func packAnswers(answers []string, budget int) [][]string {
+	var groups [][]string
+	var group []string
+	used := 0
+	for _, answer := range answers {
+		tokens := EstimateTokens(answer) + messageTokens
+		if len(group) > 0 && used+tokens > budget {
+			groups = append(groups, group)
+			group, used = nil, 0
+		}
+		group = append(group, answer)
+		used += tokens
+	}
+	if len(group) > 0 {
+		groups = append(groups, group)
+	}
+	return groups
+}
+
+// repoBudget returns how many tokens of budget are left for repository data once the
+// prompt template and the other messages are counted.
+func repoBudget(budget int, others []domain.Message) (int, error) {
+	overhead := EstimateTokens(fmt.Sprintf(partTemplate, 99, 99, "[]")) + messageTokens + estimateMessages(others)
+	if overhead >= budget {
+		return 0, fmt.Errorf("the question and conversation (~%d tokens) leave no room for repositories in the %d-token context budget", overhead, budget)
+	}
+	return budget - overhead, nil
+}
@@ -0,0 +1,68 @@
+package analysis
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/brianluby/karakeep-extractor/internal/core/domain"
+)
+
+func TestEstimateTokens(t *testing.T) {
+	tests := map[string]int{
+		"":         0,
+		"abc":      1,
+		"abcd":     1,
+		"abcde":    2,
+		"héllo wö": 2,
+	}
+	for text, expected := range tests {
+		if got := EstimateTokens(text); got != expected {
+			t.Errorf("EstimateTokens(%q) = %d, expected %d", text, got, expected)
+		}
+	}
+
+	small := EstimateRepoTokens(domain.RepositoryContext{Name: "a/b"})
+	large := EstimateRepoTokens(domain.RepositoryContext{Name: "a/b", Readme: strings.Repeat("x", 400)})
+	if large-small != 100+EstimateTokens(`,"readme":""`) {
+		t.Errorf("Expected a 400-character README to add about 100 tokens, got %d -> %d", small, large)
+	}
+}
+
+func TestPromptBudget(t *testing.T) {
+	tests := []struct {
+		context, answer, expected int
+	}{
+		{0, 0, DefaultContextTokens - DefaultAnswerTokens},
+		{8192, 1024, 7168},
+		{4096, 0, 2048}, // The default answer reserve is capped at half the window.
+	}
+	for _, tt := range tests {
+		if got := PromptBudget(tt.context, tt.answer); got != tt.expected {
+			t.Errorf("PromptBudget(%d, %d) = %d, expected %d", tt.context, tt.answer, got, tt.expected)
+		}
+	}
+}
+
+func TestPackContexts(t *testing.T) {
+	var contexts []domain.RepositoryContext
+	for i := 0; i < 5; i++ {
+		contexts = append(contexts, domain.RepositoryContext{Name: fmt.Sprintf("owner/repo%d", i)})
+	}
+	contexts[3].Readme = strings.Repeat("x", 4000) // Too big to share a part.
+
+	each := EstimateRepoTokens(contexts[0])
+	parts := packContexts(contexts, 2*each)
+
+	var got []string
+	for _, part := range parts {
+		var names []string
+		for _, repo := range part {
+			names = append(names, strings.TrimPrefix(repo.Name, "owner/"))
+		}
+		got = append(got, strings.Join(names, "+"))
+	}
+	if strings.Join(got, " ") != "repo0+repo1 repo2 repo3 repo4" {
+		t.Errorf("Unexpected parts: %v", got)
+	}
+}
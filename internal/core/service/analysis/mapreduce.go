@@ -0,0 +1,121 @@
+package analysis
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/brianluby/karakeep-extractor/internal/core/domain"
+)
+
+// answer replies to question about repos, following the conversation in history. When the
+// repos don't fit in the context budget, each part is analyzed on its own and the partial
+// answers are combined (map-reduce). Only the final answer is streamed.
+func (s *Service) answer(ctx context.Context, repos []domain.ExtractedRepo, history []domain.Message, question string) (string, error) {
+	contexts := buildContexts(repos, s.prompt)
+	others := make([]domain.Message, 0, len(history)+1)
+	others = append(others, history...)
+	others = append(others, domain.Message{Role: "user", Content: question})
+
+	if s.budget > 0 {
+		room, err := repoBudget(s.budget, others)
+		if err != nil {
+			return "", err
+		}
+		if parts := packContexts(contexts, room); len(parts) > 1 {
+			return s.mapReduce(ctx, parts, others)
+		}
+	}
+
+	system, err := systemMessage(contexts)
+	if err != nil {
+		return "", err
+	}
+	return s.send(ctx, append([]domain.Message{system}, others...))
+}
+
+// mapReduce answers the question for each part of the repos, then combines the answers.
+func (s *Service) mapReduce(ctx context.Context, parts [][]domain.RepositoryContext, others []domain.Message) (string, error) {
+	reporter := s.progress
+	if reporter == nil {
+		reporter = nopReporter{}
+	}
+
+	total := 0
+	for _, part := range parts {
+		total += len(part)
+	}
+	reporter.Start(len(parts)+1, fmt.Sprintf("Analyzing %d repositories in %d parts", total, len(parts)))
+
+	answers := make([]string, 0, len(parts))
+	for i, part := range parts {
+		reporter.SetStatus(fmt.Sprintf("Analyzing part %d of %d (%d repositories)", i+1, len(parts), len(part)))
+		system, err := partMessage(part, i+1, len(parts))
+		if err != nil {
+			return "", err
+		}
+		answer, err := s.llm.SendMessage(ctx, domain.AnalysisRequest{Messages: append([]domain.Message{system}, others...)})
+		reporter.Increment()
+		if err != nil {
+			reporter.RecordFailure()
+			return "", fmt.Errorf("failed to analyze part %d of %d: %w", i+1, len(parts), err)
+		}
+		reporter.RecordSuccess()
+		answers = append(answers, answer)
+	}
+
+	reporter.SetStatus(fmt.Sprintf("Combining %d partial answers", len(answers)))
+	answer, err := s.combine(ctx, answers, others)
+	reporter.Increment()
+	if err != nil {
+		reporter.RecordFailure()
+		return "", err
+	}
+	reporter.RecordSuccess()
+	reporter.Finish(fmt.Sprintf("Combined the answers for %d parts", len(parts)))
+	return answer, nil
+}
+
+// combine merges the partial answers into one. Answers too long to combine at once are
+// first combined in groups.
+func (s *Service) combine(ctx context.Context, answers []string, others []domain.Message) (string, error) {
+	for s.budget > 0 && len(answers) > 1 {
+		room := s.budget - EstimateTokens(fmt.Sprintf(combineTemplate, "")) - messageTokens - estimateMessages(others)
+		groups := packAnswers(answers, room)
+		if len(groups) == 1 || len(groups) == len(answers) {
+			// Either everything fits, or no two answers do and grouping can't help.
+			break
+		}
+		combined := make([]string, 0, len(groups))
+		for _, group := range groups {
+			if len(group) == 1 {
+				combined = append(combined, group[0])
+				continue
+			}
+			answer, err := s.llm.SendMessage(ctx, domain.AnalysisRequest{Messages: append([]domain.Message{combineMessage(group)}, others...)})
+			if err != nil {
+				return "", fmt.Errorf("failed to combine partial answers: %w", err)
+			}
+			combined = append(combined, answer)
+		}
+		answers = combined
+	}
+
+	answer, err := s.send(ctx, append([]domain.Message{combineMessage(answers)}, others...))
+	if err != nil {
+		return "", fmt.Errorf("failed to combine partial answers: %w", err)
+	}
+	return answer, nil
+}
+
+// nopReporter discards progress when no reporter is set.
+type nopReporter struct{}
+
+func (nopReporter) Start(total int, title string) {}
+func (nopReporter) Increment()                    {}
+func (nopReporter) SetStatus(status string)       {}
+func (nopReporter) Log(message string)            {}
+func (nopReporter) Error(err error)               {}
+func (nopReporter) Finish(summary string)         {}
+func (nopReporter) RecordSuccess()                {}
+func (nopReporter) RecordFailure()                {}
+func (nopReporter) RecordSkipped()                {}
@@ -0,0 +1,104 @@
+package analysis
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/brianluby/karakeep-extractor/internal/core/domain"
+)
+
+// countingReporter records progress events.
+type countingReporter struct {
+	nopReporter
+	total, increments, successes int
+}
+
+func (r *countingReporter) Start(total int, title string) { r.total = total }
+func (r *countingReporter) Increment()                    { r.increments++ }
+func (r *countingReporter) RecordSuccess()                { r.successes++ }
+
+func manyRepos(n int) []domain.ExtractedRepo {
+	repos := make([]domain.ExtractedRepo, n)
+	for i := range repos {
+		desc := strings.Repeat("A repository description. ", 10)
+		repos[i] = domain.ExtractedRepo{RepoID: fmt.Sprintf("owner/repo%02d", i), Description: &desc}
+	}
+	return repos
+}
+
+func TestAnalyze_MapReduce(t *testing.T) {
+	repo := &mockRankingRepo{repos: map[string][]domain.ExtractedRepo{"": manyRepos(20)}}
+	llm := &mockLLM{answer: "Partial"}
+	reporter := &countingReporter{}
+	var streamed []string
+	svc := NewService(repo, llm).
+		WithContextBudget(1500).
+		WithProgress(reporter).
+		WithStream(func(token string) { streamed = append(streamed, token) })
+
+	if _, err := svc.Analyze(context.Background(), "Which is best?", domain.RankQuery{}); err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+
+	parts := len(llm.requests) - 1
+	if parts < 2 {
+		t.Fatalf("Expected the repos to be split into parts, got %d requests", len(llm.requests))
+	}
+	seen := 0
+	for i, msgs := range llm.requests[:parts] {
+		if estimateMessages(msgs) > 1500 {
+			t.Errorf("Part %d exceeds the budget: ~%d tokens", i+1, estimateMessages(msgs))
+		}
+		if !strings.Contains(msgs[0].Content, fmt.Sprintf("part %d of %d", i+1, parts)) {
+			t.Errorf("Part %d is missing its position: %q", i+1, msgs[0].Content[:200])
+		}
+		seen += strings.Count(msgs[0].Content, `"name":"owner/repo`)
+	}
+	if seen != 20 {
+		t.Errorf("Expected every repo in exactly one part, saw %d", seen)
+	}
+
+	final := llm.requests[parts]
+	if !strings.Contains(final[0].Content, "### Part 1\n\nPartial") || final[len(final)-1].Content != "Which is best?" {
+		t.Errorf("Unexpected combine request: %+v", final)
+	}
+	if len(streamed) != 1 {
+		t.Errorf("Expected only the combined answer to stream, got %q", streamed)
+	}
+	if reporter.total != parts+1 || reporter.increments != parts+1 || reporter.successes != parts+1 {
+		t.Errorf("Unexpected progress: %+v", reporter)
+	}
+}
+
+func TestAnalyze_FitsBudget(t *testing.T) {
+	repo := &mockRankingRepo{repos: map[string][]domain.ExtractedRepo{"": manyRepos(3)}}
+	llm := &mockLLM{answer: "Whole"}
+	answer, err := NewService(repo, llm).WithContextBudget(PromptBudget(0, 0)).
+		Analyze(context.Background(), "Which is best?", domain.RankQuery{})
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	if answer != "Whole" || len(llm.requests) != 1 || roles(llm.requests[0]) != "system,user" {
+		t.Errorf("Expected a single request, got %d", len(llm.requests))
+	}
+}
+
+func TestCombine_Groups(t *testing.T) {
+	llm := &mockLLM{answer: "Merged"}
+	svc := NewService(&mockRankingRepo{}, llm).WithContextBudget(600)
+	answers := []string{strings.Repeat("a", 800), strings.Repeat("b", 800), strings.Repeat("c", 800)}
+	others := []domain.Message{{Role: "user", Content: "Which is best?"}}
+
+	if _, err := svc.combine(context.Background(), answers, others); err != nil {
+		t.Fatalf("combine failed: %v", err)
+	}
+	// Two answers fit per group: (a+b) is merged, c passes through, then both are combined.
+	if len(llm.requests) != 2 {
+		t.Fatalf("Expected 2 combine requests, got %d", len(llm.requests))
+	}
+	if last := llm.requests[1][0].Content; !strings.Contains(last, "Merged") || !strings.Contains(last, strings.Repeat("c", 800)) {
+		t.Errorf("Unexpected final combine prompt: %q", last)
+	}
+}
@@ -3,18 +3,39 @@ package analysis
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/brianluby/karakeep-extractor/internal/core/domain"
 )
 
+// DefaultReadmeChars is how much of each README goes into the prompt when READMEs are included.
+const DefaultReadmeChars = 2000
+
+// PromptOptions controls what goes into the repository context.
+type PromptOptions struct {
+	IncludeReadme bool // Add each repo's README text (repos must be hydrated).
+	ReadmeChars   int  // Per-repo README cap; <= 0 uses DefaultReadmeChars.
+}
+
 // BuildMessages constructs the chat messages for the LLM analysis.
-func BuildMessages(query string, repos []domain.ExtractedRepo) ([]domain.Message, error) {
-	contextJSON, err := serializeRepos(repos)
+func BuildMessages(query string, repos []domain.ExtractedRepo, opts PromptOptions) ([]domain.Message, error) {
+	system, err := BuildSystemMessage(repos, opts)
 	if err != nil {
 		return nil, err
 	}
 
-	systemContent := fmt.Sprintf(`You are an expert software engineering assistant.
+	return []domain.Message{
+		system,
+		{Role: "user", Content: query},
+	}, nil
+}
+
+// BuildSystemMessage constructs the system message holding the repository context.
+func BuildSystemMessage(repos []domain.ExtractedRepo, opts PromptOptions) (domain.Message, error) {
+	return systemMessage(buildContexts(repos, opts))
+}
+
+const systemTemplate = `You are an expert software engineering assistant.
 You are analyzing a curated list of GitHub repositories provided in JSON format.
 Your goal is to answer the user's question based on the provided repository data.
 
@@ -25,15 +46,71 @@ Instructions:
 - Base your answer strictly on the provided data.
 - If the answer cannot be found in the data, say so.
 - Be concise and helpful.
-`, contextJSON)
+`
 
-	return []domain.Message{
-		{Role: "system", Content: systemContent},
-		{Role: "user", Content: query},
-	}, nil
+// partTemplate is the system prompt for one part of a map-reduce analysis.
+const partTemplate = `You are an expert software engineering assistant.
+You are analyzing part %d of %d of a curated list of GitHub repositories provided in JSON format.
+Your answer will be combined with the answers for the other parts, so answer the user's question for this part only.
+
+Repository Data:
+%s
+
+Instructions:
+- Base your answer strictly on the provided data.
+- Name the relevant repositories and keep the details needed to compare them with repositories from other parts.
+- If no repository in this part is relevant, say so briefly.
+`
+
+// combineTemplate is the system prompt for combining the answers of a map-reduce analysis.
+const combineTemplate = `You are an expert software engineering assistant.
+The repositories were too many to analyze at once, so they were split into parts and the user's question was answered for each part.
+Combine the partial answers below into a single answer to the user's question.
+
+Partial Answers:
+%s
+
+Instructions:
+- Base your answer strictly on the partial answers.
+- Compare repositories across parts instead of listing the parts one after another.
+- If the answer cannot be found in the partial answers, say so.
+- Be concise and helpful.
+`
+
+func systemMessage(contexts []domain.RepositoryContext) (domain.Message, error) {
+	contextJSON, err := serializeContexts(contexts)
+	if err != nil {
+		return domain.Message{}, err
+	}
+	return domain.Message{Role: "system", Content: fmt.Sprintf(systemTemplate, contextJSON)}, nil
+}
+
+func partMessage(contexts []domain.RepositoryContext, part int, parts int) (domain.Message, error) {
+	contextJSON, err := serializeContexts(contexts)
+	if err != nil {
+		return domain.Message{}, err
+	}
+	return domain.Message{Role: "system", Content: fmt.Sprintf(partTemplate, part, parts, contextJSON)}, nil
 }
 
-func serializeRepos(repos []domain.ExtractedRepo) (string, error) {
+func combineMessage(answers []string) domain.Message {
+	var b strings.Builder
+	for i, answer := range answers {
+		if i > 0 {
+			b.WriteString("\n\n")
+		}
+		fmt.Fprintf(&b, "### Part %d\n\n%s", i+1, strings.TrimSpace(answer))
+	}
+	return domain.Message{Role: "system", Content: fmt.Sprintf(combineTemplate, b.String())}
+}
+
+// buildContexts converts repos to the subset of data sent to the LLM.
+func buildContexts(repos []domain.ExtractedRepo, opts PromptOptions) []domain.RepositoryContext {
+	readmeChars := opts.ReadmeChars
+	if readmeChars <= 0 {
+		readmeChars = DefaultReadmeChars
+	}
+
 	var contexts []domain.RepositoryContext
 	for _, r := range repos {
 		ctx := domain.RepositoryContext{
@@ -55,11 +132,28 @@ func serializeRepos(repos []domain.ExtractedRepo) (string, error) {
 		if r.LastPushedAt != nil {
 			ctx.LastUpdated = r.LastPushedAt.Format("2006-01-02")
 		}
-		// Tags not available in ExtractedRepo yet
-		
+		ctx.Tags = domain.TagNames(r.Tags)
+		ctx.Topics = r.Topics
+		ctx.License = r.License
+		ctx.Archived = r.RepoArchived
+		ctx.ForkOf = r.Parent
+		ctx.Homepage = r.Homepage
+		if r.OpenIssues != nil {
+			ctx.OpenIssues = *r.OpenIssues
+		}
+		if r.CreatedAt != nil {
+			ctx.Created = r.CreatedAt.Format("2006-01-02")
+		}
+		if opts.IncludeReadme {
+			ctx.Readme = truncateRunes(r.Readme, readmeChars)
+		}
+
 		contexts = append(contexts, ctx)
 	}
+	return contexts
+}
 
+func serializeContexts(contexts []domain.RepositoryContext) (string, error) {
 	// Use Marshal without indent to save tokens? Or Indent for readability?
 	// Indent uses more tokens. We should probably use compact.
 	data, err := json.Marshal(contexts)
@@ -68,3 +162,12 @@ func serializeRepos(repos []domain.ExtractedRepo) (string, error) {
 	}
 	return string(data), nil
 }
+
+// truncateRunes shortens s to at most n characters.
+func truncateRunes(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n]) + "…"
+}
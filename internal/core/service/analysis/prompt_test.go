@@ -23,7 +23,7 @@ func TestBuildMessages(t *testing.T) {
 		},
 	}
 
-	msgs, err := BuildMessages("Summarize this", repos)
+	msgs, err := BuildMessages("Summarize this", repos, PromptOptions{})
 	if err != nil {
 		t.Fatalf("BuildMessages failed: %v", err)
 	}
@@ -49,3 +49,23 @@ func TestBuildMessages(t *testing.T) {
 		t.Errorf("Expected user message content to match query")
 	}
 }
+
+func TestBuildMessages_Readme(t *testing.T) {
+	repos := []domain.ExtractedRepo{{RepoID: "owner/repo", Readme: "Supports streaming responses out of the box."}}
+
+	msgs, err := BuildMessages("Which supports streaming?", repos, PromptOptions{})
+	if err != nil {
+		t.Fatalf("BuildMessages failed: %v", err)
+	}
+	if strings.Contains(msgs[0].Content, "streaming responses") {
+		t.Errorf("Expected README to be left out by default")
+	}
+
+	msgs, err = BuildMessages("Which supports streaming?", repos, PromptOptions{IncludeReadme: true, ReadmeChars: 18})
+	if err != nil {
+		t.Fatalf("BuildMessages failed: %v", err)
+	}
+	if !strings.Contains(msgs[0].Content, `"readme":"Supports streaming…"`) {
+		t.Errorf("Expected truncated README in context, got %s", msgs[0].Content)
+	}
+}
@@ -3,18 +3,24 @@ package analysis
 import (
 	"context"
 	"fmt"
-	"strings"
 
 	"github.com/brianluby/karakeep-extractor/internal/core/domain"
 )
 
 type LLMProvider interface {
 	SendMessage(ctx context.Context, req domain.AnalysisRequest) (string, error)
+	// StreamMessage is SendMessage, passing each piece of the reply to onToken as it arrives.
+	StreamMessage(ctx context.Context, req domain.AnalysisRequest, onToken func(string)) (string, error)
 }
 
 type Service struct {
-	repo domain.RankingRepository
-	llm  LLMProvider
+	repo     domain.RankingRepository
+	llm      LLMProvider
+	readmes  domain.ReadmeRepository // Optional; adds README text to the context.
+	prompt   PromptOptions
+	onToken  func(string)            // Optional; streams the answer.
+	budget   int                     // Prompt tokens per request; <= 0 sends everything at once.
+	progress domain.ProgressReporter // Optional; reports map-reduce progress.
 }
 
 func NewService(repo domain.RankingRepository, llm LLMProvider) *Service {
@@ -24,65 +30,73 @@ func NewService(repo domain.RankingRepository, llm LLMProvider) *Service {
 	}
 }
 
-func (s *Service) Analyze(ctx context.Context, query string, limit int, langFilter string, tagFilter string, minStars int, maxStars int) (string, error) {
-	// Fetch a large batch to allow for local filtering
-	// If the user asks for a range like 500-1000, and we only fetch top 500 by stars, we might miss them if they are further down.
-	// We might need to fetch MORE if a specific range is requested that isn't at the top.
-	// For now, let's bump the fetch limit if filtering by stars to ensure we catch them.
-	fetchLimit := 2000 
-	if limit > fetchLimit {
-		fetchLimit = limit
-	}
-
-	// Default sort by stars to get "best" repos first
-	repos, err := s.repo.GetRankedRepos(ctx, fetchLimit, domain.SortByStars, tagFilter)
-	if err != nil {
-		return "", fmt.Errorf("failed to fetch repos: %w", err)
-	}
+// WithReadmes includes up to readmeChars of each repo's stored README in the LLM context.
+func (s *Service) WithReadmes(readmes domain.ReadmeRepository, readmeChars int) *Service {
+	s.readmes = readmes
+	s.prompt.IncludeReadme = true
+	s.prompt.ReadmeChars = readmeChars
+	return s
+}
 
-	// Filter
-	var filtered []domain.ExtractedRepo
-	for _, r := range repos {
-		// Language Filter
-		if langFilter != "" {
-			if r.Language == nil || !strings.EqualFold(*r.Language, langFilter) {
-				continue
-			}
-		}
+// WithStream passes each piece of the answer to onToken as the LLM produces it.
+// Analyze still returns the whole answer.
+func (s *Service) WithStream(onToken func(string)) *Service {
+	s.onToken = onToken
+	return s
+}
 
-		// Stars Filter
-		stars := 0
-		if r.Stars != nil {
-			stars = *r.Stars
-		}
-		if stars < minStars {
-			continue
-		}
-		if maxStars > 0 && stars > maxStars {
-			continue
-		}
+// WithContextBudget limits each request to about tokens of prompt (see PromptBudget).
+// Repos that don't fit are analyzed in parts and the answers combined.
+func (s *Service) WithContextBudget(tokens int) *Service {
+	s.budget = tokens
+	return s
+}
 
-		filtered = append(filtered, r)
-	}
+// WithProgress reports the progress of analyses split into parts.
+func (s *Service) WithProgress(reporter domain.ProgressReporter) *Service {
+	s.progress = reporter
+	return s
+}
 
-	// Apply final limit
-	if len(filtered) > limit {
-		filtered = filtered[:limit]
+// Analyze answers question about the repos selected by query (by default the top repos by stars).
+// All filtering happens in the query, so the limit applies to matching repos.
+func (s *Service) Analyze(ctx context.Context, question string, query domain.RankQuery) (string, error) {
+	repos, err := s.loadRepos(ctx, query)
+	if err != nil {
+		return "", err
 	}
 
-	if len(filtered) == 0 {
+	if len(repos) == 0 {
 		return "No repositories found matching your criteria.", nil
 	}
 
-	// Build Prompt
-	msgs, err := BuildMessages(query, filtered)
+	return s.answer(ctx, repos, nil, question)
+}
+
+// loadRepos fetches the repos selected by query, with READMEs if enabled.
+func (s *Service) loadRepos(ctx context.Context, query domain.RankQuery) ([]domain.ExtractedRepo, error) {
+	if query.SortBy == "" {
+		query.SortBy = domain.SortByStars
+	}
+	repos, err := s.repo.GetRankedRepos(ctx, query)
 	if err != nil {
-		return "", err
+		return nil, fmt.Errorf("failed to fetch repos: %w", err)
+	}
+	if s.readmes != nil && len(repos) > 0 {
+		if err := s.readmes.HydrateReadmes(ctx, repos); err != nil {
+			return nil, fmt.Errorf("failed to load readmes: %w", err)
+		}
 	}
+	return repos, nil
+}
 
-	// Call LLM
+// send asks the LLM to continue the conversation, streaming if enabled.
+func (s *Service) send(ctx context.Context, msgs []domain.Message) (string, error) {
 	req := domain.AnalysisRequest{
 		Messages: msgs,
 	}
+	if s.onToken != nil {
+		return s.llm.StreamMessage(ctx, req, s.onToken)
+	}
 	return s.llm.SendMessage(ctx, req)
 }
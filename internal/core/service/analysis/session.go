@@ -0,0 +1,85 @@
+package analysis
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/brianluby/karakeep-extractor/internal/core/domain"
+)
+
+// Session is a conversation about one selection of repos. The repos are loaded once,
+// and each question is sent along with the conversation so far.
+type Session struct {
+	svc    *Service
+	query  domain.RankQuery
+	filter string
+	repos  []domain.ExtractedRepo
+	turns  []domain.ChatTurn
+}
+
+// NewSession starts a conversation about the repos selected by query. The filter
+// describes the selection in the transcript.
+func (s *Service) NewSession(ctx context.Context, query domain.RankQuery, filter string) (*Session, error) {
+	session := &Session{svc: s}
+	if err := session.SetQuery(ctx, query, filter); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// SetQuery switches to a new selection of repos, keeping the conversation so far.
+func (c *Session) SetQuery(ctx context.Context, query domain.RankQuery, filter string) error {
+	repos, err := c.svc.loadRepos(ctx, query)
+	if err != nil {
+		return err
+	}
+	c.query, c.filter, c.repos = query, filter, repos
+	return nil
+}
+
+// Ask sends question with the conversation so far and records the answer. A failed
+// or cancelled question is left out of the conversation.
+func (c *Session) Ask(ctx context.Context, question string) (string, error) {
+	if len(c.repos) == 0 {
+		return "", fmt.Errorf("no repositories match the current filter")
+	}
+
+	history := make([]domain.Message, 0, 2*len(c.turns))
+	for _, turn := range c.turns {
+		history = append(history,
+			domain.Message{Role: "user", Content: turn.Question},
+			domain.Message{Role: "assistant", Content: turn.Answer})
+	}
+
+	answer, err := c.svc.answer(ctx, c.repos, history, question)
+	if err != nil {
+		return "", err
+	}
+	c.turns = append(c.turns, domain.ChatTurn{Question: question, Answer: answer, Filter: c.filter, RepoCount: len(c.repos)})
+	return answer, nil
+}
+
+// Reset forgets the conversation, keeping the current repos.
+func (c *Session) Reset() {
+	c.turns = nil
+}
+
+// Query returns the query selecting the current repos.
+func (c *Session) Query() domain.RankQuery {
+	return c.query
+}
+
+// Filter describes the current selection of repos.
+func (c *Session) Filter() string {
+	return c.filter
+}
+
+// RepoCount is the number of repos in the current context.
+func (c *Session) RepoCount() int {
+	return len(c.repos)
+}
+
+// Turns returns the conversation so far.
+func (c *Session) Turns() []domain.ChatTurn {
+	return c.turns
+}
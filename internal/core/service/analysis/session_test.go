@@ -0,0 +1,123 @@
+package analysis
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/brianluby/karakeep-extractor/internal/core/domain"
+)
+
+// mockRankingRepo returns repos filtered by tag.
+type mockRankingRepo struct {
+	repos map[string][]domain.ExtractedRepo // By tag.
+}
+
+func (m *mockRankingRepo) GetRankedRepos(ctx context.Context, query domain.RankQuery) ([]domain.ExtractedRepo, error) {
+	return m.repos[query.Tag], nil
+}
+
+func (m *mockRankingRepo) CountRankedRepos(ctx context.Context, query domain.RankQuery) (int, error) {
+	return len(m.repos[query.Tag]), nil
+}
+
+// mockLLM records the messages of each request and answers with a fixed reply.
+type mockLLM struct {
+	requests [][]domain.Message
+	answer   string
+	err      error
+}
+
+func (m *mockLLM) SendMessage(ctx context.Context, req domain.AnalysisRequest) (string, error) {
+	m.requests = append(m.requests, req.Messages)
+	return m.answer, m.err
+}
+
+func (m *mockLLM) StreamMessage(ctx context.Context, req domain.AnalysisRequest, onToken func(string)) (string, error) {
+	answer, err := m.SendMessage(ctx, req)
+	if err == nil {
+		onToken(answer)
+	}
+	return answer, err
+}
+
+func roles(msgs []domain.Message) string {
+	var out []string
+	for _, msg := range msgs {
+		out = append(out, msg.Role)
+	}
+	return strings.Join(out, ",")
+}
+
+func TestSession_Ask(t *testing.T) {
+	repo := &mockRankingRepo{repos: map[string][]domain.ExtractedRepo{
+		"":   {{RepoID: "owner/one"}, {RepoID: "owner/two"}},
+		"go": {{RepoID: "owner/gopher"}},
+	}}
+	llm := &mockLLM{answer: "An answer"}
+	session, err := NewService(repo, llm).NewSession(context.Background(), domain.RankQuery{}, "")
+	if err != nil {
+		t.Fatalf("NewSession failed: %v", err)
+	}
+
+	for _, q := range []string{"First?", "Second?"} {
+		if _, err := session.Ask(context.Background(), q); err != nil {
+			t.Fatalf("Ask failed: %v", err)
+		}
+	}
+	if got := roles(llm.requests[1]); got != "system,user,assistant,user" {
+		t.Errorf("Expected the first turn to be sent with the second question, got %s", got)
+	}
+	if last := llm.requests[1][3].Content; last != "Second?" {
+		t.Errorf("Expected the new question last, got %q", last)
+	}
+
+	// A failed question is not added to the conversation.
+	llm.err = errors.New("boom")
+	if _, err := session.Ask(context.Background(), "Third?"); err == nil {
+		t.Error("Expected the LLM error")
+	}
+	if len(session.Turns()) != 2 {
+		t.Errorf("Expected 2 turns after a failed question, got %d", len(session.Turns()))
+	}
+	llm.err = nil
+
+	// A new filter swaps the repos but keeps the conversation.
+	if err := session.SetQuery(context.Background(), domain.RankQuery{Tag: "go"}, "tag:go"); err != nil {
+		t.Fatalf("SetQuery failed: %v", err)
+	}
+	if _, err := session.Ask(context.Background(), "And now?"); err != nil {
+		t.Fatalf("Ask failed: %v", err)
+	}
+	sent := llm.requests[len(llm.requests)-1]
+	if len(sent) != 6 || !strings.Contains(sent[0].Content, "owner/gopher") || strings.Contains(sent[0].Content, "owner/one") {
+		t.Errorf("Expected the history with the new repos, got %d messages: %q", len(sent), sent[0].Content)
+	}
+	turns := session.Turns()
+	if turns[2].Filter != "tag:go" || turns[2].RepoCount != 1 || turns[0].RepoCount != 2 {
+		t.Errorf("Expected each turn to record its filter, got %+v", turns)
+	}
+
+	session.Reset()
+	if _, err := session.Ask(context.Background(), "Fresh?"); err != nil {
+		t.Fatalf("Ask failed: %v", err)
+	}
+	if got := roles(llm.requests[len(llm.requests)-1]); got != "system,user" {
+		t.Errorf("Expected no history after Reset, got %s", got)
+	}
+}
+
+func TestSession_Ask_NoRepos(t *testing.T) {
+	llm := &mockLLM{answer: "An answer"}
+	session, err := NewService(&mockRankingRepo{}, llm).NewSession(context.Background(), domain.RankQuery{Tag: "none"}, "tag:none")
+	if err != nil {
+		t.Fatalf("NewSession failed: %v", err)
+	}
+	if _, err := session.Ask(context.Background(), "Anything?"); err == nil {
+		t.Error("Expected an error with no matching repos")
+	}
+	if len(llm.requests) != 0 {
+		t.Errorf("Expected no LLM request, got %d", len(llm.requests))
+	}
+}
@@ -14,19 +14,51 @@ import (
 type Enricher struct {
 	repo   domain.RepoRepository
 	client domain.GitHubClient
+	forges map[string]domain.ForgeClient // keyed by host
+	gate   *rateLimitGate                // nil unless waiting out rate limits
 }
 
 func NewEnricher(repo domain.RepoRepository, client domain.GitHubClient) *Enricher {
 	return &Enricher{
 		repo:   repo,
 		client: client,
+		forges: make(map[string]domain.ForgeClient),
 	}
 }
 
+// WithForgeClient registers the client used for repos hosted on 'host'.
+func (e *Enricher) WithForgeClient(host string, client domain.ForgeClient) *Enricher {
+	e.forges[host] = client
+	return e
+}
+
+// WithWaitOnRateLimit makes the enricher pause all workers until a rate limit resets
+// and then resume, instead of aborting the batch.
+func (e *Enricher) WithWaitOnRateLimit(wait bool) *Enricher {
+	if wait {
+		e.gate = newRateLimitGate()
+	} else {
+		e.gate = nil
+	}
+	return e
+}
+
+// clientFor picks the stats client for a repo host.
+func (e *Enricher) clientFor(host string) domain.ForgeClient {
+	if host == domain.GitHubHost {
+		return e.client
+	}
+	if c, ok := e.forges[host]; ok {
+		return c
+	}
+	return nil
+}
+
 type EnrichmentResult struct {
-	RepoID string
-	Status domain.EnrichmentStatus
-	Err    error
+	RepoID      string
+	Status      domain.EnrichmentStatus
+	NotModified bool // Revalidated from cache without refetching.
+	Err         error
 }
 
 func (e *Enricher) EnrichBatch(ctx context.Context, limit int, force bool, workers int, reporter domain.ProgressReporter) (int, int, error) {
@@ -50,6 +82,34 @@ func (e *Enricher) EnrichBatch(ctx context.Context, limit int, force bool, worke
 	resCh := make(chan EnrichmentResult, len(repos))
 	var wg sync.WaitGroup
 
+	// A batch-capable GitHub client takes the GitHub repos off the worker queue
+	// and fetches them a whole batch per request.
+	queue := repos
+	if batcher, ok := e.client.(domain.BatchGitHubClient); ok {
+		var githubRepos []*domain.ExtractedRepo
+		queue = nil
+		for _, repo := range repos {
+			if host, _, _, ok := domain.SplitRepoID(repo.RepoID); ok && host == domain.GitHubHost {
+				githubRepos = append(githubRepos, repo)
+			} else {
+				queue = append(queue, repo)
+			}
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			size := batcher.BatchSize()
+			for start := 0; start < len(githubRepos); start += size {
+				if ctx.Err() != nil {
+					return
+				}
+				end := min(start+size, len(githubRepos))
+				e.processGitHubBatch(ctx, batcher, githubRepos[start:end], resCh, reporter)
+			}
+		}()
+	}
+
 	// Start workers
 	for i := 0; i < workers; i++ {
 		wg.Add(1)
@@ -66,7 +126,7 @@ func (e *Enricher) EnrichBatch(ctx context.Context, limit int, force bool, worke
 	}
 
 	// Enqueue jobs
-	for _, repo := range repos {
+	for _, repo := range queue {
 		jobCh <- repo
 	}
 	close(jobCh)
@@ -78,6 +138,7 @@ func (e *Enricher) EnrichBatch(ctx context.Context, limit int, force bool, worke
 	}()
 
 	successCount := 0
+	unchangedCount := 0
 	notFoundCount := 0
 	errCount := 0
 
@@ -85,6 +146,9 @@ func (e *Enricher) EnrichBatch(ctx context.Context, limit int, force bool, worke
 		switch res.Status {
 		case domain.StatusSuccess:
 			successCount++
+			if res.NotModified {
+				unchangedCount++
+			}
 			reporter.RecordSuccess()
 		case domain.StatusNotFound:
 			notFoundCount++
@@ -96,38 +160,99 @@ func (e *Enricher) EnrichBatch(ctx context.Context, limit int, force bool, worke
 			// Ideally, processRepo returns a specific error type we can check.
 			if res.Status == domain.StatusAPIError && res.Err != nil && errors.Is(res.Err, domain.ErrRateLimitExceeded) {
 				cancel() // Stop all other workers immediately
-				reporter.Error(domain.ErrRateLimitExceeded)
-				return successCount, errCount, domain.ErrRateLimitExceeded
+				reporter.Error(res.Err)
+				return successCount, errCount, res.Err
 			}
 		}
 		reporter.Increment()
 	}
 	
-	reporter.Finish(fmt.Sprintf("Enriched: %d, Not Found: %d, Failed: %d", successCount, notFoundCount, errCount))
+	reporter.Finish(fmt.Sprintf("Enriched: %d (Unchanged: %d), Not Found: %d, Failed: %d", successCount, unchangedCount, notFoundCount, errCount))
 
 	return successCount, errCount, nil
 }
 
 func (e *Enricher) processRepo(ctx context.Context, repo *domain.ExtractedRepo, resCh chan<- EnrichmentResult, reporter domain.ProgressReporter) {
-	// Parse Owner/Repo from URL or RepoID
-	// Assuming RepoID is already "owner/repo" as per domain
-	parts := strings.Split(repo.RepoID, "/")
-	if len(parts) != 2 {
+	// Parse Host/Owner/Repo from the forge-qualified RepoID
+	host, owner, name, ok := domain.SplitRepoID(repo.RepoID)
+	if !ok {
 		err := fmt.Errorf("invalid repo id format")
 		reporter.Log(fmt.Sprintf("Skipping %s: %v", repo.RepoID, err))
 		resCh <- EnrichmentResult{RepoID: repo.RepoID, Status: domain.StatusAPIError, Err: err}
 		return
 	}
-	owner, name := parts[0], parts[1]
+
+	client := e.clientFor(host)
+	if client == nil {
+		err := fmt.Errorf("no client configured for host %s", host)
+		reporter.Log(fmt.Sprintf("Skipping %s: %v", repo.RepoID, err))
+		resCh <- EnrichmentResult{RepoID: repo.RepoID, Status: domain.StatusAPIError, Err: err}
+		return
+	}
 
 	reporter.SetStatus(fmt.Sprintf("Enriching %s", repo.RepoID))
-	stats, _, err := e.client.GetRepoStats(ctx, owner, name)
-	
+	// Revalidate with the stored ETag/Last-Modified when the client supports it.
+	conditional, canRevalidate := client.(domain.ConditionalGitHubClient)
+	canRevalidate = canRevalidate && host == domain.GitHubHost && (repo.ETag != "" || repo.LastModified != "")
+
+	var stats *domain.RepoStats
+	err := e.callWithRateLimitWait(ctx, reporter, func() error {
+		var err error
+		if canRevalidate {
+			stats, _, err = conditional.GetRepoStatsIfChanged(ctx, owner, name, repo.ETag, repo.LastModified)
+		} else {
+			stats, _, err = client.GetRepoStats(ctx, owner, name)
+		}
+		return err
+	})
+	e.recordResult(ctx, repo, stats, err, resCh, reporter)
+}
+
+// processGitHubBatch enriches a slice of GitHub repos with a single batched request.
+func (e *Enricher) processGitHubBatch(ctx context.Context, client domain.BatchGitHubClient, repos []*domain.ExtractedRepo, resCh chan<- EnrichmentResult, reporter domain.ProgressReporter) {
+	refs := make([]domain.RepoRef, len(repos))
+	for i, repo := range repos {
+		_, owner, name, _ := domain.SplitRepoID(repo.RepoID)
+		refs[i] = domain.RepoRef{Forge: domain.ForgeGitHub, Host: domain.GitHubHost, Owner: owner, Name: name}
+	}
+
+	reporter.SetStatus(fmt.Sprintf("Enriching %d repositories from GitHub", len(repos)))
+	var results map[string]domain.RepoStatsResult
+	err := e.callWithRateLimitWait(ctx, reporter, func() error {
+		var err error
+		results, _, err = client.GetRepoStatsBatch(ctx, refs)
+		return err
+	})
+	for i, repo := range repos {
+		if err != nil {
+			// The whole request failed, so every repo in the batch shares the error.
+			e.recordResult(ctx, repo, nil, err, resCh, reporter)
+			continue
+		}
+		res, ok := results[refs[i].Owner+"/"+refs[i].Name]
+		if !ok {
+			res.Err = fmt.Errorf("missing from batch response")
+		}
+		e.recordResult(ctx, repo, res.Stats, res.Err, resCh, reporter)
+	}
+}
+
+// recordResult persists the outcome of a stats lookup and reports it on resCh.
+func (e *Enricher) recordResult(ctx context.Context, repo *domain.ExtractedRepo, stats *domain.RepoStats, err error, resCh chan<- EnrichmentResult, reporter domain.ProgressReporter) {
+	// Cancelled while waiting out a rate limit: leave the repo untouched for the next run.
+	if ctx.Err() != nil && errors.Is(err, ctx.Err()) {
+		return
+	}
+
 	update := domain.RepoEnrichmentUpdate{
 		RepoID: repo.RepoID,
 	}
 
-	if err != nil {
+	if errors.Is(err, domain.ErrNotModified) {
+		update.NotModified = true
+		update.EnrichmentStatus = domain.StatusSuccess
+		err = nil
+	} else if err != nil {
 		if errors.Is(err, domain.ErrRateLimitExceeded) {
 			// Critical error, handled by orchestrator to stop
 			update.EnrichmentStatus = domain.StatusAPIError // Or keep pending?
@@ -147,6 +272,10 @@ func (e *Enricher) processRepo(ctx context.Context, repo *domain.ExtractedRepo,
 	} else {
 		update.Stats = stats
 		update.EnrichmentStatus = domain.StatusSuccess
+		if id, url, moved := canonicalRepo(repo.RepoID, stats.FullName); moved {
+			update.CanonicalID, update.CanonicalURL = id, url
+			reporter.Log(fmt.Sprintf("Repo %s was renamed or transferred to %s", repo.RepoID, id))
+		}
 	}
 
 	// Persist
@@ -156,5 +285,22 @@ func (e *Enricher) processRepo(ctx context.Context, repo *domain.ExtractedRepo,
 		return
 	}
 
-	resCh <- EnrichmentResult{RepoID: repo.RepoID, Status: update.EnrichmentStatus, Err: err}
-}
\ No newline at end of file
+	resCh <- EnrichmentResult{RepoID: repo.RepoID, Status: update.EnrichmentStatus, NotModified: update.NotModified, Err: err}
+}
+
+// canonicalRepo maps the full name a forge reports for a repo back to a repo ID on the same host,
+// reporting whether it differs from the ID the repo is stored under. Stored IDs keep the casing
+// of the URL that was first bookmarked, but forges treat owner and name case-insensitively, so a
+// difference in case alone is not a move; the repository finds the repo under either spelling.
+func canonicalRepo(repoID, fullName string) (id, url string, moved bool) {
+	host, _, _, ok := domain.SplitRepoID(repoID)
+	i := strings.LastIndex(fullName, "/")
+	if !ok || i <= 0 || i == len(fullName)-1 {
+		return "", "", false
+	}
+	id = domain.RepoRef{Host: host, Owner: fullName[:i], Name: fullName[i+1:]}.ID()
+	if strings.EqualFold(id, repoID) {
+		return "", "", false
+	}
+	return id, "https://" + host + "/" + fullName, true
+}
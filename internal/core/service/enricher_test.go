@@ -3,7 +3,10 @@ package service
 import (
 	"context"
 	"errors"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/brianluby/karakeep-extractor/internal/core/domain"
 )
@@ -15,6 +18,9 @@ type MockRepo struct {
 
 func (m *MockRepo) Save(ctx context.Context, repo domain.ExtractedRepo) error { return nil }
 func (m *MockRepo) Exists(ctx context.Context, repoID string) (bool, error)   { return true, nil }
+func (m *MockRepo) ExistingRepos(ctx context.Context, repoIDs []string) (map[string]bool, error) {
+	return nil, nil
+}
 func (m *MockRepo) GetReposForEnrichment(ctx context.Context, limit int, force bool) ([]*domain.ExtractedRepo, error) {
 	var res []*domain.ExtractedRepo
 	for _, r := range m.repos {
@@ -115,3 +121,268 @@ func TestEnricher_RateLimit(t *testing.T) {
 		t.Errorf("Expected ErrRateLimitExceeded, got %v", err)
 	}
 }
+
+func TestEnricher_RoutesToForgeClient(t *testing.T) {
+	ghRepo := &domain.ExtractedRepo{RepoID: "owner/repo1", EnrichmentStatus: domain.StatusPending}
+	glRepo := &domain.ExtractedRepo{RepoID: "gitlab.com:group/sub/project", Forge: domain.ForgeGitLab, EnrichmentStatus: domain.StatusPending}
+	unknown := &domain.ExtractedRepo{RepoID: "git.example.com:team/tool", Forge: domain.ForgeGitea, EnrichmentStatus: domain.StatusPending}
+
+	mockRepo := &MockRepo{
+		repos: map[string]*domain.ExtractedRepo{
+			ghRepo.RepoID:  ghRepo,
+			glRepo.RepoID:  glRepo,
+			unknown.RepoID: unknown,
+		},
+	}
+	ghClient := &MockClient{stats: map[string]*domain.RepoStats{"owner/repo1": {Stars: 10}}}
+	glClient := &MockClient{stats: map[string]*domain.RepoStats{"group/sub/project": {Stars: 30}}}
+
+	enricher := NewEnricher(mockRepo, ghClient).WithForgeClient("gitlab.com", glClient)
+
+	success, failed, err := enricher.EnrichBatch(context.Background(), 10, false, 1, &mockReporter{})
+	if err != nil {
+		t.Fatalf("EnrichBatch failed: %v", err)
+	}
+	if success != 2 || failed != 1 {
+		t.Errorf("Expected 2 successes and 1 failure, got %d and %d", success, failed)
+	}
+	if glRepo.Stars == nil || *glRepo.Stars != 30 {
+		t.Errorf("GitLab repo not enriched by the GitLab client")
+	}
+	// Repos on hosts without a client are skipped, not persisted, so they retry once configured.
+	if unknown.EnrichmentStatus != domain.StatusPending {
+		t.Errorf("Expected repo on unconfigured host to stay pending, got %s", unknown.EnrichmentStatus)
+	}
+}
+
+// mockBatchClient records the batches it was asked for.
+type mockBatchClient struct {
+	MockClient
+	size    int
+	batches [][]domain.RepoRef
+}
+
+func (m *mockBatchClient) BatchSize() int { return m.size }
+
+func (m *mockBatchClient) GetRepoStatsBatch(ctx context.Context, repos []domain.RepoRef) (map[string]domain.RepoStatsResult, int, error) {
+	m.batches = append(m.batches, repos)
+	results := make(map[string]domain.RepoStatsResult)
+	for _, r := range repos {
+		id := r.Owner + "/" + r.Name
+		if s, ok := m.stats[id]; ok {
+			results[id] = domain.RepoStatsResult{Stats: s}
+		} else {
+			results[id] = domain.RepoStatsResult{Err: domain.ErrRepoNotFound}
+		}
+	}
+	return results, 5000, nil
+}
+
+func TestEnricher_BatchClient(t *testing.T) {
+	repo1 := &domain.ExtractedRepo{RepoID: "owner/repo1", EnrichmentStatus: domain.StatusPending}
+	repo2 := &domain.ExtractedRepo{RepoID: "owner/repo2", EnrichmentStatus: domain.StatusPending}
+	missing := &domain.ExtractedRepo{RepoID: "owner/missing", EnrichmentStatus: domain.StatusPending}
+	mockRepo := &MockRepo{
+		repos: map[string]*domain.ExtractedRepo{
+			repo1.RepoID:   repo1,
+			repo2.RepoID:   repo2,
+			missing.RepoID: missing,
+		},
+	}
+	client := &mockBatchClient{
+		MockClient: MockClient{stats: map[string]*domain.RepoStats{
+			"owner/repo1": {Stars: 10},
+			"owner/repo2": {Stars: 20},
+		}},
+		size: 2,
+	}
+
+	enricher := NewEnricher(mockRepo, client)
+	success, failed, err := enricher.EnrichBatch(context.Background(), 10, false, 2, &mockReporter{})
+	if err != nil {
+		t.Fatalf("EnrichBatch failed: %v", err)
+	}
+	if success != 2 || failed != 0 {
+		t.Errorf("Expected 2 successes and 0 failures, got %d and %d", success, failed)
+	}
+	if len(client.batches) != 2 {
+		t.Errorf("Expected 3 repos split into 2 batches, got %d", len(client.batches))
+	}
+	if missing.EnrichmentStatus != domain.StatusNotFound {
+		t.Errorf("Expected missing repo to be NOT_FOUND, got %s", missing.EnrichmentStatus)
+	}
+}
+
+// flakyRateLimitClient is rate limited until its first call's reset time has passed.
+type flakyRateLimitClient struct {
+	mu      sync.Mutex
+	resetAt time.Time
+	calls   int
+}
+
+func (c *flakyRateLimitClient) GetRepoStats(ctx context.Context, owner, repo string) (*domain.RepoStats, int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls++
+	if c.resetAt.IsZero() {
+		c.resetAt = time.Now().Add(50 * time.Millisecond)
+	}
+	if time.Now().Before(c.resetAt) {
+		return nil, 0, &domain.RateLimitError{ResetAt: c.resetAt}
+	}
+	return &domain.RepoStats{Stars: 1}, 4999, nil
+}
+
+// statusRecorder captures SetStatus messages.
+type statusRecorder struct {
+	mockReporter
+	mu       sync.Mutex
+	statuses []string
+}
+
+func (r *statusRecorder) SetStatus(status string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.statuses = append(r.statuses, status)
+}
+
+func TestEnricher_WaitOnRateLimit(t *testing.T) {
+	mockRepo := &MockRepo{repos: map[string]*domain.ExtractedRepo{}}
+	for _, id := range []string{"owner/a", "owner/b", "owner/c"} {
+		mockRepo.repos[id] = &domain.ExtractedRepo{RepoID: id, EnrichmentStatus: domain.StatusPending}
+	}
+	client := &flakyRateLimitClient{}
+	reporter := &statusRecorder{}
+
+	enricher := NewEnricher(mockRepo, client).WithWaitOnRateLimit(true)
+	success, failed, err := enricher.EnrichBatch(context.Background(), 10, false, 1, reporter)
+	if err != nil {
+		t.Fatalf("Expected batch to wait out the rate limit, got %v", err)
+	}
+	if success != 3 || failed != 0 {
+		t.Errorf("Expected 3 successes and 0 failures, got %d and %d", success, failed)
+	}
+
+	countdown := false
+	for _, s := range reporter.statuses {
+		if strings.HasPrefix(s, "Rate limited, resuming in") {
+			countdown = true
+		}
+	}
+	if !countdown {
+		t.Errorf("Expected a countdown status, got %v", reporter.statuses)
+	}
+}
+
+// conditionalClient answers 304 for repos whose ETag matches.
+type conditionalClient struct {
+	MockClient
+	etags map[string]string
+}
+
+func (c *conditionalClient) GetRepoStatsIfChanged(ctx context.Context, owner, repo, etag, lastModified string) (*domain.RepoStats, int, error) {
+	if etag != "" && c.etags[owner+"/"+repo] == etag {
+		return nil, 5000, domain.ErrNotModified
+	}
+	return c.GetRepoStats(ctx, owner, repo)
+}
+
+func TestEnricher_ConditionalRequests(t *testing.T) {
+	cached := &domain.ExtractedRepo{RepoID: "owner/cached", ETag: `"v1"`, EnrichmentStatus: domain.StatusSuccess}
+	changed := &domain.ExtractedRepo{RepoID: "owner/changed", ETag: `"old"`, EnrichmentStatus: domain.StatusSuccess}
+	mockRepo := &notModifiedRepo{MockRepo: MockRepo{repos: map[string]*domain.ExtractedRepo{
+		cached.RepoID:  cached,
+		changed.RepoID: changed,
+	}}}
+	client := &conditionalClient{
+		MockClient: MockClient{stats: map[string]*domain.RepoStats{
+			"owner/cached":  {Stars: 1},
+			"owner/changed": {Stars: 2},
+		}},
+		etags: map[string]string{"owner/cached": `"v1"`, "owner/changed": `"v2"`},
+	}
+
+	enricher := NewEnricher(mockRepo, client)
+	success, failed, err := enricher.EnrichBatch(context.Background(), 10, true, 1, &mockReporter{})
+	if err != nil {
+		t.Fatalf("EnrichBatch failed: %v", err)
+	}
+	if success != 2 || failed != 0 {
+		t.Errorf("Expected 2 successes and 0 failures, got %d and %d", success, failed)
+	}
+	if len(mockRepo.notModified) != 1 || mockRepo.notModified[0] != "owner/cached" {
+		t.Errorf("Expected only owner/cached to be recorded as not modified, got %v", mockRepo.notModified)
+	}
+	if changed.Stars == nil || *changed.Stars != 2 {
+		t.Errorf("Expected owner/changed to be refetched")
+	}
+}
+
+// notModifiedRepo records NotModified updates.
+type notModifiedRepo struct {
+	MockRepo
+	notModified []string
+}
+
+func (m *notModifiedRepo) UpdateRepoEnrichment(ctx context.Context, update domain.RepoEnrichmentUpdate) error {
+	if update.NotModified {
+		m.notModified = append(m.notModified, update.RepoID)
+	}
+	return m.MockRepo.UpdateRepoEnrichment(ctx, update)
+}
+
+// movedRepo records the canonical IDs reported in updates.
+type movedRepo struct {
+	MockRepo
+	moves map[string]string
+}
+
+func (m *movedRepo) UpdateRepoEnrichment(ctx context.Context, update domain.RepoEnrichmentUpdate) error {
+	if update.CanonicalID != "" {
+		m.moves[update.RepoID] = update.CanonicalID + " " + update.CanonicalURL
+	}
+	return m.MockRepo.UpdateRepoEnrichment(ctx, update)
+}
+
+func TestEnricher_RenamedRepos(t *testing.T) {
+	mockRepo := &movedRepo{
+		MockRepo: MockRepo{repos: map[string]*domain.ExtractedRepo{
+			"old/name":  {RepoID: "old/name"},
+			"same/name": {RepoID: "same/name"},
+		}},
+		moves: map[string]string{},
+	}
+	client := &MockClient{stats: map[string]*domain.RepoStats{
+		"old/name":  {Stars: 1, FullName: "New-Owner/name"},
+		"same/name": {Stars: 2, FullName: "same/name"},
+	}}
+
+	if _, _, err := NewEnricher(mockRepo, client).EnrichBatch(context.Background(), 10, false, 1, &mockReporter{}); err != nil {
+		t.Fatalf("EnrichBatch failed: %v", err)
+	}
+	if len(mockRepo.moves) != 1 || mockRepo.moves["old/name"] != "New-Owner/name https://github.com/New-Owner/name" {
+		t.Errorf("Expected only old/name to move, got %v", mockRepo.moves)
+	}
+}
+
+func TestCanonicalRepo(t *testing.T) {
+	tests := []struct {
+		repoID, fullName string
+		wantID           string
+		wantURL          string
+		wantMoved        bool
+	}{
+		{"owner/name", "owner/name", "", "", false},
+		{"owner/name", "", "", "", false},
+		{"owner/name", "Owner/Name", "", "", false},
+		{"burntsushi/ripgrep", "BurntSushi/ripgrep", "", "", false},
+		{"owner/name", "New-Owner/name", "New-Owner/name", "https://github.com/New-Owner/name", true},
+		{"gitlab.com:group/project", "group/renamed", "gitlab.com:group/renamed", "https://gitlab.com/group/renamed", true},
+	}
+	for _, tc := range tests {
+		id, url, moved := canonicalRepo(tc.repoID, tc.fullName)
+		if id != tc.wantID || url != tc.wantURL || moved != tc.wantMoved {
+			t.Errorf("canonicalRepo(%q, %q) = %q, %q, %v; want %q, %q, %v", tc.repoID, tc.fullName, id, url, moved, tc.wantID, tc.wantURL, tc.wantMoved)
+		}
+	}
+}
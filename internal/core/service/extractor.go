@@ -11,10 +11,16 @@ import (
 	"github.com/brianluby/karakeep-extractor/internal/core/domain"
 )
 
+// SyncSourceKarakeep is the sync-state key used for the Karakeep bookmark source.
+const SyncSourceKarakeep = "karakeep"
+
 // Extractor orchestrates the bookmark fetching, filtering, and saving process.
 type Extractor struct {
 	Source     domain.BookmarkSource
 	Repository domain.RepoRepository
+	SyncState  domain.SyncStateRepository // Optional; enables incremental extraction.
+	Reconciler domain.BookmarkReconciler  // Optional; flags repos whose bookmarks were deleted or archived.
+	Detectors  []ForgeDetector            // Recognise repository URLs per forge.
 }
 
 // NewExtractor creates a new Extractor service.
@@ -22,13 +28,53 @@ func NewExtractor(source domain.BookmarkSource, repository domain.RepoRepository
 	return &Extractor{
 		Source:     source,
 		Repository: repository,
+		Detectors:  DefaultForgeDetectors(nil),
 	}
 }
 
-// Extract fetches bookmarks, filters for GitHub repos, normalizes URLs, and saves them.
-func (e *Extractor) Extract(ctx context.Context, reporter domain.ProgressReporter) error {
-	reporter.SetStatus("Fetching all bookmarks...")
-	bookmarks, err := e.Source.FetchBookmarks(ctx)
+// WithDetectors replaces the forge detectors, e.g. to add self-hosted instances.
+func (e *Extractor) WithDetectors(detectors []ForgeDetector) *Extractor {
+	e.Detectors = detectors
+	return e
+}
+
+// WithSyncState enables incremental extraction using the given checkpoint store.
+func (e *Extractor) WithSyncState(state domain.SyncStateRepository) *Extractor {
+	e.SyncState = state
+	return e
+}
+
+// WithReconciler enables reconciliation of stored repos with the bookmarks that still exist.
+// Incremental runs reconcile only if the source is also a domain.BookmarkStateSource.
+func (e *Extractor) WithReconciler(reconciler domain.BookmarkReconciler) *Extractor {
+	e.Reconciler = reconciler
+	return e
+}
+
+// Extract fetches bookmarks, filters for repository links on known forges, normalizes URLs, and saves them.
+// Unless 'full' is set, only bookmarks newer than the stored sync checkpoint are fetched.
+func (e *Extractor) Extract(ctx context.Context, full bool, reporter domain.ProgressReporter) error {
+	runStartedAt := time.Now()
+
+	var checkpoint *domain.SyncState
+	if e.SyncState != nil {
+		state, err := e.SyncState.GetSyncState(ctx, SyncSourceKarakeep)
+		if err != nil {
+			reporter.Error(err)
+			return fmt.Errorf("failed to load sync state: %w", err)
+		}
+		checkpoint = state
+	}
+
+	var since time.Time
+	if !full && checkpoint != nil && !checkpoint.LastBookmarkAt.IsZero() {
+		since = checkpoint.LastBookmarkAt
+		reporter.SetStatus(fmt.Sprintf("Fetching bookmarks changed since %s...", since.Format(time.RFC3339)))
+	} else {
+		reporter.SetStatus("Fetching all bookmarks...")
+	}
+
+	bookmarks, states, err := e.fetch(ctx, since)
 	if err != nil {
 		reporter.Error(err)
 		return fmt.Errorf("failed to fetch all bookmarks: %w", err)
@@ -36,47 +82,43 @@ func (e *Extractor) Extract(ctx context.Context, reporter domain.ProgressReporte
 
 	if len(bookmarks) == 0 {
 		reporter.Log("No bookmarks found.")
+		// An empty full listing is more likely an API problem than every bookmark being deleted.
+		if !since.IsZero() {
+			if err := e.reconcile(ctx, since, nil, states, reporter); err != nil {
+				reporter.Error(err)
+				return err
+			}
+		}
+		if err := e.saveCheckpoint(ctx, checkpoint, nil, runStartedAt); err != nil {
+			reporter.Error(err)
+			return err
+		}
 		reporter.Finish("Extraction complete: 0 new repositories.")
 		return nil
 	}
 	
-	reporter.Start(len(bookmarks), "Processing bookmarks")
-	extractedCount := 0
-	
-	// Regex to find potential links in text (simplified)
-	linkRegex := regexp.MustCompile(`https?://github\.com/[a-zA-Z0-9._-]+/[a-zA-Z0-9._-]+`)
-
-	for _, bm := range bookmarks {
-		// Candidate URLs: Main URL + any found in HTML content
-		candidates := []string{bm.Content.URL}
-		
-		if bm.Content.HTMLContent != "" {
-			matches := linkRegex.FindAllString(bm.Content.HTMLContent, -1)
-			candidates = append(candidates, matches...)
+	// Detect every bookmark's repos first, so that existence is checked in one batch.
+	detected := make([]map[string]repoCandidate, len(bookmarks))
+	var repoIDs []string
+	for i, bm := range bookmarks {
+		detected[i] = e.detectRepos(bm)
+		for repoID := range detected[i] {
+			repoIDs = append(repoIDs, repoID)
 		}
+	}
+	known, err := e.Repository.ExistingRepos(ctx, repoIDs)
+	if err != nil {
+		reporter.Error(err)
+		return fmt.Errorf("failed to check existing repositories: %w", err)
+	}
 
-		// Deduplicate candidates for this bookmark to avoid processing same repo twice
-		uniqueRepos := make(map[string]string) // normalizedID -> originalURL
+	reporter.Start(len(bookmarks), "Processing bookmarks")
+	extractedCount := 0
+	failedCount := 0
 
+	for i, bm := range bookmarks {
 		foundNew := false
-		for _, rawURL := range candidates {
-			normalizedRepoID, isGitHub := NormalizeGitHubURL(rawURL)
-			if !isGitHub || normalizedRepoID == "" {
-				continue
-			}
-			uniqueRepos[normalizedRepoID] = rawURL
-		}
-
-		for normalizedRepoID, originalURL := range uniqueRepos {
-			exists, err := e.Repository.Exists(ctx, normalizedRepoID)
-			if err != nil {
-				reporter.Log(fmt.Sprintf("Error checking existence for %s: %v", normalizedRepoID, err))
-				continue
-			}
-			if exists {
-				continue
-			}
-
+		for normalizedRepoID, found := range detected[i] {
 			// Determine Title (Use bookmark title, or fallback to repo ID if finding multiple?)
 			title := bm.Content.Title
 			if bm.Title != nil && *bm.Title != "" {
@@ -84,18 +126,28 @@ func (e *Extractor) Extract(ctx context.Context, reporter domain.ProgressReporte
 			}
 
 			repo := domain.ExtractedRepo{
-				RepoID:   normalizedRepoID,
-				URL:      originalURL,
-				SourceID: bm.ID,
-				Title:    title,
-				FoundAt:  time.Now(),
+				RepoID:           normalizedRepoID,
+				URL:              found.url,
+				Forge:            found.forge,
+				SourceID:         bm.ID,
+				LinkKind:         found.kind,
+				Title:            title,
+				FoundAt:          time.Now(),
+				BookmarkArchived: bm.Archived,
+				Tags:             bm.Tags,
 			}
 
 			if err := e.Repository.Save(ctx, repo); err != nil {
 				reporter.Log(fmt.Sprintf("Error saving repo %s: %v", normalizedRepoID, err))
 				reporter.RecordFailure()
+				failedCount++
+				continue
+			}
+			// Known repos are saved again only to link this bookmark and merge its tags.
+			if known[normalizedRepoID] {
 				continue
 			}
+			known[normalizedRepoID] = true
 			extractedCount++
 			foundNew = true
 		}
@@ -107,10 +159,138 @@ func (e *Extractor) Extract(ctx context.Context, reporter domain.ProgressReporte
 		}
 		reporter.Increment()
 	}
+
+	if err := e.reconcile(ctx, since, bookmarks, states, reporter); err != nil {
+		reporter.Error(err)
+		return err
+	}
+
+	// Don't move the checkpoint past bookmarks we failed to store; they are retried next run.
+	if failedCount > 0 {
+		reporter.Log(fmt.Sprintf("%d repositories failed to save; sync checkpoint not advanced.", failedCount))
+		bookmarks = nil
+	}
+	if err := e.saveCheckpoint(ctx, checkpoint, bookmarks, runStartedAt); err != nil {
+		reporter.Error(err)
+		return err
+	}
+
 	reporter.Finish(fmt.Sprintf("Extraction complete: %d new repositories found.", extractedCount))
 	return nil
 }
 
+// linkRegex finds potential links in text (simplified).
+// Forge detection decides which of these are repositories.
+var linkRegex = regexp.MustCompile(`https?://[^\s"'<>()\[\]]+`)
+
+// repoCandidate is where a repository was first found in a bookmark.
+type repoCandidate struct {
+	url   string
+	forge domain.Forge
+	kind  domain.LinkKind
+}
+
+// detectRepos returns the repositories a bookmark links to, keyed by normalized ID: its own
+// URL plus any links found in its HTML content.
+func (e *Extractor) detectRepos(bm domain.RawBookmark) map[string]repoCandidate {
+	candidates := []string{bm.Content.URL}
+	if bm.Content.HTMLContent != "" {
+		for _, match := range linkRegex.FindAllString(bm.Content.HTMLContent, -1) {
+			candidates = append(candidates, strings.TrimRight(match, ".,;:!?"))
+		}
+	}
+
+	// Deduplicate candidates for this bookmark to avoid processing same repo twice
+	uniqueRepos := make(map[string]repoCandidate)
+	for i, rawURL := range candidates {
+		ref, ok := DetectRepo(e.Detectors, rawURL)
+		if !ok {
+			continue
+		}
+		if _, seen := uniqueRepos[ref.ID()]; seen {
+			continue
+		}
+		kind := domain.LinkContent
+		if i == 0 {
+			kind = domain.LinkPrimary
+		}
+		uniqueRepos[ref.ID()] = repoCandidate{url: rawURL, forge: ref.Forge, kind: kind}
+	}
+	return uniqueRepos
+}
+
+// fetch fetches the bookmarks changed since 'since'. When reconciling with a source that walks
+// every bookmark anyway, it also returns their states; otherwise the states are nil.
+func (e *Extractor) fetch(ctx context.Context, since time.Time) ([]domain.RawBookmark, []domain.BookmarkState, error) {
+	if source, ok := e.Source.(domain.BookmarkStateSource); ok && e.Reconciler != nil {
+		return source.FetchBookmarksWithStates(ctx, since)
+	}
+	bookmarks, err := e.Source.FetchBookmarks(ctx, since)
+	return bookmarks, nil, err
+}
+
+// reconcile marks repos whose bookmarks disappeared and refreshes archived state. Deletions can
+// only be detected from every bookmark: a full run has fetched them all, and an incremental run
+// needs the states its source listed while fetching.
+func (e *Extractor) reconcile(ctx context.Context, since time.Time, bookmarks []domain.RawBookmark, states []domain.BookmarkState, reporter domain.ProgressReporter) error {
+	if e.Reconciler == nil {
+		return nil
+	}
+
+	if states == nil {
+		if !since.IsZero() {
+			reporter.Log("Skipping reconciliation of deleted and archived bookmarks; run 'extract --full' to reconcile.")
+			return nil
+		}
+		states = make([]domain.BookmarkState, 0, len(bookmarks))
+		for _, bm := range bookmarks {
+			states = append(states, domain.BookmarkState{ID: bm.ID, Archived: bm.Archived})
+		}
+	}
+	if len(states) == 0 {
+		reporter.Log("Karakeep listed no bookmarks; skipping reconciliation.")
+		return nil
+	}
+
+	reporter.SetStatus("Reconciling deleted and archived bookmarks...")
+
+	result, err := e.Reconciler.ReconcileBookmarks(ctx, states)
+	if err != nil {
+		return fmt.Errorf("failed to reconcile bookmarks: %w", err)
+	}
+	reporter.Log(fmt.Sprintf("Reconciled: %d newly orphaned, %d restored, %d archived.", result.Orphaned, result.Restored, result.Archived))
+	return nil
+}
+
+// saveCheckpoint advances the sync state to the newest bookmark processed in this run.
+func (e *Extractor) saveCheckpoint(ctx context.Context, previous *domain.SyncState, bookmarks []domain.RawBookmark, runAt time.Time) error {
+	if e.SyncState == nil {
+		return nil
+	}
+
+	state := domain.SyncState{Source: SyncSourceKarakeep, LastRunAt: runAt}
+	if previous != nil {
+		state.LastBookmarkID = previous.LastBookmarkID
+		state.LastBookmarkAt = previous.LastBookmarkAt
+	}
+
+	for _, bm := range bookmarks {
+		seenAt := bm.CreatedAt
+		if bm.ModifiedAt != nil && bm.ModifiedAt.After(seenAt) {
+			seenAt = *bm.ModifiedAt
+		}
+		if seenAt.After(state.LastBookmarkAt) {
+			state.LastBookmarkAt = seenAt
+			state.LastBookmarkID = bm.ID
+		}
+	}
+
+	if err := e.SyncState.SaveSyncState(ctx, state); err != nil {
+		return fmt.Errorf("failed to save sync state: %w", err)
+	}
+	return nil
+}
+
 var githubDomainRegex = regexp.MustCompile(`^(www\.)?github\.com$`)
 var repoPathRegex = regexp.MustCompile(`^/?([^/]+)/([^/]+)`) // Matches /owner/repo
 
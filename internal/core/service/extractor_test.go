@@ -2,8 +2,8 @@ package service_test
 
 import (
 	"context"
-	"fmt"
 	"testing"
+	"time"
 
 	"github.com/brianluby/karakeep-extractor/internal/core/domain"
 	"github.com/brianluby/karakeep-extractor/internal/core/service"
@@ -13,9 +13,11 @@ import (
 type mockBookmarkSource struct {
 	bookmarks [][]domain.RawBookmark
 	callCount int
+	since     time.Time
 }
 
-func (m *mockBookmarkSource) FetchBookmarks(ctx context.Context) ([]domain.RawBookmark, error) {
+func (m *mockBookmarkSource) FetchBookmarks(ctx context.Context, since time.Time) ([]domain.RawBookmark, error) {
+	m.since = since
 	if m.callCount >= len(m.bookmarks) {
 		return nil, nil // No more pages (shouldn't happen with non-paginated API)
 	}
@@ -26,7 +28,14 @@ func (m *mockBookmarkSource) FetchBookmarks(ctx context.Context) ([]domain.RawBo
 
 // MockRepoRepository for testing extractor service
 type mockRepoRepository struct {
-	repos map[string]domain.ExtractedRepo
+	repos           map[string]domain.ExtractedRepo
+	links           []bookmarkLink
+	existenceChecks int
+}
+
+type bookmarkLink struct {
+	bookmarkID, repoID string
+	kind               domain.LinkKind
 }
 
 func newMockRepoRepository() *mockRepoRepository {
@@ -36,8 +45,12 @@ func newMockRepoRepository() *mockRepoRepository {
 }
 
 func (m *mockRepoRepository) Save(ctx context.Context, repo domain.ExtractedRepo) error {
-	if _, exists := m.repos[repo.RepoID]; exists {
-		return fmt.Errorf("duplicate RepoID: %s", repo.RepoID) // Simulate unique constraint
+	m.links = append(m.links, bookmarkLink{bookmarkID: repo.SourceID, repoID: repo.RepoID, kind: repo.LinkKind})
+	if existing, exists := m.repos[repo.RepoID]; exists {
+		// Re-saving an existing repo only links the bookmark and adds its tags.
+		existing.Tags = append(existing.Tags, repo.Tags...)
+		m.repos[repo.RepoID] = existing
+		return nil
 	}
 	m.repos[repo.RepoID] = repo
 	return nil
@@ -48,6 +61,17 @@ func (m *mockRepoRepository) Exists(ctx context.Context, repoID string) (bool, e
 	return exists, nil
 }
 
+func (m *mockRepoRepository) ExistingRepos(ctx context.Context, repoIDs []string) (map[string]bool, error) {
+	m.existenceChecks++
+	existing := make(map[string]bool)
+	for _, id := range repoIDs {
+		if _, exists := m.repos[id]; exists {
+			existing[id] = true
+		}
+	}
+	return existing, nil
+}
+
 func (m *mockRepoRepository) GetReposForEnrichment(ctx context.Context, limit int, force bool) ([]*domain.ExtractedRepo, error) {
 	return nil, nil
 }
@@ -182,7 +206,7 @@ func TestExtractService_Extract(t *testing.T) {
 			mockRepo := newMockRepoRepository()
 			extractor := service.NewExtractor(mockSource, mockRepo)
 
-			err := extractor.Extract(context.Background(), &mockReporter{})
+			err := extractor.Extract(context.Background(), false, &mockReporter{})
 			if tc.expectedError && err == nil {
 				t.Errorf("Expected an error but got none")
 			}
@@ -275,7 +299,7 @@ func TestExtractService_Extract_FullPagination(t *testing.T) {
 	mockRepo := newMockRepoRepository()
 	extractor := service.NewExtractor(mockSource, mockRepo)
 
-	err := extractor.Extract(context.Background(), &mockReporter{})
+	err := extractor.Extract(context.Background(), false, &mockReporter{})
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
@@ -292,4 +316,191 @@ func TestExtractService_Extract_FullPagination(t *testing.T) {
 	if _, ok := mockRepo.repos["e/f"]; !ok {
 		t.Errorf("Repo e/f not found")
 	}
-}
\ No newline at end of file
+}
+// mockSyncState for testing incremental extraction
+type mockSyncState struct {
+	state *domain.SyncState
+	saved []domain.SyncState
+}
+
+func (m *mockSyncState) GetSyncState(ctx context.Context, source string) (*domain.SyncState, error) {
+	return m.state, nil
+}
+
+func (m *mockSyncState) SaveSyncState(ctx context.Context, state domain.SyncState) error {
+	m.saved = append(m.saved, state)
+	return nil
+}
+
+func TestExtractService_Extract_Incremental(t *testing.T) {
+	checkpoint := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	newer := checkpoint.Add(time.Hour)
+
+	bm := domain.RawBookmark{ID: "42", CreatedAt: newer}
+	bm.Content.URL = "https://github.com/new/repo"
+
+	mockSource := &mockBookmarkSource{bookmarks: [][]domain.RawBookmark{{bm}, {bm}}}
+	state := &mockSyncState{state: &domain.SyncState{Source: service.SyncSourceKarakeep, LastBookmarkID: "41", LastBookmarkAt: checkpoint}}
+	extractor := service.NewExtractor(mockSource, newMockRepoRepository()).WithSyncState(state)
+
+	// Incremental run passes the checkpoint to the source and advances it.
+	if err := extractor.Extract(context.Background(), false, &mockReporter{}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !mockSource.since.Equal(checkpoint) {
+		t.Errorf("Expected since %v, got %v", checkpoint, mockSource.since)
+	}
+	if len(state.saved) != 1 {
+		t.Fatalf("Expected checkpoint to be saved once, got %d", len(state.saved))
+	}
+	if !state.saved[0].LastBookmarkAt.Equal(newer) || state.saved[0].LastBookmarkID != "42" {
+		t.Errorf("Expected checkpoint to advance to bookmark 42 at %v, got %+v", newer, state.saved[0])
+	}
+
+	// Full run ignores the checkpoint.
+	if err := extractor.Extract(context.Background(), true, &mockReporter{}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !mockSource.since.IsZero() {
+		t.Errorf("Expected full sync to fetch without since, got %v", mockSource.since)
+	}
+}
+
+// mockReconciler records reconciliation calls
+type mockReconciler struct {
+	calls  int
+	states []domain.BookmarkState
+}
+
+func (m *mockReconciler) ReconcileBookmarks(ctx context.Context, bookmarks []domain.BookmarkState) (domain.ReconcileResult, error) {
+	m.calls++
+	m.states = bookmarks
+	return domain.ReconcileResult{}, nil
+}
+
+func TestExtractService_Extract_Reconcile(t *testing.T) {
+	bm := domain.RawBookmark{ID: "1", Archived: true, CreatedAt: time.Now()}
+	bm.Content.URL = "https://github.com/owner/repo"
+
+	mockSource := &mockBookmarkSource{bookmarks: [][]domain.RawBookmark{{bm}, {bm}}}
+	mockRepo := newMockRepoRepository()
+	state := &mockSyncState{}
+	reconciler := &mockReconciler{}
+	extractor := service.NewExtractor(mockSource, mockRepo).WithSyncState(state).WithReconciler(reconciler)
+
+	// First run has no checkpoint, so it is a full sync and reconciles.
+	if err := extractor.Extract(context.Background(), false, &mockReporter{}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if reconciler.calls != 1 {
+		t.Fatalf("Expected 1 reconcile call, got %d", reconciler.calls)
+	}
+	if len(reconciler.states) != 1 || !reconciler.states[0].Archived {
+		t.Errorf("Expected archived bookmark state, got %+v", reconciler.states)
+	}
+	if !mockRepo.repos["owner/repo"].BookmarkArchived {
+		t.Errorf("Expected saved repo to be flagged archived")
+	}
+
+	// Incremental runs only see changed bookmarks; without a way to list them all, they skip reconcile.
+	state.state = &state.saved[0]
+	if err := extractor.Extract(context.Background(), false, &mockReporter{}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if reconciler.calls != 1 {
+		t.Errorf("Expected incremental run to skip reconcile, got %d calls", reconciler.calls)
+	}
+}
+
+// mockStateSource also returns every bookmark's state from the same fetch.
+type mockStateSource struct {
+	mockBookmarkSource
+	states  []domain.BookmarkState
+	fetches int
+}
+
+func (m *mockStateSource) FetchBookmarksWithStates(ctx context.Context, since time.Time) ([]domain.RawBookmark, []domain.BookmarkState, error) {
+	m.fetches++
+	bookmarks, err := m.FetchBookmarks(ctx, since)
+	return bookmarks, m.states, err
+}
+
+func TestExtractService_Extract_ReconcileIncremental(t *testing.T) {
+	source := &mockStateSource{states: []domain.BookmarkState{{ID: "1"}, {ID: "2", Archived: true}}}
+	reconciler := &mockReconciler{}
+	state := &mockSyncState{state: &domain.SyncState{Source: service.SyncSourceKarakeep, LastBookmarkAt: time.Now().Add(-time.Hour)}}
+	extractor := service.NewExtractor(source, newMockRepoRepository()).WithSyncState(state).WithReconciler(reconciler)
+
+	// Nothing changed since the checkpoint, but deletions and archiving are still reconciled
+	// from the states listed by the one fetch.
+	if err := extractor.Extract(context.Background(), false, &mockReporter{}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if source.fetches != 1 {
+		t.Errorf("Expected a single fetch, got %d", source.fetches)
+	}
+	if reconciler.calls != 1 || len(reconciler.states) != 2 || !reconciler.states[1].Archived {
+		t.Errorf("Expected the listed states to be reconciled, got %d calls with %+v", reconciler.calls, reconciler.states)
+	}
+}
+
+func TestExtractService_Extract_Tags(t *testing.T) {
+	first := domain.RawBookmark{ID: "1", Tags: []domain.Tag{{Name: "rust", AttachedBy: domain.TagSourceHuman}}}
+	first.Content.URL = "https://github.com/owner/repo"
+	second := domain.RawBookmark{ID: "2", Tags: []domain.Tag{{Name: "cli", AttachedBy: domain.TagSourceAI}}}
+	second.Content.URL = "https://github.com/owner/repo"
+
+	mockSource := &mockBookmarkSource{bookmarks: [][]domain.RawBookmark{{first, second}}}
+	mockRepo := newMockRepoRepository()
+	extractor := service.NewExtractor(mockSource, mockRepo)
+
+	if err := extractor.Extract(context.Background(), false, &mockReporter{}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// The second bookmark's tags are merged into the repo found by the first.
+	tags := mockRepo.repos["owner/repo"].Tags
+	if len(tags) != 2 {
+		t.Fatalf("Expected 2 tags, got %+v", tags)
+	}
+	if tags[0] != (domain.Tag{Name: "rust", AttachedBy: domain.TagSourceHuman}) || tags[1] != (domain.Tag{Name: "cli", AttachedBy: domain.TagSourceAI}) {
+		t.Errorf("Unexpected tags: %+v", tags)
+	}
+}
+
+func TestExtractService_Extract_BookmarkLinks(t *testing.T) {
+	article := domain.RawBookmark{ID: "1"}
+	article.Content.URL = "https://github.com/a/b"
+	article.Content.HTMLContent = `See <a href="https://github.com/c/d">c/d</a> and https://github.com/a/b again.`
+	direct := domain.RawBookmark{ID: "2"}
+	direct.Content.URL = "https://github.com/c/d"
+
+	mockSource := &mockBookmarkSource{bookmarks: [][]domain.RawBookmark{{article, direct}}}
+	mockRepo := newMockRepoRepository()
+	extractor := service.NewExtractor(mockSource, mockRepo)
+
+	if err := extractor.Extract(context.Background(), false, &mockReporter{}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(mockRepo.repos) != 2 {
+		t.Fatalf("Expected 2 repos, got %d", len(mockRepo.repos))
+	}
+	if mockRepo.existenceChecks != 1 {
+		t.Errorf("Expected one batched existence check, got %d", mockRepo.existenceChecks)
+	}
+
+	// Every bookmark is linked, including the second one referencing an already-known repo.
+	want := map[bookmarkLink]bool{
+		{bookmarkID: "1", repoID: "a/b", kind: domain.LinkPrimary}: true,
+		{bookmarkID: "1", repoID: "c/d", kind: domain.LinkContent}: true,
+		{bookmarkID: "2", repoID: "c/d", kind: domain.LinkPrimary}: true,
+	}
+	if len(mockRepo.links) != len(want) {
+		t.Fatalf("Expected %d links, got %+v", len(want), mockRepo.links)
+	}
+	for _, link := range mockRepo.links {
+		if !want[link] {
+			t.Errorf("Unexpected link %+v", link)
+		}
+	}
+}
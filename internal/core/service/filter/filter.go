@@ -0,0 +1,276 @@
+// Package filter parses the --where query language shared by rank and analyze, e.g.
+//
+//	lang:go stars:>1000 pushed:>2024-01-01 tag:cli -tag:archived
+//
+// A query is a space-separated list of terms that must all match. A term is "field:value",
+// optionally prefixed with "-" to negate it; a bare word matches the repo name, bookmark title
+// or description. Values containing spaces are double-quoted: tag:"machine learning".
+// Numeric and date fields take a comparison (>, >=, <, <=) or an inclusive range (10..100,
+// 2024-01-01..2024-06-30, 100..*).
+package filter
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/brianluby/karakeep-extractor/internal/core/domain"
+)
+
+type fieldKind int
+
+const (
+	kindText fieldKind = iota
+	kindNumber
+	kindDate
+	kindFlag
+)
+
+var fields = map[string]struct {
+	field domain.FilterField
+	kind  fieldKind
+}{
+	"lang":      {domain.FilterLang, kindText},
+	"language":  {domain.FilterLang, kindText},
+	"stars":     {domain.FilterStars, kindNumber},
+	"forks":     {domain.FilterForks, kindNumber},
+	"issues":    {domain.FilterIssues, kindNumber},
+	"bookmarks": {domain.FilterBookmarks, kindNumber},
+	"size":      {domain.FilterSize, kindNumber},
+	"pushed":    {domain.FilterPushed, kindDate},
+	"created":   {domain.FilterCreated, kindDate},
+	"tag":       {domain.FilterTag, kindText},
+	"topic":     {domain.FilterTopic, kindText},
+	"license":   {domain.FilterLicense, kindText},
+	"forge":     {domain.FilterForge, kindText},
+	"is":        {domain.FilterIs, kindFlag},
+}
+
+var flags = []string{domain.FilterIsArchived, domain.FilterIsFork}
+
+const dateLayout = "2006-01-02"
+
+// Error is a parse error, pointing at the offending part of the input.
+type Error struct {
+	Input string
+	Pos   int // Byte offset of the bad token in Input.
+	Len   int // Byte length of the bad token.
+	Msg   string
+}
+
+// Error renders the message followed by the input with the bad token underlined.
+func (e *Error) Error() string {
+	col := utf8.RuneCountInString(e.Input[:e.Pos])
+	width := max(utf8.RuneCountInString(e.Input[e.Pos:e.Pos+e.Len]), 1)
+	return fmt.Sprintf("%s at column %d\n  %s\n  %s%s", e.Msg, col+1, e.Input, strings.Repeat(" ", col), strings.Repeat("^", width))
+}
+
+// token is one whitespace-separated word of the input, with quotes removed.
+type token struct {
+	text string
+	pos  int // Byte offset in the input.
+	raw  string
+}
+
+// Parse parses a --where query. An empty query yields no terms.
+func Parse(input string) ([]domain.FilterTerm, error) {
+	tokens, err := tokenize(input)
+	if err != nil {
+		return nil, err
+	}
+
+	terms := make([]domain.FilterTerm, 0, len(tokens))
+	for _, tok := range tokens {
+		term, err := parseTerm(input, tok)
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, term)
+	}
+	return terms, nil
+}
+
+func tokenize(input string) ([]token, error) {
+	var tokens []token
+	i := 0
+	for i < len(input) {
+		if input[i] == ' ' || input[i] == '\t' || input[i] == '\n' {
+			i++
+			continue
+		}
+
+		start := i
+		var text strings.Builder
+		for i < len(input) && input[i] != ' ' && input[i] != '\t' && input[i] != '\n' {
+			if input[i] != '"' {
+				text.WriteByte(input[i])
+				i++
+				continue
+			}
+			end := strings.IndexByte(input[i+1:], '"')
+			if end < 0 {
+				return nil, &Error{Input: input, Pos: i, Len: len(input) - i, Msg: "unterminated quote"}
+			}
+			text.WriteString(input[i+1 : i+1+end])
+			i += end + 2
+		}
+		tokens = append(tokens, token{text: text.String(), pos: start, raw: input[start:i]})
+	}
+	return tokens, nil
+}
+
+func parseTerm(input string, tok token) (domain.FilterTerm, error) {
+	fail := func(format string, args ...interface{}) (domain.FilterTerm, error) {
+		return domain.FilterTerm{}, &Error{Input: input, Pos: tok.pos, Len: len(tok.raw), Msg: fmt.Sprintf(format, args...)}
+	}
+
+	var term domain.FilterTerm
+	text := tok.text
+	// A leading "-" negates, unless the quoted word itself starts with one.
+	if strings.HasPrefix(tok.raw, "-") {
+		term.Negate = true
+		text = text[1:]
+	}
+	if text == "" {
+		return fail("empty term")
+	}
+
+	// Only a colon outside quotes separates field and value.
+	name, value, hasField := strings.Cut(text, ":")
+	if !hasField || strings.HasPrefix(strings.TrimPrefix(tok.raw, "-"), `"`) {
+		term.Field, term.Op, term.Value = domain.FilterText, domain.OpEq, text
+		return term, nil
+	}
+
+	spec, ok := fields[strings.ToLower(name)]
+	if !ok {
+		return fail("unknown field %q (valid: %s)", name, strings.Join(fieldNames(), ", "))
+	}
+	term.Field = spec.field
+	if value == "" {
+		return fail("missing value for %s", name)
+	}
+
+	switch spec.kind {
+	case kindText:
+		if op, _ := splitOp(value); op != domain.OpEq {
+			return fail("%s does not support comparisons", name)
+		}
+		term.Op, term.Value = domain.OpEq, value
+	case kindFlag:
+		value = strings.ToLower(value)
+		if !slices.Contains(flags, value) {
+			return fail("unknown value %q for is (valid: %s)", value, strings.Join(flags, ", "))
+		}
+		term.Op, term.Value = domain.OpEq, value
+	case kindNumber:
+		op, operand := splitOp(value)
+		term.Op = op
+		if lo, hi, isRange := strings.Cut(operand, ".."); isRange && op == domain.OpEq {
+			term.Op = domain.OpRange
+			var err error
+			if term.Number, err = parseBound(lo, parseNumber); err != nil {
+				return fail("invalid number %q for %s", lo, name)
+			}
+			if term.Upper, err = parseBound(hi, parseNumber); err != nil {
+				return fail("invalid number %q for %s", hi, name)
+			}
+			if term.Number == nil && term.Upper == nil {
+				return fail("range for %s needs at least one bound", name)
+			}
+			break
+		}
+		n, err := parseNumber(operand)
+		if err != nil {
+			return fail("invalid number %q for %s", operand, name)
+		}
+		term.Number = &n
+	case kindDate:
+		op, operand := splitOp(value)
+		term.Op = op
+		if lo, hi, isRange := strings.Cut(operand, ".."); isRange && op == domain.OpEq {
+			term.Op = domain.OpRange
+			from, err := parseBound(lo, parseDate)
+			if err != nil {
+				return fail("invalid date %q for %s (use YYYY-MM-DD)", lo, name)
+			}
+			to, err := parseBound(hi, parseDate)
+			if err != nil {
+				return fail("invalid date %q for %s (use YYYY-MM-DD)", hi, name)
+			}
+			if from == nil && to == nil {
+				return fail("range for %s needs at least one bound", name)
+			}
+			if from != nil {
+				term.Date = *from
+			}
+			if to != nil {
+				term.DateUpper = *to
+			}
+			break
+		}
+		d, err := parseDate(operand)
+		if err != nil {
+			return fail("invalid date %q for %s (use YYYY-MM-DD)", operand, name)
+		}
+		term.Date = d
+	}
+	return term, nil
+}
+
+// splitOp splits a leading comparison operator off value.
+func splitOp(value string) (domain.FilterOp, string) {
+	for _, op := range []domain.FilterOp{domain.OpGte, domain.OpLte, domain.OpGt, domain.OpLt} {
+		if rest, ok := strings.CutPrefix(value, string(op)); ok {
+			return op, rest
+		}
+	}
+	return domain.OpEq, value
+}
+
+// parseBound parses one end of a range; "*" or "" leaves it open.
+func parseBound[T any](s string, parse func(string) (T, error)) (*T, error) {
+	if s == "" || s == "*" {
+		return nil, nil
+	}
+	v, err := parse(s)
+	if err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+// parseNumber accepts plain integers and a k suffix (1.5k = 1500).
+func parseNumber(s string) (int, error) {
+	if rest, ok := strings.CutSuffix(strings.ToLower(s), "k"); ok {
+		f, err := strconv.ParseFloat(rest, 64)
+		if err != nil || f < 0 {
+			return 0, fmt.Errorf("invalid number %q", s)
+		}
+		return int(f * 1000), nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid number %q", s)
+	}
+	return n, nil
+}
+
+func parseDate(s string) (time.Time, error) {
+	return time.Parse(dateLayout, s)
+}
+
+func fieldNames() []string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		if name != "language" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,109 @@
+package filter
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brianluby/karakeep-extractor/internal/core/domain"
+)
+
+func intPtr(n int) *int { return &n }
+
+func date(s string) time.Time {
+	t, _ := time.Parse("2006-01-02", s)
+	return t
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []domain.FilterTerm
+	}{
+		{"", []domain.FilterTerm{}},
+		{"lang:go", []domain.FilterTerm{{Field: domain.FilterLang, Op: domain.OpEq, Value: "go"}}},
+		{"Language:Go", []domain.FilterTerm{{Field: domain.FilterLang, Op: domain.OpEq, Value: "Go"}}},
+		{"stars:>1000", []domain.FilterTerm{{Field: domain.FilterStars, Op: domain.OpGt, Number: intPtr(1000)}}},
+		{"stars:>=1.5k", []domain.FilterTerm{{Field: domain.FilterStars, Op: domain.OpGte, Number: intPtr(1500)}}},
+		{"forks:<=10", []domain.FilterTerm{{Field: domain.FilterForks, Op: domain.OpLte, Number: intPtr(10)}}},
+		{"stars:10..100", []domain.FilterTerm{{Field: domain.FilterStars, Op: domain.OpRange, Number: intPtr(10), Upper: intPtr(100)}}},
+		{"bookmarks:2..*", []domain.FilterTerm{{Field: domain.FilterBookmarks, Op: domain.OpRange, Number: intPtr(2)}}},
+		{"pushed:>2024-01-01", []domain.FilterTerm{{Field: domain.FilterPushed, Op: domain.OpGt, Date: date("2024-01-01")}}},
+		{"created:2020-01-01..2020-12-31", []domain.FilterTerm{{Field: domain.FilterCreated, Op: domain.OpRange, Date: date("2020-01-01"), DateUpper: date("2020-12-31")}}},
+		{"-tag:archived", []domain.FilterTerm{{Field: domain.FilterTag, Op: domain.OpEq, Negate: true, Value: "archived"}}},
+		{`tag:"machine learning"`, []domain.FilterTerm{{Field: domain.FilterTag, Op: domain.OpEq, Value: "machine learning"}}},
+		{"is:Fork -is:archived", []domain.FilterTerm{
+			{Field: domain.FilterIs, Op: domain.OpEq, Value: "fork"},
+			{Field: domain.FilterIs, Op: domain.OpEq, Negate: true, Value: "archived"},
+		}},
+		// Bare words, including quoted ones containing a colon, are text searches.
+		{`parser "a:b" -wip`, []domain.FilterTerm{
+			{Field: domain.FilterText, Op: domain.OpEq, Value: "parser"},
+			{Field: domain.FilterText, Op: domain.OpEq, Value: "a:b"},
+			{Field: domain.FilterText, Op: domain.OpEq, Negate: true, Value: "wip"},
+		}},
+		{"  lang:go \t stars:>1000  pushed:>2024-01-01 tag:cli -tag:archived ", []domain.FilterTerm{
+			{Field: domain.FilterLang, Op: domain.OpEq, Value: "go"},
+			{Field: domain.FilterStars, Op: domain.OpGt, Number: intPtr(1000)},
+			{Field: domain.FilterPushed, Op: domain.OpGt, Date: date("2024-01-01")},
+			{Field: domain.FilterTag, Op: domain.OpEq, Value: "cli"},
+			{Field: domain.FilterTag, Op: domain.OpEq, Negate: true, Value: "archived"},
+		}},
+	}
+
+	for _, tt := range tests {
+		got, err := Parse(tt.in)
+		if err != nil {
+			t.Errorf("Parse(%q) failed: %v", tt.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Parse(%q) =\n%+v\nwant\n%+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		in     string
+		msg    string
+		column int
+		marker string
+	}{
+		{"lang:go lnag:rust", `unknown field "lnag"`, 9, "^^^^^^^^^"},
+		{"stars:>lots", `invalid number "lots" for stars`, 1, "^^^^^^^^^^^"},
+		{"stars:1..x", `invalid number "x" for stars`, 1, "^^^^^^^^^^"},
+		{"stars:..", "range for stars needs at least one bound", 1, "^^^^^^^^"},
+		{"lang:go pushed:>2024-13-01", `invalid date "2024-13-01" for pushed (use YYYY-MM-DD)`, 9, "^^^^^^^^^^^^^^^^^^"},
+		{"lang:>go", "lang does not support comparisons", 1, "^^^^^^^^"},
+		{"is:popular", `unknown value "popular" for is`, 1, "^^^^^^^^^^"},
+		{"tag:", "missing value for tag", 1, "^^^^"},
+		{"ok -", "empty term", 4, "^"},
+		{`stars:>1 tag:"open`, "unterminated quote", 14, "^^^^^"},
+		// Columns count characters, not bytes.
+		{"tag:café lnag:go", `unknown field "lnag"`, 10, "^^^^^^^"},
+	}
+
+	for _, tt := range tests {
+		_, err := Parse(tt.in)
+		var perr *Error
+		if !errors.As(err, &perr) {
+			t.Errorf("Parse(%q): expected *Error, got %v", tt.in, err)
+			continue
+		}
+		lines := strings.Split(err.Error(), "\n")
+		if len(lines) != 3 {
+			t.Errorf("Parse(%q): expected message, input and marker lines, got %q", tt.in, err.Error())
+			continue
+		}
+		if !strings.HasPrefix(lines[0], tt.msg) || !strings.HasSuffix(lines[0], " at column "+strconv.Itoa(tt.column)) {
+			t.Errorf("Parse(%q) error = %q, want %q at column %d", tt.in, lines[0], tt.msg, tt.column)
+		}
+		if want := "  " + strings.Repeat(" ", tt.column-1) + tt.marker; lines[2] != want {
+			t.Errorf("Parse(%q) marker =\n%q\nwant\n%q", tt.in, lines[2], want)
+		}
+	}
+}
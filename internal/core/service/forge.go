@@ -0,0 +1,164 @@
+package service
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/brianluby/karakeep-extractor/internal/core/domain"
+)
+
+// DefaultForgeHosts maps well-known public forge hosts to their forge type.
+// GitHub is handled separately by NormalizeGitHubURL.
+var DefaultForgeHosts = map[string]domain.Forge{
+	"gitlab.com":    domain.ForgeGitLab,
+	"codeberg.org":  domain.ForgeGitea,
+	"gitea.com":     domain.ForgeGitea,
+	"bitbucket.org": domain.ForgeBitbucket,
+	"git.sr.ht":     domain.ForgeSourceHut,
+}
+
+// ForgeDetector recognises repository URLs belonging to one forge.
+type ForgeDetector interface {
+	Detect(u *url.URL) (domain.RepoRef, bool)
+}
+
+// DefaultForgeDetectors returns detectors for GitHub and every host in DefaultForgeHosts,
+// plus any extra (e.g. self-hosted) hosts.
+func DefaultForgeDetectors(extraHosts map[string]domain.Forge) []ForgeDetector {
+	hosts := make(map[domain.Forge][]string)
+	for host, forge := range DefaultForgeHosts {
+		hosts[forge] = append(hosts[forge], host)
+	}
+	for host, forge := range extraHosts {
+		hosts[forge] = append(hosts[forge], strings.ToLower(host))
+	}
+
+	return []ForgeDetector{
+		GitHubDetector{},
+		&hostDetector{forge: domain.ForgeGitLab, hosts: hosts[domain.ForgeGitLab], parse: parseGitLabPath},
+		&hostDetector{forge: domain.ForgeGitea, hosts: hosts[domain.ForgeGitea], parse: parseGiteaPath},
+		&hostDetector{forge: domain.ForgeBitbucket, hosts: hosts[domain.ForgeBitbucket], parse: parseBitbucketPath},
+		&hostDetector{forge: domain.ForgeSourceHut, hosts: hosts[domain.ForgeSourceHut], parse: parseSourceHutPath},
+	}
+}
+
+// DetectRepo returns the first repository reference recognised by the detectors.
+func DetectRepo(detectors []ForgeDetector, rawURL string) (domain.RepoRef, bool) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return domain.RepoRef{}, false
+	}
+	for _, d := range detectors {
+		if ref, ok := d.Detect(u); ok {
+			return ref, true
+		}
+	}
+	return domain.RepoRef{}, false
+}
+
+// GitHubDetector recognises github.com repository URLs.
+type GitHubDetector struct{}
+
+func (GitHubDetector) Detect(u *url.URL) (domain.RepoRef, bool) {
+	id, ok := NormalizeGitHubURL(u.String())
+	if !ok {
+		return domain.RepoRef{}, false
+	}
+	owner, name, _ := strings.Cut(id, "/")
+	return domain.RepoRef{Forge: domain.ForgeGitHub, Host: domain.GitHubHost, Owner: owner, Name: name}, true
+}
+
+// hostDetector matches a fixed set of hosts and delegates path parsing to the forge's URL layout.
+type hostDetector struct {
+	forge domain.Forge
+	hosts []string
+	parse func(segments []string) (owner, name string, ok bool)
+}
+
+func (d *hostDetector) Detect(u *url.URL) (domain.RepoRef, bool) {
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	matched := false
+	for _, h := range d.hosts {
+		if host == h {
+			matched = true
+			break
+		}
+	}
+	if !matched {
+		return domain.RepoRef{}, false
+	}
+
+	var segments []string
+	for _, s := range strings.Split(u.Path, "/") {
+		if s != "" {
+			segments = append(segments, s)
+		}
+	}
+
+	owner, name, ok := d.parse(segments)
+	if !ok {
+		return domain.RepoRef{}, false
+	}
+	return domain.RepoRef{Forge: d.forge, Host: host, Owner: owner, Name: strings.TrimSuffix(name, ".git")}, true
+}
+
+// gitlabSubpages are path segments that end the project path on older GitLab URLs without "/-/".
+var gitlabSubpages = map[string]bool{
+	"tree": true, "blob": true, "raw": true, "issues": true, "merge_requests": true,
+	"commits": true, "commit": true, "tags": true, "releases": true, "wikis": true, "pipelines": true,
+}
+
+var gitlabReserved = map[string]bool{
+	"explore": true, "users": true, "help": true, "dashboard": true, "search": true,
+	"groups": true, "projects": true, "admin": true, "api": true,
+}
+
+// parseGitLabPath handles nested groups: /group/sub/project[/-/...]
+func parseGitLabPath(segments []string) (string, string, bool) {
+	if len(segments) == 0 || gitlabReserved[strings.ToLower(segments[0])] {
+		return "", "", false
+	}
+	end := len(segments)
+	for i, s := range segments {
+		if s == "-" || (i >= 2 && gitlabSubpages[s]) {
+			end = i
+			break
+		}
+	}
+	if end < 2 {
+		return "", "", false
+	}
+	return strings.Join(segments[:end-1], "/"), segments[end-1], true
+}
+
+var giteaReserved = map[string]bool{
+	"explore": true, "user": true, "org": true, "api": true, "assets": true, "admin": true, "notifications": true,
+}
+
+// parseGiteaPath handles /owner/repo[/...]
+func parseGiteaPath(segments []string) (string, string, bool) {
+	if len(segments) < 2 || giteaReserved[strings.ToLower(segments[0])] {
+		return "", "", false
+	}
+	return segments[0], segments[1], true
+}
+
+var bitbucketReserved = map[string]bool{
+	"account": true, "dashboard": true, "product": true, "blog": true, "site": true, "repo": true,
+}
+
+// parseBitbucketPath handles /workspace/repo[/...]
+func parseBitbucketPath(segments []string) (string, string, bool) {
+	if len(segments) < 2 || bitbucketReserved[strings.ToLower(segments[0])] {
+		return "", "", false
+	}
+	return segments[0], segments[1], true
+}
+
+// parseSourceHutPath handles /~user/repo[/...]
+func parseSourceHutPath(segments []string) (string, string, bool) {
+	if len(segments) < 2 || !strings.HasPrefix(segments[0], "~") {
+		return "", "", false
+	}
+	return segments[0], segments[1], true
+}
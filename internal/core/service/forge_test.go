@@ -0,0 +1,68 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/brianluby/karakeep-extractor/internal/core/domain"
+)
+
+func TestDetectRepo(t *testing.T) {
+	detectors := DefaultForgeDetectors(map[string]domain.Forge{"git.example.com": domain.ForgeGitLab})
+
+	tests := []struct {
+		url    string
+		wantID string
+		forge  domain.Forge
+		ok     bool
+	}{
+		{"https://github.com/owner/repo", "owner/repo", domain.ForgeGitHub, true},
+		{"https://gitlab.com/group/project", "gitlab.com:group/project", domain.ForgeGitLab, true},
+		{"https://gitlab.com/group/sub/project/-/issues/1", "gitlab.com:group/sub/project", domain.ForgeGitLab, true},
+		{"https://gitlab.com/group/project/tree/main", "gitlab.com:group/project", domain.ForgeGitLab, true},
+		{"https://gitlab.com/explore/projects", "", "", false},
+		{"https://codeberg.org/forgejo/forgejo.git", "codeberg.org:forgejo/forgejo", domain.ForgeGitea, true},
+		{"https://codeberg.org/explore/repos", "", "", false},
+		{"https://bitbucket.org/workspace/repo/src/main", "bitbucket.org:workspace/repo", domain.ForgeBitbucket, true},
+		{"https://git.sr.ht/~sircmpwn/aerc", "git.sr.ht:~sircmpwn/aerc", domain.ForgeSourceHut, true},
+		{"https://git.sr.ht/sircmpwn/aerc", "", "", false},
+		{"https://git.example.com/team/tool", "git.example.com:team/tool", domain.ForgeGitLab, true},
+		{"https://gitlab.com/onlygroup", "", "", false},
+		{"https://example.com/owner/repo", "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			ref, ok := DetectRepo(detectors, tt.url)
+			if ok != tt.ok {
+				t.Fatalf("DetectRepo(%q) ok = %v, want %v", tt.url, ok, tt.ok)
+			}
+			if !ok {
+				return
+			}
+			if ref.ID() != tt.wantID {
+				t.Errorf("ID = %q, want %q", ref.ID(), tt.wantID)
+			}
+			if ref.Forge != tt.forge {
+				t.Errorf("Forge = %q, want %q", ref.Forge, tt.forge)
+			}
+		})
+	}
+}
+
+func TestSplitRepoID(t *testing.T) {
+	tests := []struct {
+		id                string
+		host, owner, name string
+	}{
+		{"owner/repo", domain.GitHubHost, "owner", "repo"},
+		{"gitlab.com:group/sub/project", "gitlab.com", "group/sub", "project"},
+		{"git.sr.ht:~user/repo", "git.sr.ht", "~user", "repo"},
+	}
+
+	for _, tt := range tests {
+		host, owner, name, ok := domain.SplitRepoID(tt.id)
+		if !ok || host != tt.host || owner != tt.owner || name != tt.name {
+			t.Errorf("SplitRepoID(%q) = %q, %q, %q, %v", tt.id, host, owner, name, ok)
+		}
+	}
+}
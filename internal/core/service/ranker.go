@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"strconv"
+	"time"
 
 	"github.com/brianluby/karakeep-extractor/internal/core/domain"
 	"github.com/brianluby/karakeep-extractor/internal/ui"
@@ -13,6 +15,9 @@ type Ranker struct {
 	repo     domain.RankingRepository
 	exporter domain.Exporter
 	sink     domain.Sink
+	scorer   *Scorer
+	explain  bool
+	paging   bool
 }
 
 func NewRanker(repo domain.RankingRepository, exporter domain.Exporter, sink domain.Sink) *Ranker {
@@ -23,25 +28,112 @@ func NewRanker(repo domain.RankingRepository, exporter domain.Exporter, sink dom
 	}
 }
 
-func (r *Ranker) Rank(ctx context.Context, limit int, sortBy string, filterTag string, output io.Writer) error {
-	var sortOption domain.RankSortOption
-	switch sortBy {
-	case "stars":
-		sortOption = domain.SortByStars
-	case "forks":
-		sortOption = domain.SortByForks
-	case "updated":
-		sortOption = domain.SortByUpdated
-	default:
-		return fmt.Errorf("invalid sort option: %s (valid: stars, forks, updated)", sortBy)
+// WithScorer sets the weights used by SortByScore; without it the defaults apply.
+func (r *Ranker) WithScorer(scorer *Scorer) *Ranker {
+	r.scorer = scorer
+	return r
+}
+
+// WithExplain shows each score component's contribution in the table output.
+func (r *Ranker) WithExplain(explain bool) *Ranker {
+	r.explain = explain
+	return r
+}
+
+// WithPaging reports where the results sit in the full ranking: a "showing x-y of n" line
+// under the table, and the total and next page in JSON. It is implied by a non-zero Offset.
+func (r *Ranker) WithPaging(paging bool) *Ranker {
+	r.paging = paging
+	return r
+}
+
+// DefaultDeltaWindow is the stars-delta window used when RankQuery.Since is unset.
+const DefaultDeltaWindow = 30 * 24 * time.Hour
+
+// ParseSince parses a look-back window such as "30d", "2w" or any time.ParseDuration value ("12h").
+func ParseSince(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, nil
+	}
+	var unit time.Duration
+	switch s[len(s)-1] {
+	case 'd':
+		unit = 24 * time.Hour
+	case 'w':
+		unit = 7 * 24 * time.Hour
+	}
+	if unit != 0 {
+		n, err := strconv.Atoi(s[:len(s)-1])
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid window %q (use e.g. 30d, 2w, 12h)", s)
+		}
+		return time.Duration(n) * unit, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid window %q (use e.g. 30d, 2w, 12h)", s)
+	}
+	return d, nil
+}
+
+// ValidateSortOption checks that sort is one Rank understands.
+func ValidateSortOption(sort domain.RankSortOption) error {
+	switch sort {
+	case domain.SortByStars, domain.SortByForks, domain.SortByUpdated, domain.SortByStarsDelta, domain.SortByBookmarks, domain.SortByScore:
+		return nil
+	}
+	return fmt.Errorf("invalid sort option: %s (valid: stars, forks, updated, stars-delta, bookmarks, score)", sort)
+}
+
+func (r *Ranker) Rank(ctx context.Context, query domain.RankQuery, output io.Writer) error {
+	if err := ValidateSortOption(query.SortBy); err != nil {
+		return err
+	}
+	if query.SortBy == domain.SortByStarsDelta && query.Since <= 0 {
+		query.Since = DefaultDeltaWindow
+	}
+	if r.explain && query.SortBy != domain.SortByScore {
+		return fmt.Errorf("--explain only applies to --sort score")
 	}
+	if query.Offset < 0 {
+		return fmt.Errorf("invalid offset %d", query.Offset)
+	}
+	paging := r.paging || query.Offset > 0
 
-	repos, err := r.repo.GetRankedRepos(ctx, limit, sortOption, filterTag)
+	// The score is computed here, over every matching repo, rather than in the query.
+	fetch := query
+	if query.SortBy == domain.SortByScore {
+		fetch.SortBy = domain.SortByStars
+		fetch.Limit = 0
+		fetch.Offset = 0
+	}
+	repos, err := r.repo.GetRankedRepos(ctx, fetch)
 	if err != nil {
 		return fmt.Errorf("failed to get ranked repos: %w", err)
 	}
+	total := len(repos)
+	scorer := r.scorer
+	if query.SortBy == domain.SortByScore {
+		if scorer == nil {
+			scorer, _ = NewScorer(domain.ScoreConfig{})
+		}
+		scorer.Rank(repos, time.Now())
+		repos = repos[min(query.Offset, len(repos)):]
+		if query.Limit > 0 && len(repos) > query.Limit {
+			repos = repos[:query.Limit]
+		}
+	} else if paging {
+		if total, err = r.repo.CountRankedRepos(ctx, query); err != nil {
+			return fmt.Errorf("failed to count ranked repos: %w", err)
+		}
+	}
+	page := domain.Page{Repos: repos, Total: total, Offset: query.Offset, Limit: query.Limit}
 
 	if len(repos) == 0 {
+		if paging && total > 0 {
+			fmt.Fprintf(output, "No repositories on this page (%d in total).\n", total)
+			return nil
+		}
 		fmt.Fprintln(output, "No repositories found.")
 		return nil
 	}
@@ -56,12 +148,32 @@ func (r *Ranker) Rank(ctx context.Context, limit int, sortBy string, filterTag s
 
 	// 2. Handle Output (Exporter or Table)
 	if r.exporter != nil {
+		if paged, ok := r.exporter.(domain.PagedExporter); ok && paging {
+			return paged.ExportPage(page, output)
+		}
 		return r.exporter.Export(repos, output)
 	}
 
 	// Default: Table Pager
 	return ui.UsePager(output, func(w io.Writer) error {
-		renderer := ui.NewTableRenderer(w)
-		return renderer.Render(repos)
+		if r.explain {
+			fmt.Fprintf(w, "%s\n\n", scorer.Describe())
+		}
+		renderer := ui.NewTableRenderer(w).WithExplain(r.explain).WithRankOffset(query.Offset)
+		if err := renderer.Render(repos); err != nil {
+			return err
+		}
+		if paging {
+			fmt.Fprintf(w, "\nShowing %d-%d of %d", page.Offset+1, page.Offset+len(repos), page.Total)
+			if next := page.NextOffset(); next >= 0 {
+				if n := page.Number(); n > 0 {
+					fmt.Fprintf(w, " (next: --page %d)", n+1)
+				} else {
+					fmt.Fprintf(w, " (next: --offset %d)", next)
+				}
+			}
+			fmt.Fprintln(w)
+		}
+		return nil
 	})
 }
@@ -44,7 +44,7 @@ func TestRanker_WithExportAndSink(t *testing.T) {
 	ranker := service.NewRanker(mockRepo, mockExp, mockSnk)
 	var buf bytes.Buffer
 
-	if err := ranker.Rank(context.Background(), 10, "stars", "", &buf); err != nil {
+	if err := ranker.Rank(context.Background(), domain.RankQuery{Limit: 10, SortBy: "stars"}, &buf); err != nil {
 		t.Fatalf("Rank failed: %v", err)
 	}
 
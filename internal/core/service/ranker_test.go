@@ -3,11 +3,14 @@ package service_test
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/brianluby/karakeep-extractor/internal/core/domain"
 	"github.com/brianluby/karakeep-extractor/internal/core/service"
+	"github.com/brianluby/karakeep-extractor/internal/ui"
 )
 
 type mockRankingRepo struct {
@@ -16,14 +19,24 @@ type mockRankingRepo struct {
 
 func (m *mockRankingRepo) Save(ctx context.Context, repo domain.ExtractedRepo) error { return nil }
 func (m *mockRankingRepo) Exists(ctx context.Context, repoID string) (bool, error)   { return false, nil }
+func (m *mockRankingRepo) ExistingRepos(ctx context.Context, repoIDs []string) (map[string]bool, error) {
+	return nil, nil
+}
 func (m *mockRankingRepo) GetReposForEnrichment(ctx context.Context, limit int, force bool) ([]*domain.ExtractedRepo, error) {
 	return nil, nil
 }
 func (m *mockRankingRepo) UpdateRepoEnrichment(ctx context.Context, update domain.RepoEnrichmentUpdate) error {
 	return nil
 }
-func (m *mockRankingRepo) GetRankedRepos(ctx context.Context, limit int, sortBy domain.RankSortOption, filterTag string) ([]domain.ExtractedRepo, error) {
-	return m.repos, nil
+func (m *mockRankingRepo) GetRankedRepos(ctx context.Context, query domain.RankQuery) ([]domain.ExtractedRepo, error) {
+	repos := m.repos[min(query.Offset, len(m.repos)):]
+	if query.Limit > 0 && len(repos) > query.Limit {
+		repos = repos[:query.Limit]
+	}
+	return repos, nil
+}
+func (m *mockRankingRepo) CountRankedRepos(ctx context.Context, query domain.RankQuery) (int, error) {
+	return len(m.repos), nil
 }
 
 func TestRanker_Rank(t *testing.T) {
@@ -35,7 +48,7 @@ func TestRanker_Rank(t *testing.T) {
 	ranker := service.NewRanker(mockRepo, nil, nil)
 	var buf bytes.Buffer
 
-	if err := ranker.Rank(context.Background(), 10, "stars", "", &buf); err != nil {
+	if err := ranker.Rank(context.Background(), domain.RankQuery{Limit: 10, SortBy: "stars"}, &buf); err != nil {
 		t.Fatalf("Rank failed: %v", err)
 	}
 
@@ -47,7 +60,148 @@ func TestRanker_Rank(t *testing.T) {
 func TestRanker_InvalidSort(t *testing.T) {
 	ranker := service.NewRanker(&mockRankingRepo{}, nil, nil)
 	var buf bytes.Buffer
-	if err := ranker.Rank(context.Background(), 10, "invalid", "", &buf); err == nil {
+	if err := ranker.Rank(context.Background(), domain.RankQuery{Limit: 10, SortBy: "invalid"}, &buf); err == nil {
 		t.Error("Expected error for invalid sort option")
 	}
 }
+
+func TestParseSince(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"30d", 30 * 24 * time.Hour, false},
+		{"2w", 14 * 24 * time.Hour, false},
+		{"12h", 12 * time.Hour, false},
+		{"", 0, false},
+		{"0d", 0, true},
+		{"xd", 0, true},
+		{"soon", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := service.ParseSince(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseSince(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseSince(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestRanker_Rank_StarsDelta(t *testing.T) {
+	delta := 42
+	mockRepo := &mockRankingRepo{
+		repos: []domain.ExtractedRepo{
+			{RepoID: "test/rising", StarsDelta: &delta},
+		},
+	}
+	ranker := service.NewRanker(mockRepo, nil, nil)
+	var buf bytes.Buffer
+
+	if err := ranker.Rank(context.Background(), domain.RankQuery{Limit: 10, SortBy: domain.SortByStarsDelta}, &buf); err != nil {
+		t.Fatalf("Rank failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), "DELTA") || !strings.Contains(buf.String(), "+42") {
+		t.Errorf("Expected delta column in output, got:\n%s", buf.String())
+	}
+}
+
+func TestRanker_Rank_Paging(t *testing.T) {
+	mockRepo := &mockRankingRepo{
+		repos: []domain.ExtractedRepo{
+			{RepoID: "test/a"}, {RepoID: "test/b"}, {RepoID: "test/c"}, {RepoID: "test/d"}, {RepoID: "test/e"},
+		},
+	}
+
+	var buf bytes.Buffer
+	ranker := service.NewRanker(mockRepo, nil, nil).WithPaging(true)
+	if err := ranker.Rank(context.Background(), domain.RankQuery{Limit: 2, Offset: 2, SortBy: domain.SortByStars}, &buf); err != nil {
+		t.Fatalf("Rank failed: %v", err)
+	}
+	out := buf.String()
+	if strings.Contains(out, "test/b") || !strings.Contains(out, "test/c") || !strings.Contains(out, "test/d") {
+		t.Errorf("expected the second page, got:\n%s", out)
+	}
+	if !strings.Contains(out, "\n3     test/c") {
+		t.Errorf("expected ranks to continue from the offset, got:\n%s", out)
+	}
+	if !strings.Contains(out, "Showing 3-4 of 5 (next: --page 3)") {
+		t.Errorf("expected a paging footer, got:\n%s", out)
+	}
+
+	var page struct {
+		Total      int                    `json:"total"`
+		Page       int                    `json:"page"`
+		NextOffset *int                   `json:"next_offset"`
+		NextPage   *int                   `json:"next_page"`
+		Repos      []domain.ExtractedRepo `json:"repos"`
+	}
+	for _, tt := range []struct {
+		offset   int
+		nextPage int // 0 = last page
+	}{{2, 3}, {4, 0}} {
+		buf.Reset()
+		ranker := service.NewRanker(mockRepo, ui.NewJSONExporter(), nil).WithPaging(true)
+		if err := ranker.Rank(context.Background(), domain.RankQuery{Limit: 2, Offset: tt.offset, SortBy: domain.SortByStars}, &buf); err != nil {
+			t.Fatalf("Rank failed: %v", err)
+		}
+		page.NextOffset, page.NextPage = nil, nil
+		if err := json.Unmarshal(buf.Bytes(), &page); err != nil {
+			t.Fatalf("invalid JSON page: %v\n%s", err, buf.String())
+		}
+		if page.Total != 5 || page.Page != tt.offset/2+1 {
+			t.Errorf("offset %d: unexpected metadata %+v", tt.offset, page)
+		}
+		if tt.nextPage == 0 && page.NextOffset != nil {
+			t.Errorf("offset %d: expected no next page, got offset %d", tt.offset, *page.NextOffset)
+		}
+		if tt.nextPage != 0 && (page.NextPage == nil || *page.NextPage != tt.nextPage || *page.NextOffset != tt.offset+2) {
+			t.Errorf("offset %d: unexpected next page %+v", tt.offset, page)
+		}
+	}
+
+	// An offset off the page grid has no page number to point to.
+	buf.Reset()
+	ranker = service.NewRanker(mockRepo, nil, nil).WithPaging(true)
+	if err := ranker.Rank(context.Background(), domain.RankQuery{Limit: 2, Offset: 1, SortBy: domain.SortByStars}, &buf); err != nil {
+		t.Fatalf("Rank failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), "Showing 2-3 of 5 (next: --offset 3)") {
+		t.Errorf("expected an offset footer, got:\n%s", buf.String())
+	}
+	buf.Reset()
+	ranker = service.NewRanker(mockRepo, ui.NewJSONExporter(), nil).WithPaging(true)
+	if err := ranker.Rank(context.Background(), domain.RankQuery{Limit: 2, Offset: 1, SortBy: domain.SortByStars}, &buf); err != nil {
+		t.Fatalf("Rank failed: %v", err)
+	}
+	page.Page, page.NextOffset, page.NextPage = 0, nil, nil
+	if err := json.Unmarshal(buf.Bytes(), &page); err != nil {
+		t.Fatalf("invalid JSON page: %v\n%s", err, buf.String())
+	}
+	if page.Page != 0 || page.NextPage != nil || page.NextOffset == nil || *page.NextOffset != 3 {
+		t.Errorf("offset 1: unexpected metadata %+v", page)
+	}
+}
+
+func TestRanker_Rank_PagingScore(t *testing.T) {
+	small, big := 10, 1000
+	mockRepo := &mockRankingRepo{
+		repos: []domain.ExtractedRepo{
+			{RepoID: "test/big", Stars: &big}, {RepoID: "test/small", Stars: &small},
+		},
+	}
+
+	var buf bytes.Buffer
+	ranker := service.NewRanker(mockRepo, nil, nil)
+	// Score ranks over every repo, so the offset is applied after scoring.
+	if err := ranker.Rank(context.Background(), domain.RankQuery{Limit: 1, Offset: 1, SortBy: domain.SortByScore}, &buf); err != nil {
+		t.Fatalf("Rank failed: %v", err)
+	}
+	if out := buf.String(); strings.Contains(out, "test/big") || !strings.Contains(out, "test/small") || !strings.Contains(out, "Showing 2-2 of 2") {
+		t.Errorf("expected the second-scored repo, got:\n%s", out)
+	}
+}
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/brianluby/karakeep-extractor/internal/core/domain"
+)
+
+// defaultRateLimitWait is used when the API doesn't say when the limit resets.
+const defaultRateLimitWait = time.Minute
+
+// maxRateLimitWaits bounds how many times one request is retried after waiting.
+const maxRateLimitWaits = 5
+
+// rateLimitGate pauses every worker until a shared resume time.
+type rateLimitGate struct {
+	mu             sync.Mutex
+	resumeAt       time.Time
+	counting       bool          // A worker is already reporting the countdown.
+	statusInterval time.Duration // How often the countdown status is refreshed.
+}
+
+func newRateLimitGate() *rateLimitGate {
+	return &rateLimitGate{statusInterval: 10 * time.Second}
+}
+
+// pause moves the resume time forward to 'until' (never backward).
+func (g *rateLimitGate) pause(until time.Time) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if until.After(g.resumeAt) {
+		g.resumeAt = until
+	}
+}
+
+// wait blocks until the gate opens. One waiter reports a countdown through the reporter.
+func (g *rateLimitGate) wait(ctx context.Context, reporter domain.ProgressReporter) error {
+	g.mu.Lock()
+	owner := !g.counting && time.Now().Before(g.resumeAt)
+	if owner {
+		g.counting = true
+	}
+	g.mu.Unlock()
+
+	if owner {
+		defer func() {
+			g.mu.Lock()
+			g.counting = false
+			g.mu.Unlock()
+		}()
+	}
+
+	for {
+		g.mu.Lock()
+		remaining := time.Until(g.resumeAt)
+		g.mu.Unlock()
+		if remaining <= 0 {
+			return nil
+		}
+
+		step := remaining
+		if owner {
+			reporter.SetStatus(fmt.Sprintf("Rate limited, resuming in %s", remaining.Round(time.Second)))
+			step = min(remaining, g.statusInterval)
+		}
+
+		timer := time.NewTimer(step)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// callWithRateLimitWait runs call, and when waiting is enabled and the call hits a rate limit,
+// pauses all workers until the reset time and retries.
+func (e *Enricher) callWithRateLimitWait(ctx context.Context, reporter domain.ProgressReporter, call func() error) error {
+	if e.gate == nil {
+		return call()
+	}
+
+	for attempt := 0; ; attempt++ {
+		if err := e.gate.wait(ctx, reporter); err != nil {
+			return err
+		}
+
+		err := call()
+		if !errors.Is(err, domain.ErrRateLimitExceeded) || attempt >= maxRateLimitWaits {
+			return err
+		}
+
+		resumeAt := time.Now().Add(defaultRateLimitWait)
+		var rlErr *domain.RateLimitError
+		if errors.As(err, &rlErr) && !rlErr.ResetAt.IsZero() {
+			resumeAt = rlErr.ResetAt
+		}
+		reporter.Log(fmt.Sprintf("%v; waiting until %s", err, resumeAt.Format(time.Kitchen)))
+		e.gate.pause(resumeAt)
+	}
+}
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/brianluby/karakeep-extractor/internal/core/domain"
+)
+
+// DefaultReadmeMaxBytes caps the README text stored per repo.
+const DefaultReadmeMaxBytes = 16 * 1024
+
+// ReadmeFetcher is the optional enrichment stage that stores README text for GitHub repos.
+type ReadmeFetcher struct {
+	repo     domain.ReadmeRepository
+	client   domain.ReadmeClient
+	maxBytes int
+}
+
+func NewReadmeFetcher(repo domain.ReadmeRepository, client domain.ReadmeClient) *ReadmeFetcher {
+	return &ReadmeFetcher{
+		repo:     repo,
+		client:   client,
+		maxBytes: DefaultReadmeMaxBytes,
+	}
+}
+
+// WithMaxBytes overrides the size cap for stored README text.
+func (f *ReadmeFetcher) WithMaxBytes(n int) *ReadmeFetcher {
+	if n > 0 {
+		f.maxBytes = n
+	}
+	return f
+}
+
+// FetchReadmes fetches READMEs for up to limit repos. A README whose SHA matches the stored one
+// is not rendered or rewritten again. It stops early on a rate limit, keeping what was stored.
+func (f *ReadmeFetcher) FetchReadmes(ctx context.Context, limit int, force bool, reporter domain.ProgressReporter) (updated, failed int, err error) {
+	repos, err := f.repo.GetReposForReadme(ctx, limit, force)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to get repos for readme: %w", err)
+	}
+	if len(repos) == 0 {
+		return 0, 0, nil
+	}
+
+	reporter.Start(len(repos), "Fetching READMEs")
+	unchanged, missing := 0, 0
+	for _, repo := range repos {
+		if ctx.Err() != nil {
+			return updated, failed, ctx.Err()
+		}
+		_, owner, name, ok := domain.SplitRepoID(repo.RepoID)
+		if !ok {
+			reporter.RecordSkipped()
+			reporter.Increment()
+			continue
+		}
+
+		update := domain.ReadmeUpdate{RepoID: repo.RepoID}
+		readme, fetchErr := f.client.GetReadme(ctx, owner, name)
+		switch {
+		case errors.Is(fetchErr, domain.ErrNoReadme):
+			missing++
+		case errors.Is(fetchErr, domain.ErrRateLimitExceeded):
+			reporter.Error(fetchErr)
+			reporter.Finish(fmt.Sprintf("READMEs updated: %d (Unchanged: %d, None: %d), Failed: %d; stopped by rate limit", updated, unchanged, missing, failed))
+			return updated, failed, fetchErr
+		case fetchErr != nil:
+			reporter.Log(fmt.Sprintf("README fetch failed for %s: %v", repo.RepoID, fetchErr))
+			reporter.RecordFailure()
+			reporter.Increment()
+			failed++
+			continue
+		case readme.SHA == repo.ReadmeSHA:
+			update.Unchanged = true
+			unchanged++
+		default:
+			update.SHA = readme.SHA
+			update.Text = ReadmeText(readme.Content, f.maxBytes)
+			updated++
+		}
+
+		if err := f.repo.UpdateRepoReadme(ctx, update); err != nil {
+			reporter.Log(fmt.Sprintf("Save failed for %s: %v", repo.RepoID, err))
+			reporter.RecordFailure()
+			reporter.Increment()
+			failed++
+			continue
+		}
+		reporter.RecordSuccess()
+		reporter.Increment()
+	}
+
+	reporter.Finish(fmt.Sprintf("READMEs updated: %d (Unchanged: %d, None: %d), Failed: %d", updated, unchanged, missing, failed))
+	return updated, failed, nil
+}
+
+var (
+	mdComment    = regexp.MustCompile(`(?s)<!--.*?-->`)
+	mdFence      = regexp.MustCompile("(?m)^\\s*(```|~~~).*$")
+	mdImage      = regexp.MustCompile(`!\[([^\]]*)\]\([^)]*\)`)
+	mdLink       = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`)
+	mdRefDef     = regexp.MustCompile(`(?m)^\s*\[[^\]]+\]:\s*\S+.*$`)
+	mdHTMLTag    = regexp.MustCompile(`</?[a-zA-Z][^>]*>`)
+	mdHeading    = regexp.MustCompile(`(?m)^\s{0,3}#{1,6}\s*`)
+	mdQuote      = regexp.MustCompile(`(?m)^\s*>\s?`)
+	mdTableRule  = regexp.MustCompile(`(?m)^\s*\|?\s*:?-{3,}:?\s*(\|\s*:?-{3,}:?\s*)*\|?\s*$`)
+	mdRule       = regexp.MustCompile(`(?m)^\s*((-\s*){3,}|(\*\s*){3,}|(_\s*){3,})$`)
+	mdEmphasis   = regexp.MustCompile("(\\*\\*|__|`)")
+	mdBlankLines = regexp.MustCompile(`\n{3,}`)
+)
+
+// ReadmeText renders Markdown (or HTML-flavoured Markdown) to plain text for search and LLM
+// context, and caps it at maxBytes without splitting a UTF-8 character.
+func ReadmeText(markdown string, maxBytes int) string {
+	text := strings.ReplaceAll(markdown, "\r\n", "\n")
+	text = mdComment.ReplaceAllString(text, "")
+	text = mdFence.ReplaceAllString(text, "")
+	text = mdImage.ReplaceAllString(text, "$1")
+	text = mdLink.ReplaceAllString(text, "$1")
+	text = mdRefDef.ReplaceAllString(text, "")
+	text = mdHTMLTag.ReplaceAllString(text, "")
+	text = mdHeading.ReplaceAllString(text, "")
+	text = mdQuote.ReplaceAllString(text, "")
+	text = mdTableRule.ReplaceAllString(text, "")
+	text = mdRule.ReplaceAllString(text, "")
+	text = mdEmphasis.ReplaceAllString(text, "")
+	text = html.UnescapeString(text)
+
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		line = strings.TrimRight(line, " \t")
+		if strings.HasPrefix(strings.TrimSpace(line), "|") {
+			line = strings.TrimSpace(strings.ReplaceAll(line, "|", " "))
+		}
+		lines[i] = line
+	}
+	text = mdBlankLines.ReplaceAllString(strings.Join(lines, "\n"), "\n\n")
+	text = strings.TrimSpace(text)
+
+	if maxBytes > 0 && len(text) > maxBytes {
+		cut := maxBytes
+		for cut > 0 && !utf8.RuneStart(text[cut]) {
+			cut--
+		}
+		text = text[:cut]
+	}
+	return text
+}
@@ -0,0 +1,101 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/brianluby/karakeep-extractor/internal/core/domain"
+	"github.com/brianluby/karakeep-extractor/internal/core/service"
+)
+
+func TestReadmeText(t *testing.T) {
+	markdown := "# Fastlib\r\n\r\n" +
+		"[![Build](https://ci.example.com/badge.svg)](https://ci.example.com)\n\n" +
+		"<!-- hidden -->A **fast** library with [streaming](https://example.com/docs) support &amp; `async` I/O.\n\n" +
+		"```go\nfastlib.Run()\n```\n\n" +
+		"| Feature | Supported |\n|---|---|\n| Streaming | yes |\n\n\n\n" +
+		"<p align=\"center\">Made with care</p>\n"
+
+	got := service.ReadmeText(markdown, 0)
+	want := "Fastlib\n\nBuild\n\nA fast library with streaming support & async I/O.\n\nfastlib.Run()\n\nFeature   Supported\n\nStreaming   yes\n\nMade with care"
+	if got != want {
+		t.Errorf("ReadmeText() =\n%q\nwant\n%q", got, want)
+	}
+
+	// The cap never splits a multi-byte character.
+	if capped := service.ReadmeText("héllo", 2); capped != "h" {
+		t.Errorf("Expected cap to back off to a rune boundary, got %q", capped)
+	}
+}
+
+type mockReadmeRepo struct {
+	repos   []*domain.ExtractedRepo
+	updates []domain.ReadmeUpdate
+}
+
+func (m *mockReadmeRepo) GetReposForReadme(ctx context.Context, limit int, force bool) ([]*domain.ExtractedRepo, error) {
+	return m.repos, nil
+}
+
+func (m *mockReadmeRepo) UpdateRepoReadme(ctx context.Context, update domain.ReadmeUpdate) error {
+	m.updates = append(m.updates, update)
+	return nil
+}
+
+func (m *mockReadmeRepo) HydrateReadmes(ctx context.Context, repos []domain.ExtractedRepo) error {
+	return nil
+}
+
+type mockReadmeClient map[string]*domain.Readme
+
+func (m mockReadmeClient) GetReadme(ctx context.Context, owner, name string) (*domain.Readme, error) {
+	switch rd, ok := m[owner+"/"+name]; {
+	case !ok:
+		return nil, domain.ErrNoReadme
+	case rd == nil:
+		return nil, errors.New("boom")
+	default:
+		return rd, nil
+	}
+}
+
+func TestReadmeFetcher_FetchReadmes(t *testing.T) {
+	repo := &mockReadmeRepo{repos: []*domain.ExtractedRepo{
+		{RepoID: "owner/new"},
+		{RepoID: "owner/same", ReadmeSHA: "abc"},
+		{RepoID: "owner/none", ReadmeSHA: "old"},
+		{RepoID: "owner/broken"},
+	}}
+	client := mockReadmeClient{
+		"owner/new":    {SHA: "def", Content: "# New\n\nSome **bold** text that is long"},
+		"owner/same":   {SHA: "abc", Content: "# Same"},
+		"owner/broken": nil,
+	}
+
+	updated, failed, err := service.NewReadmeFetcher(repo, client).WithMaxBytes(16).
+		FetchReadmes(context.Background(), 10, false, &mockReporter{})
+	if err != nil {
+		t.Fatalf("FetchReadmes failed: %v", err)
+	}
+	if updated != 1 || failed != 1 {
+		t.Errorf("Expected 1 updated and 1 failed, got %d and %d", updated, failed)
+	}
+
+	byID := map[string]domain.ReadmeUpdate{}
+	for _, u := range repo.updates {
+		byID[u.RepoID] = u
+	}
+	if u := byID["owner/new"]; u.SHA != "def" || u.Text != "New\n\nSome bold t" || u.Unchanged {
+		t.Errorf("Unexpected update for owner/new: %+v", u)
+	}
+	if u := byID["owner/same"]; !u.Unchanged {
+		t.Errorf("Expected owner/same to be unchanged, got %+v", u)
+	}
+	if u, ok := byID["owner/none"]; !ok || u.SHA != "" || u.Text != "" {
+		t.Errorf("Expected owner/none README to be cleared, got %+v", u)
+	}
+	if _, ok := byID["owner/broken"]; ok || len(repo.updates) != 3 {
+		t.Errorf("Expected failed fetch not to be stored, got %+v", repo.updates)
+	}
+}
@@ -0,0 +1,186 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/brianluby/karakeep-extractor/internal/core/domain"
+	"github.com/brianluby/karakeep-extractor/internal/ui"
+)
+
+// SyncSourceReleases is the sync_state key holding when the releases list was last shown.
+const SyncSourceReleases = "releases"
+
+// DefaultReleaseWindow is how far back the first releases run looks.
+const DefaultReleaseWindow = 30 * 24 * time.Hour
+
+// ReleaseFetcher is the optional enrichment stage that records the latest release of GitHub repos.
+type ReleaseFetcher struct {
+	repo   domain.ReleaseRepository
+	client domain.ReleaseClient
+}
+
+func NewReleaseFetcher(repo domain.ReleaseRepository, client domain.ReleaseClient) *ReleaseFetcher {
+	return &ReleaseFetcher{
+		repo:   repo,
+		client: client,
+	}
+}
+
+// FetchReleases checks up to limit repos for a new release. It stops early on a rate limit,
+// keeping what was stored.
+func (f *ReleaseFetcher) FetchReleases(ctx context.Context, limit int, force bool, reporter domain.ProgressReporter) (updated, failed int, err error) {
+	repos, err := f.repo.GetReposForReleases(ctx, limit, force)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to get repos for releases: %w", err)
+	}
+	if len(repos) == 0 {
+		return 0, 0, nil
+	}
+
+	reporter.Start(len(repos), "Checking releases")
+	missing := 0
+	for _, repo := range repos {
+		if ctx.Err() != nil {
+			return updated, failed, ctx.Err()
+		}
+		_, owner, name, ok := domain.SplitRepoID(repo.RepoID)
+		if !ok {
+			reporter.RecordSkipped()
+			reporter.Increment()
+			continue
+		}
+
+		update := domain.ReleaseUpdate{RepoID: repo.RepoID}
+		release, fetchErr := f.client.GetLatestRelease(ctx, owner, name)
+		switch {
+		case errors.Is(fetchErr, domain.ErrNoRelease):
+			missing++
+		case errors.Is(fetchErr, domain.ErrRateLimitExceeded):
+			reporter.Error(fetchErr)
+			reporter.Finish(fmt.Sprintf("Releases checked: %d (None: %d), Failed: %d; stopped by rate limit", updated, missing, failed))
+			return updated, failed, fetchErr
+		case fetchErr != nil:
+			reporter.Log(fmt.Sprintf("Release fetch failed for %s: %v", repo.RepoID, fetchErr))
+			reporter.RecordFailure()
+			reporter.Increment()
+			failed++
+			continue
+		default:
+			update.Release = release
+			updated++
+		}
+
+		if err := f.repo.UpdateRepoRelease(ctx, update); err != nil {
+			reporter.Log(fmt.Sprintf("Save failed for %s: %v", repo.RepoID, err))
+			reporter.RecordFailure()
+			reporter.Increment()
+			failed++
+			continue
+		}
+		reporter.RecordSuccess()
+		reporter.Increment()
+	}
+
+	reporter.Finish(fmt.Sprintf("Releases checked: %d (None: %d), Failed: %d", updated, missing, failed))
+	return updated, failed, nil
+}
+
+// ReleaseWatcher lists bookmarked repos with a release recorded since it last ran.
+type ReleaseWatcher struct {
+	repo     domain.ReleaseRepository
+	state    domain.SyncStateRepository
+	exporter domain.Exporter
+	sink     domain.Sink
+}
+
+func NewReleaseWatcher(repo domain.ReleaseRepository, state domain.SyncStateRepository, exporter domain.Exporter, sink domain.Sink) *ReleaseWatcher {
+	return &ReleaseWatcher{
+		repo:     repo,
+		state:    state,
+		exporter: exporter,
+		sink:     sink,
+	}
+}
+
+// ReleaseWatchOptions controls a ReleaseWatcher run.
+type ReleaseWatchOptions struct {
+	Since time.Duration // Look back this far instead of to the last run.
+	Limit int           // <= 0 lists them all.
+	Peek  bool          // Don't record this run, so the same releases are listed next time.
+}
+
+// Run writes (and sends to the sink, if any) the repos whose release was recorded by enrich since
+// the last run, even if it was published earlier, or the releases published within opts.Since.
+// The first run lists releases published within DefaultReleaseWindow. A run cut short by
+// opts.Limit doesn't move the checkpoint, so the releases it left out are listed next time.
+func (w *ReleaseWatcher) Run(ctx context.Context, opts ReleaseWatchOptions, output io.Writer) error {
+	now := time.Now()
+	after := now.Add(-DefaultReleaseWindow)
+	query := domain.ReleaseQuery{PublishedAfter: after}
+	if opts.Since > 0 {
+		after = now.Add(-opts.Since)
+		query.PublishedAfter = after
+	} else {
+		state, err := w.state.GetSyncState(ctx, SyncSourceReleases)
+		if err != nil {
+			return fmt.Errorf("failed to load releases checkpoint: %w", err)
+		}
+		if state != nil {
+			after = state.LastRunAt
+			query = domain.ReleaseQuery{SeenAfter: after}
+		}
+	}
+	// One more than the limit tells whether the output was truncated.
+	if opts.Limit > 0 {
+		query.Limit = opts.Limit + 1
+	}
+
+	repos, err := w.repo.GetNewReleases(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to get new releases: %w", err)
+	}
+	truncated := opts.Limit > 0 && len(repos) > opts.Limit
+	if truncated {
+		repos = repos[:opts.Limit]
+	}
+
+	if len(repos) == 0 {
+		fmt.Fprintf(output, "No new releases since %s.\n", after.Local().Format("2006-01-02 15:04"))
+	} else {
+		if w.sink != nil {
+			if err := w.sink.Send(ctx, repos); err != nil {
+				return fmt.Errorf("failed to send to sink: %w", err)
+			}
+			fmt.Fprintln(output, "Successfully sent results to sink.")
+		}
+
+		if w.exporter != nil {
+			err = w.exporter.Export(repos, output)
+		} else {
+			err = ui.UsePager(output, func(out io.Writer) error {
+				if err := ui.NewReleaseTableRenderer(out).Render(repos); err != nil {
+					return err
+				}
+				if truncated && !opts.Peek {
+					fmt.Fprintf(out, "\nShowing the first %d; the rest are listed again next run.\n", opts.Limit)
+				}
+				return nil
+			})
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if opts.Peek || truncated {
+		return nil
+	}
+	if err := w.state.SaveSyncState(ctx, domain.SyncState{Source: SyncSourceReleases, LastRunAt: now}); err != nil {
+		return fmt.Errorf("failed to save releases checkpoint: %w", err)
+	}
+	return nil
+}
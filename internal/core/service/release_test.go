@@ -0,0 +1,141 @@
+package service_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brianluby/karakeep-extractor/internal/core/domain"
+	"github.com/brianluby/karakeep-extractor/internal/core/service"
+)
+
+type mockReleaseRepo struct {
+	repos   []*domain.ExtractedRepo
+	updates []domain.ReleaseUpdate
+	queries []domain.ReleaseQuery
+	fresh   []domain.ExtractedRepo
+}
+
+func (m *mockReleaseRepo) GetReposForReleases(ctx context.Context, limit int, force bool) ([]*domain.ExtractedRepo, error) {
+	return m.repos, nil
+}
+
+func (m *mockReleaseRepo) UpdateRepoRelease(ctx context.Context, update domain.ReleaseUpdate) error {
+	m.updates = append(m.updates, update)
+	return nil
+}
+
+func (m *mockReleaseRepo) GetNewReleases(ctx context.Context, query domain.ReleaseQuery) ([]domain.ExtractedRepo, error) {
+	m.queries = append(m.queries, query)
+	if query.Limit > 0 && len(m.fresh) > query.Limit {
+		return m.fresh[:query.Limit], nil
+	}
+	return m.fresh, nil
+}
+
+type mockReleaseClient map[string]error
+
+func (m mockReleaseClient) GetLatestRelease(ctx context.Context, owner, name string) (*domain.Release, error) {
+	if err := m[owner+"/"+name]; err != nil {
+		return nil, err
+	}
+	return &domain.Release{Tag: "v1.0.0", PublishedAt: time.Now()}, nil
+}
+
+func TestReleaseFetcher_FetchReleases(t *testing.T) {
+	repo := &mockReleaseRepo{repos: []*domain.ExtractedRepo{
+		{RepoID: "owner/released"},
+		{RepoID: "owner/none"},
+		{RepoID: "owner/broken"},
+		{RepoID: "owner/limited"},
+	}}
+	client := mockReleaseClient{
+		"owner/none":    domain.ErrNoRelease,
+		"owner/broken":  errors.New("boom"),
+		"owner/limited": &domain.RateLimitError{},
+	}
+
+	updated, failed, err := service.NewReleaseFetcher(repo, client).FetchReleases(context.Background(), 10, false, &mockReporter{})
+	if !errors.Is(err, domain.ErrRateLimitExceeded) {
+		t.Fatalf("Expected the rate limit to stop the run, got %v", err)
+	}
+	if updated != 1 || failed != 1 {
+		t.Errorf("Expected 1 updated and 1 failed, got %d and %d", updated, failed)
+	}
+	if len(repo.updates) != 2 || repo.updates[0].Release == nil || repo.updates[1].Release != nil {
+		t.Errorf("Expected a release for owner/released and none for owner/none, got %+v", repo.updates)
+	}
+}
+
+func TestReleaseWatcher_Run(t *testing.T) {
+	lastRun := time.Now().Add(-48 * time.Hour)
+	repo := &mockReleaseRepo{fresh: []domain.ExtractedRepo{
+		{RepoID: "owner/repo", LatestRelease: &domain.Release{Tag: "v1.1.0", PublishedAt: time.Now()}},
+	}}
+	state := &mockSyncState{state: &domain.SyncState{Source: service.SyncSourceReleases, LastRunAt: lastRun}}
+	sink := &mockSink{}
+
+	var out bytes.Buffer
+	watcher := service.NewReleaseWatcher(repo, state, nil, sink)
+	if err := watcher.Run(context.Background(), service.ReleaseWatchOptions{}, &out); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	if q := repo.queries[0]; !q.SeenAfter.Equal(lastRun) || !q.PublishedAfter.IsZero() {
+		t.Errorf("Expected releases recorded since the last run, got %+v", q)
+	}
+	if len(sink.repos) != 1 {
+		t.Errorf("Expected the release to be sent to the sink, got %+v", sink.repos)
+	}
+	if !strings.Contains(out.String(), "v1.1.0") {
+		t.Errorf("Expected release in output, got %q", out.String())
+	}
+	if len(state.saved) != 1 || state.saved[0].Source != service.SyncSourceReleases || !state.saved[0].LastRunAt.After(lastRun) {
+		t.Errorf("Expected the checkpoint to advance, got %+v", state.saved)
+	}
+
+	// --since overrides the checkpoint, and --peek leaves it alone.
+	if err := watcher.Run(context.Background(), service.ReleaseWatchOptions{Since: 7 * 24 * time.Hour, Peek: true}, &out); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if since := time.Since(repo.queries[1].PublishedAfter); since < 7*24*time.Hour || since > 7*24*time.Hour+time.Minute || !repo.queries[1].SeenAfter.IsZero() {
+		t.Errorf("Expected a 7 day window, got %+v", repo.queries[1])
+	}
+	if len(state.saved) != 1 {
+		t.Errorf("Expected peek not to save the checkpoint, got %+v", state.saved)
+	}
+}
+
+func TestReleaseWatcher_Run_Truncated(t *testing.T) {
+	lastRun := time.Now().Add(-48 * time.Hour)
+	repo := &mockReleaseRepo{fresh: []domain.ExtractedRepo{
+		{RepoID: "owner/a", LatestRelease: &domain.Release{Tag: "v2.0.0", PublishedAt: time.Now()}},
+		{RepoID: "owner/b", LatestRelease: &domain.Release{Tag: "v1.0.0", PublishedAt: time.Now()}},
+	}}
+	state := &mockSyncState{state: &domain.SyncState{Source: service.SyncSourceReleases, LastRunAt: lastRun}}
+	watcher := service.NewReleaseWatcher(repo, state, nil, nil)
+
+	// Releases cut off by the limit must still be listed on the next run.
+	var out bytes.Buffer
+	if err := watcher.Run(context.Background(), service.ReleaseWatchOptions{Limit: 1}, &out); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if !strings.Contains(out.String(), "owner/a") || strings.Contains(out.String(), "owner/b") {
+		t.Errorf("Expected only the first release, got %q", out.String())
+	}
+	if len(state.saved) != 0 {
+		t.Errorf("Expected a truncated run not to move the checkpoint, got %+v", state.saved)
+	}
+
+	// Once everything fits, the checkpoint advances.
+	out.Reset()
+	if err := watcher.Run(context.Background(), service.ReleaseWatchOptions{Limit: 2}, &out); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if !strings.Contains(out.String(), "owner/b") || len(state.saved) != 1 {
+		t.Errorf("Expected both releases and a saved checkpoint, got %q and %+v", out.String(), state.saved)
+	}
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+
+	"github.com/brianluby/karakeep-extractor/internal/core/domain"
+	"github.com/brianluby/karakeep-extractor/internal/ui"
+)
+
+// StaleReport lists bookmarked repos that look abandoned: no push in a while,
+// archived on their forge, or gone (404).
+type StaleReport struct {
+	repo     domain.RankingRepository
+	exporter domain.Exporter
+}
+
+func NewStaleReport(repo domain.RankingRepository, exporter domain.Exporter) *StaleReport {
+	return &StaleReport{
+		repo:     repo,
+		exporter: exporter,
+	}
+}
+
+// Run writes stale repos with no push in the last 'months' months, least recently pushed
+// (and missing repos) first. A limit <= 0 lists them all.
+func (s *StaleReport) Run(ctx context.Context, months int, limit int, output io.Writer) error {
+	if months <= 0 {
+		return fmt.Errorf("months must be positive, got %d", months)
+	}
+	cutoff := time.Now().AddDate(0, -months, 0)
+
+	repos, err := s.repo.GetRankedRepos(ctx, domain.RankQuery{
+		SortBy:      domain.SortByUpdated,
+		StaleBefore: cutoff,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get stale repos: %w", err)
+	}
+
+	// SortByUpdated is newest first; the report reads better oldest first.
+	slices.Reverse(repos)
+	if limit > 0 && len(repos) > limit {
+		repos = repos[:limit]
+	}
+
+	if len(repos) == 0 {
+		fmt.Fprintln(output, "No stale repositories found.")
+		return nil
+	}
+
+	if s.exporter != nil {
+		return s.exporter.Export(repos, output)
+	}
+
+	return ui.UsePager(output, func(w io.Writer) error {
+		return ui.NewStaleTableRenderer(w, cutoff).Render(repos)
+	})
+}
@@ -0,0 +1,52 @@
+package service_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brianluby/karakeep-extractor/internal/core/domain"
+	"github.com/brianluby/karakeep-extractor/internal/core/service"
+)
+
+func TestStaleReport_Run(t *testing.T) {
+	recent := time.Now().AddDate(0, -1, 0)
+	old := time.Now().AddDate(-3, 0, 0)
+
+	// Returned newest first, as for SortByUpdated.
+	mockRepo := &mockRankingRepo{
+		repos: []domain.ExtractedRepo{
+			{RepoID: "test/archived", LastPushedAt: &recent, RepoArchived: true, EnrichmentStatus: domain.StatusSuccess},
+			{RepoID: "test/dormant", LastPushedAt: &old, EnrichmentStatus: domain.StatusSuccess},
+			{RepoID: "test/gone", EnrichmentStatus: domain.StatusNotFound},
+		},
+	}
+
+	var buf bytes.Buffer
+	report := service.NewStaleReport(mockRepo, nil)
+	if err := report.Run(context.Background(), 12, 0, &buf); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	out := buf.String()
+	gone := strings.Index(out, "test/gone")
+	dormant := strings.Index(out, "test/dormant")
+	archived := strings.Index(out, "test/archived")
+	if gone < 0 || dormant < 0 || archived < 0 {
+		t.Fatalf("Expected all repos in output, got:\n%s", out)
+	}
+	if !(gone < dormant && dormant < archived) {
+		t.Errorf("Expected oldest first, got:\n%s", out)
+	}
+	for _, reason := range []string{"not found", "no recent push", "archived"} {
+		if !strings.Contains(out, reason) {
+			t.Errorf("Expected reason %q in output, got:\n%s", reason, out)
+		}
+	}
+
+	if err := report.Run(context.Background(), 0, 0, &buf); err == nil {
+		t.Error("Expected error for non-positive months")
+	}
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/brianluby/karakeep-extractor/internal/core/domain"
+)
+
+// Default score weights and recency half-life, used for anything config.yaml leaves unset.
+const (
+	DefaultStarsWeight         = 1.0
+	DefaultForkRatioWeight     = 0.5
+	DefaultRecencyWeight       = 1.0
+	DefaultBookmarksWeight     = 1.0
+	DefaultTagsWeight          = 1.0
+	DefaultRecencyHalfLifeDays = 180
+)
+
+// Scorer computes the interest score behind 'rank --sort score'. Each component is normalised
+// to 0..1 and multiplied by its weight; the score is their sum.
+type Scorer struct {
+	stars, forkRatio, recency, bookmarks, tags float64
+	interestTags                               map[string]bool
+	halfLife                                   time.Duration
+}
+
+// NewScorer resolves cfg against the defaults. Negative weights are rejected.
+func NewScorer(cfg domain.ScoreConfig) (*Scorer, error) {
+	s := &Scorer{
+		stars:        weight(cfg.Weights.Stars, DefaultStarsWeight),
+		forkRatio:    weight(cfg.Weights.ForkRatio, DefaultForkRatioWeight),
+		recency:      weight(cfg.Weights.Recency, DefaultRecencyWeight),
+		bookmarks:    weight(cfg.Weights.Bookmarks, DefaultBookmarksWeight),
+		tags:         weight(cfg.Weights.Tags, DefaultTagsWeight),
+		interestTags: make(map[string]bool),
+		halfLife:     time.Duration(DefaultRecencyHalfLifeDays) * 24 * time.Hour,
+	}
+	for name, w := range map[string]float64{"stars": s.stars, "fork_ratio": s.forkRatio, "recency": s.recency, "bookmarks": s.bookmarks, "tags": s.tags} {
+		if w < 0 || math.IsNaN(w) || math.IsInf(w, 0) {
+			return nil, fmt.Errorf("invalid score weight %s: %v (must be zero or positive)", name, w)
+		}
+	}
+	if cfg.RecencyHalfLifeDays < 0 {
+		return nil, fmt.Errorf("invalid recency_half_life_days: %d", cfg.RecencyHalfLifeDays)
+	}
+	if cfg.RecencyHalfLifeDays > 0 {
+		s.halfLife = time.Duration(cfg.RecencyHalfLifeDays) * 24 * time.Hour
+	}
+	for _, tag := range cfg.Tags {
+		if tag = strings.ToLower(strings.TrimSpace(tag)); tag != "" {
+			s.interestTags[tag] = true
+		}
+	}
+	return s, nil
+}
+
+func weight(w *float64, def float64) float64 {
+	if w == nil {
+		return def
+	}
+	return *w
+}
+
+// Describe spells out the formula, for 'rank --explain'.
+func (s *Scorer) Describe() string {
+	tags := "no interest tags configured"
+	if len(s.interestTags) > 0 {
+		names := make([]string, 0, len(s.interestTags))
+		for tag := range s.interestTags {
+			names = append(names, tag)
+		}
+		sort.Strings(names)
+		tags = "interest tags: " + strings.Join(names, ", ")
+	}
+	return fmt.Sprintf("score = %.2f×log-stars + %.2f×fork-ratio + %.2f×recency (half-life %dd) + %.2f×bookmarks + %.2f×tags (%s)",
+		s.stars, s.forkRatio, s.recency, int(s.halfLife.Hours()/24), s.bookmarks, s.tags, tags)
+}
+
+// Rank scores repos and sorts them by score, highest first. Stars and bookmarks are normalised
+// against the repos passed in, so scores are only comparable within one ranking.
+func (s *Scorer) Rank(repos []domain.ExtractedRepo, now time.Time) {
+	maxStars, maxBookmarks := 0, 0
+	for _, repo := range repos {
+		if repo.Stars != nil && *repo.Stars > maxStars {
+			maxStars = *repo.Stars
+		}
+		maxBookmarks = max(maxBookmarks, repo.BookmarkCount)
+	}
+
+	for i := range repos {
+		repo := &repos[i]
+		stars, forks := 0, 0
+		if repo.Stars != nil {
+			stars = *repo.Stars
+		}
+		if repo.Forks != nil {
+			forks = *repo.Forks
+		}
+
+		var b domain.ScoreBreakdown
+		if maxStars > 0 {
+			b.Stars = s.stars * math.Log1p(float64(stars)) / math.Log1p(float64(maxStars))
+		}
+		if stars > 0 {
+			b.ForkRatio = s.forkRatio * math.Min(float64(forks)/float64(stars), 1)
+		}
+		if repo.LastPushedAt != nil {
+			age := max(now.Sub(*repo.LastPushedAt), 0)
+			b.Recency = s.recency * math.Exp2(-float64(age)/float64(s.halfLife))
+		}
+		// A repo is bookmarked at least once, so only extra bookmarks count.
+		if maxBookmarks > 1 && repo.BookmarkCount > 1 {
+			b.Bookmarks = s.bookmarks * float64(repo.BookmarkCount-1) / float64(maxBookmarks-1)
+		}
+		if len(s.interestTags) > 0 {
+			matched := make(map[string]bool)
+			for _, tag := range repo.Tags {
+				if name := strings.ToLower(tag.Name); s.interestTags[name] {
+					matched[name] = true
+				}
+			}
+			b.Tags = s.tags * float64(len(matched)) / float64(len(s.interestTags))
+		}
+		b.Total = b.Stars + b.ForkRatio + b.Recency + b.Bookmarks + b.Tags
+		repo.Score = &b
+	}
+
+	sort.SliceStable(repos, func(i, j int) bool {
+		return repos[i].Score.Total > repos[j].Score.Total
+	})
+}
@@ -0,0 +1,106 @@
+package service_test
+
+import (
+	"bytes"
+	"context"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brianluby/karakeep-extractor/internal/core/domain"
+	"github.com/brianluby/karakeep-extractor/internal/core/service"
+)
+
+func intPtr(n int) *int { return &n }
+
+func floatPtr(f float64) *float64 { return &f }
+
+func TestScorer_Rank(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	fresh := now.Add(-24 * time.Hour)
+	old := now.AddDate(-2, 0, 0)
+
+	repos := []domain.ExtractedRepo{
+		// Popular but abandoned.
+		{RepoID: "big/old", Stars: intPtr(10000), Forks: intPtr(500), LastPushedAt: &old, BookmarkCount: 1},
+		// Small, active, bookmarked three times and tagged with an interest tag.
+		{RepoID: "small/active", Stars: intPtr(100), Forks: intPtr(50), LastPushedAt: &fresh, BookmarkCount: 3,
+			Tags: []domain.Tag{{Name: "Rust"}}},
+	}
+
+	scorer, err := service.NewScorer(domain.ScoreConfig{Tags: []string{"rust", "cli"}})
+	if err != nil {
+		t.Fatalf("NewScorer failed: %v", err)
+	}
+	scorer.Rank(repos, now)
+
+	if repos[0].RepoID != "small/active" {
+		t.Fatalf("Expected small/active to rank first, got %s", repos[0].RepoID)
+	}
+
+	active, big := repos[0].Score, repos[1].Score
+	if big.Stars != service.DefaultStarsWeight {
+		t.Errorf("Expected the most starred repo to get the full stars weight, got %v", big.Stars)
+	}
+	if want := service.DefaultForkRatioWeight * 0.5; math.Abs(active.ForkRatio-want) > 1e-9 {
+		t.Errorf("Expected fork ratio contribution %v, got %v", want, active.ForkRatio)
+	}
+	if active.Bookmarks != service.DefaultBookmarksWeight || big.Bookmarks != 0 {
+		t.Errorf("Expected only extra bookmarks to count, got %v and %v", active.Bookmarks, big.Bookmarks)
+	}
+	if active.Tags != service.DefaultTagsWeight/2 || big.Tags != 0 {
+		t.Errorf("Expected a case-insensitive match on 1 of 2 interest tags, got %v and %v", active.Tags, big.Tags)
+	}
+	if big.Recency >= 0.1 || active.Recency <= 0.99 {
+		t.Errorf("Expected recency to decay with age, got %v (old) and %v (fresh)", big.Recency, active.Recency)
+	}
+	sum := active.Stars + active.ForkRatio + active.Recency + active.Bookmarks + active.Tags
+	if math.Abs(active.Total-sum) > 1e-9 {
+		t.Errorf("Expected total %v to be the sum of the components, got %v", sum, active.Total)
+	}
+
+	// Zeroing every weight but stars falls back to a pure stars ordering.
+	zero := floatPtr(0)
+	starsOnly, err := service.NewScorer(domain.ScoreConfig{Weights: domain.ScoreWeights{ForkRatio: zero, Recency: zero, Bookmarks: zero, Tags: zero}})
+	if err != nil {
+		t.Fatalf("NewScorer failed: %v", err)
+	}
+	starsOnly.Rank(repos, now)
+	if repos[0].RepoID != "big/old" {
+		t.Errorf("Expected big/old first by stars alone, got %s", repos[0].RepoID)
+	}
+
+	if _, err := service.NewScorer(domain.ScoreConfig{Weights: domain.ScoreWeights{Stars: floatPtr(-1)}}); err == nil {
+		t.Error("Expected error for a negative weight")
+	}
+}
+
+func TestRanker_ScoreExplain(t *testing.T) {
+	now := time.Now()
+	mockRepo := &mockRankingRepo{repos: []domain.ExtractedRepo{
+		{RepoID: "a/one", Stars: intPtr(10), LastPushedAt: &now},
+		{RepoID: "b/two", Stars: intPtr(1000), LastPushedAt: &now},
+		{RepoID: "c/three", Stars: intPtr(1)},
+	}}
+
+	var buf bytes.Buffer
+	ranker := service.NewRanker(mockRepo, nil, nil).WithExplain(true)
+	if err := ranker.Rank(context.Background(), domain.RankQuery{Limit: 2, SortBy: domain.SortByScore}, &buf); err != nil {
+		t.Fatalf("Rank failed: %v", err)
+	}
+
+	out := buf.String()
+	for _, want := range []string{"score = 1.00×log-stars", "SCORE", "LOG-STARS", "RECENCY"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in output:\n%s", want, out)
+		}
+	}
+	if strings.Index(out, "b/two") > strings.Index(out, "a/one") || strings.Contains(out, "c/three") {
+		t.Errorf("Expected b/two then a/one, limited to 2:\n%s", out)
+	}
+
+	if err := ranker.Rank(context.Background(), domain.RankQuery{SortBy: domain.SortByStars}, &buf); err == nil {
+		t.Error("Expected --explain to be rejected for other sorts")
+	}
+}
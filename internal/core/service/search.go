@@ -0,0 +1,43 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/brianluby/karakeep-extractor/internal/core/domain"
+	"github.com/brianluby/karakeep-extractor/internal/ui"
+)
+
+// Searcher runs full-text searches and writes the results like Ranker does.
+type Searcher struct {
+	repo     domain.SearchRepository
+	exporter domain.Exporter
+}
+
+func NewSearcher(repo domain.SearchRepository, exporter domain.Exporter) *Searcher {
+	return &Searcher{
+		repo:     repo,
+		exporter: exporter,
+	}
+}
+
+func (s *Searcher) Search(ctx context.Context, query domain.SearchQuery, output io.Writer) error {
+	repos, err := s.repo.SearchRepos(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to search repos: %w", err)
+	}
+
+	if len(repos) == 0 {
+		fmt.Fprintln(output, "No repositories found.")
+		return nil
+	}
+
+	if s.exporter != nil {
+		return s.exporter.Export(repos, output)
+	}
+
+	return ui.UsePager(output, func(w io.Writer) error {
+		return ui.NewTableRenderer(w).Render(repos)
+	})
+}
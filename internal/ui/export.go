@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"strconv"
+	"strings"
 
 	"github.com/brianluby/karakeep-extractor/internal/core/domain"
 )
@@ -25,6 +26,41 @@ func (j *JSONExporter) Export(repos []domain.ExtractedRepo, w io.Writer) error {
 	return encoder.Encode(repos)
 }
 
+// jsonPage wraps a page of results with where it sits in the full ranking.
+type jsonPage struct {
+	Total      int                    `json:"total"`
+	Offset     int                    `json:"offset"`
+	Limit      int                    `json:"limit,omitempty"`
+	Page       int                    `json:"page,omitempty"`
+	NextOffset *int                   `json:"next_offset"` // null on the last page
+	NextPage   *int                   `json:"next_page,omitempty"`
+	Repos      []domain.ExtractedRepo `json:"repos"`
+}
+
+// ExportPage exports an object holding the repos plus the total count and next-page metadata.
+func (j *JSONExporter) ExportPage(page domain.Page, w io.Writer) error {
+	out := jsonPage{
+		Total:  page.Total,
+		Offset: page.Offset,
+		Limit:  max(page.Limit, 0),
+		Page:   page.Number(),
+		Repos:  page.Repos,
+	}
+	if out.Repos == nil {
+		out.Repos = []domain.ExtractedRepo{}
+	}
+	if next := page.NextOffset(); next >= 0 {
+		out.NextOffset = &next
+		if out.Page > 0 {
+			nextPage := out.Page + 1
+			out.NextPage = &nextPage
+		}
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(out)
+}
+
 // CSVExporter exports repositories as a CSV file with headers.
 type CSVExporter struct{}
 
@@ -33,17 +69,28 @@ func NewCSVExporter() *CSVExporter {
 }
 
 func (c *CSVExporter) Export(repos []domain.ExtractedRepo, w io.Writer) error {
+	return c.export(repos, 0, w)
+}
+
+// ExportPage exports the page's repos, numbering their Rank from the page offset.
+func (c *CSVExporter) ExportPage(page domain.Page, w io.Writer) error {
+	return c.export(page.Repos, page.Offset, w)
+}
+
+func (c *CSVExporter) export(repos []domain.ExtractedRepo, offset int, w io.Writer) error {
 	writer := csv.NewWriter(w)
 	defer writer.Flush()
 
 	// Write Header
-	header := []string{"Rank", "RepoID", "URL", "Stars", "Forks", "LastPushedAt", "Description", "Language"}
+	header := []string{"Rank", "RepoID", "URL", "Stars", "Forks", "LastPushedAt", "Description", "Language", "Tags", "Bookmarks",
+		"Topics", "License", "Archived", "Fork", "Parent", "OpenIssues", "Homepage", "CreatedAt", "DefaultBranch", "SizeKB",
+		"LatestRelease", "ReleasePublishedAt", "Score"}
 	if err := writer.Write(header); err != nil {
 		return fmt.Errorf("failed to write CSV header: %w", err)
 	}
 
 	for i, repo := range repos {
-		rank := strconv.Itoa(i + 1)
+		rank := strconv.Itoa(offset + i + 1)
 		stars := "0"
 		if repo.Stars != nil {
 			stars = strconv.Itoa(*repo.Stars)
@@ -64,6 +111,29 @@ func (c *CSVExporter) Export(repos []domain.ExtractedRepo, w io.Writer) error {
 		if repo.Language != nil {
 			lang = *repo.Language
 		}
+		openIssues := ""
+		if repo.OpenIssues != nil {
+			openIssues = strconv.Itoa(*repo.OpenIssues)
+		}
+		createdAt := ""
+		if repo.CreatedAt != nil {
+			createdAt = repo.CreatedAt.Format("2006-01-02T15:04:05Z")
+		}
+		size := ""
+		if repo.SizeKB != nil {
+			size = strconv.Itoa(*repo.SizeKB)
+		}
+		score := ""
+		if repo.Score != nil {
+			score = strconv.FormatFloat(repo.Score.Total, 'f', 4, 64)
+		}
+		release, releasedAt := "", ""
+		if repo.LatestRelease != nil {
+			release = repo.LatestRelease.Tag
+			if !repo.LatestRelease.PublishedAt.IsZero() {
+				releasedAt = repo.LatestRelease.PublishedAt.Format("2006-01-02T15:04:05Z")
+			}
+		}
 
 		record := []string{
 			rank,
@@ -74,6 +144,21 @@ func (c *CSVExporter) Export(repos []domain.ExtractedRepo, w io.Writer) error {
 			lastPushed,
 			desc,
 			lang,
+			strings.Join(domain.TagNames(repo.Tags), ";"),
+			strconv.Itoa(repo.BookmarkCount),
+			strings.Join(repo.Topics, ";"),
+			repo.License,
+			strconv.FormatBool(repo.RepoArchived),
+			strconv.FormatBool(repo.IsFork),
+			repo.Parent,
+			openIssues,
+			repo.Homepage,
+			createdAt,
+			repo.DefaultBranch,
+			size,
+			release,
+			releasedAt,
+			score,
 		}
 
 		if err := writer.Write(record); err != nil {
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/brianluby/karakeep-extractor/internal/core/domain"
 )
@@ -31,6 +32,63 @@ func TestJSONExporter_Export(t *testing.T) {
 	}
 }
 
+func TestJSONExporter_InternalFields(t *testing.T) {
+	checked := time.Now()
+	repos := []domain.ExtractedRepo{{
+		RepoID:        "test/repo",
+		ETag:          `W/"abc"`,
+		LastModified:  "Mon, 01 Jan 2024 00:00:00 GMT",
+		LastCheckedAt: &checked,
+		ReadmeSHA:     "0123abcd",
+		Readme:        "A long README.",
+	}}
+
+	var buf bytes.Buffer
+	if err := NewJSONExporter().Export(repos, &buf); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	var decoded []map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("Failed to decode JSON output: %v", err)
+	}
+	for _, key := range []string{"ETag", "LastModified", "LastCheckedAt", "ReadmeSHA", "Readme"} {
+		if _, ok := decoded[0][key]; ok {
+			t.Errorf("Expected %s to be left out of the JSON output", key)
+		}
+	}
+}
+
+func TestJSONExporter_Tags(t *testing.T) {
+	repos := []domain.ExtractedRepo{{
+		RepoID: "test/repo",
+		Tags:   []domain.Tag{{Name: "cli", AttachedBy: domain.TagSourceHuman}, {Name: "go", AttachedBy: domain.TagSourceAI}},
+	}}
+
+	var buf bytes.Buffer
+	if err := NewJSONExporter().Export(repos, &buf); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	var decoded []struct {
+		Tags       []string          `json:"Tags"`
+		TagSources map[string]string `json:"tag_sources"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("Failed to decode JSON output: %v", err)
+	}
+	if got := decoded[0]; strings.Join(got.Tags, ",") != "cli,go" || got.TagSources["cli"] != "human" || got.TagSources["go"] != "ai" {
+		t.Errorf("Expected tag names with their sources, got %+v", got)
+	}
+
+	// The output reads back into the same tags.
+	var repo []domain.ExtractedRepo
+	if err := json.Unmarshal(buf.Bytes(), &repo); err != nil {
+		t.Fatalf("Failed to decode JSON output: %v", err)
+	}
+	if len(repo[0].Tags) != 2 || repo[0].Tags[0] != repos[0].Tags[0] || repo[0].Tags[1] != repos[0].Tags[1] {
+		t.Errorf("Expected the tags to round-trip, got %+v", repo[0].Tags)
+	}
+}
+
 func TestCSVExporter_Export(t *testing.T) {
 	exporter := NewCSVExporter()
 	var buf bytes.Buffer
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strings"
 	"html"
+	"strconv"
 
 	"github.com/brianluby/karakeep-extractor/internal/core/domain"
 )
@@ -18,9 +19,25 @@ func NewMarkdownFormatter() *MarkdownFormatter {
 func (m *MarkdownFormatter) FormatTable(repos []domain.ExtractedRepo) string {
 	var b strings.Builder
 
+	// The release column only appears once releases have been fetched.
+	showRelease := false
+	for _, repo := range repos {
+		if repo.LatestRelease != nil {
+			showRelease = true
+			break
+		}
+	}
+
 	// Header
-	b.WriteString("| Rank | Repository | Stars | Forks | Last Updated | Description |\n")
-	b.WriteString("|------|------------|-------|-------|--------------|-------------|\n")
+	b.WriteString("| Rank | Repository | Stars | Forks | Issues | Last Updated | Created | Description | License | Topics | Homepage | Branch | Size (KB) | Tags |")
+	if showRelease {
+		b.WriteString(" Latest Release |")
+	}
+	b.WriteString("\n|------|------------|-------|-------|--------|--------------|---------|-------------|---------|--------|----------|--------|-----------|------|")
+	if showRelease {
+		b.WriteString("----------------|")
+	}
+	b.WriteString("\n")
 
 	for i, repo := range repos {
 		rank := i + 1
@@ -36,6 +53,18 @@ func (m *MarkdownFormatter) FormatTable(repos []domain.ExtractedRepo) string {
 		if repo.LastPushedAt != nil {
 			updated = repo.LastPushedAt.Format("2006-01-02")
 		}
+		issues := "-"
+		if repo.OpenIssues != nil {
+			issues = strconv.Itoa(*repo.OpenIssues)
+		}
+		created := "-"
+		if repo.CreatedAt != nil {
+			created = repo.CreatedAt.Format("2006-01-02")
+		}
+		size := "-"
+		if repo.SizeKB != nil {
+			size = strconv.Itoa(*repo.SizeKB)
+		}
 		
 		desc := ""
 		if repo.Description != nil {
@@ -48,8 +77,32 @@ func (m *MarkdownFormatter) FormatTable(repos []domain.ExtractedRepo) string {
 		// Link the repo name
 		nameLink := fmt.Sprintf("[%s](%s)", repo.RepoID, repo.URL)
 
-		fmt.Fprintf(&b, "| %d | %s | %d | %d | %s | %s |\n", 
-			rank, nameLink, stars, forks, updated, desc)
+		if repo.IsFork {
+			if repo.Parent != "" {
+				nameLink += " (fork of " + html.EscapeString(repo.Parent) + ")"
+			} else {
+				nameLink += " (fork)"
+			}
+		}
+		if repo.RepoArchived {
+			nameLink += " (archived)"
+		}
+		license := html.EscapeString(repo.License)
+		topics := html.EscapeString(strings.Join(repo.Topics, ", "))
+		homepage := strings.ReplaceAll(html.EscapeString(repo.Homepage), "|", "\\|")
+		branch := html.EscapeString(repo.DefaultBranch)
+		tags := html.EscapeString(strings.Join(domain.TagNames(repo.Tags), ", "))
+
+		fmt.Fprintf(&b, "| %d | %s | %d | %d | %s | %s | %s | %s | %s | %s | %s | %s | %s | %s |", 
+			rank, nameLink, stars, forks, issues, updated, created, desc, license, topics, homepage, branch, size, tags)
+		if showRelease {
+			release := html.EscapeString(ReleaseLabel(repo.LatestRelease))
+			if repo.LatestRelease != nil && !repo.LatestRelease.PublishedAt.IsZero() {
+				release += " (" + repo.LatestRelease.PublishedAt.Format("2006-01-02") + ")"
+			}
+			fmt.Fprintf(&b, " %s |", release)
+		}
+		b.WriteString("\n")
 	}
 
 	return b.String()
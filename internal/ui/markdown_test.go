@@ -39,3 +39,44 @@ func TestMarkdownFormatter_FormatTable(t *testing.T) {
 		t.Error("Date formatting incorrect")
 	}
 }
+
+func TestMarkdownFormatter_ReleaseColumn(t *testing.T) {
+	formatter := NewMarkdownFormatter()
+	repos := []domain.ExtractedRepo{{RepoID: "owner/repo", URL: "http://github.com/owner/repo"}}
+
+	if output := formatter.FormatTable(repos); strings.Contains(output, "Latest Release") {
+		t.Errorf("Expected no release column without releases, got %q", output)
+	}
+
+	repos[0].LatestRelease = &domain.Release{Tag: "v1.0.0-rc1", PublishedAt: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), Prerelease: true}
+	output := formatter.FormatTable(repos)
+	if !strings.Contains(output, "| Latest Release |") || !strings.Contains(output, "| v1.0.0-rc1 (pre-release) (2024-03-01) |") {
+		t.Errorf("Expected release column, got %q", output)
+	}
+}
+
+func TestMarkdownFormatter_Metadata(t *testing.T) {
+	formatter := NewMarkdownFormatter()
+	created := time.Date(2019, 5, 4, 0, 0, 0, 0, time.UTC)
+	issues, size := 12, 2048
+	repos := []domain.ExtractedRepo{{
+		RepoID:        "me/tool",
+		URL:           "https://github.com/me/tool",
+		IsFork:        true,
+		Parent:        "upstream/tool",
+		RepoArchived:  true,
+		OpenIssues:    &issues,
+		Homepage:      "https://tool.dev",
+		CreatedAt:     &created,
+		DefaultBranch: "main",
+		SizeKB:        &size,
+		License:       "MIT",
+		Topics:        []string{"cli", "go"},
+	}}
+
+	output := formatter.FormatTable(repos)
+	want := "| 1 | [me/tool](https://github.com/me/tool) (fork of upstream/tool) (archived) | 0 | 0 | 12 | - | 2019-05-04 |  | MIT | cli, go | https://tool.dev | main | 2048 |  |"
+	if !strings.Contains(output, want) {
+		t.Errorf("Expected metadata row %q, got %q", want, output)
+	}
+}
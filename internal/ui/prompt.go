@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"fmt"
 	"io"
+	"strconv"
 	"strings"
 	"syscall"
 
@@ -68,3 +69,25 @@ func (p *Prompt) AskConfirm(label string) (bool, error) {
 	return input == "y" || input == "yes", nil
 }
 
+
+// AskChoice lists numbered options and returns the one picked by number or name.
+// Other text is returned as typed, and an empty answer returns defaultValue.
+func (p *Prompt) AskChoice(label string, options []string, defaultValue string) (string, error) {
+	for i, option := range options {
+		fmt.Fprintf(p.writer, "  %d) %s\n", i+1, option)
+	}
+	for {
+		input, err := p.Ask(label, defaultValue)
+		if err != nil {
+			return "", err
+		}
+		n, err := strconv.Atoi(input)
+		if err != nil {
+			return input, nil
+		}
+		if n >= 1 && n <= len(options) {
+			return options[n-1], nil
+		}
+		fmt.Fprintf(p.writer, "Please enter a number between 1 and %d.\n", len(options))
+	}
+}
@@ -38,3 +38,28 @@ func TestPrompt_Ask_Default(t *testing.T) {
 		t.Errorf("Expected 'default', got '%s'", res)
 	}
 }
+
+func TestPrompt_AskChoice(t *testing.T) {
+	options := []string{"llama3.2:latest", "qwen2.5:7b"}
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"2\n", "qwen2.5:7b"},
+		{"\n", "llama3.2:latest"},
+		{"9\n1\n", "llama3.2:latest"}, // Out of range, asked again.
+		{"mistral\n", "mistral"},
+	}
+
+	for _, tt := range tests {
+		var w bytes.Buffer
+		p := NewPrompt(bytes.NewBufferString(tt.input), &w)
+		res, err := p.AskChoice("Model", options, "llama3.2:latest")
+		if err != nil {
+			t.Fatalf("AskChoice(%q) failed: %v", tt.input, err)
+		}
+		if res != tt.want {
+			t.Errorf("AskChoice(%q) = %q, want %q", tt.input, res, tt.want)
+		}
+	}
+}
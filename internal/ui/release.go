@@ -0,0 +1,53 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/brianluby/karakeep-extractor/internal/core/domain"
+)
+
+// ReleaseTableRenderer renders repos with their latest release.
+type ReleaseTableRenderer struct {
+	writer *tabwriter.Writer
+}
+
+func NewReleaseTableRenderer(output io.Writer) *ReleaseTableRenderer {
+	return &ReleaseTableRenderer{
+		writer: tabwriter.NewWriter(output, 0, 8, 2, ' ', 0),
+	}
+}
+
+// Render prints the table to the configured writer.
+func (t *ReleaseTableRenderer) Render(repos []domain.ExtractedRepo) error {
+	fmt.Fprintln(t.writer, "NAME\tRELEASE\tPUBLISHED\tSTARS")
+
+	for _, repo := range repos {
+		stars := "-"
+		if repo.Stars != nil {
+			stars = fmt.Sprintf("%d", *repo.Stars)
+		}
+		published := "-"
+		if repo.LatestRelease != nil && !repo.LatestRelease.PublishedAt.IsZero() {
+			published = repo.LatestRelease.PublishedAt.Format("2006-01-02")
+		}
+		fmt.Fprintf(t.writer, "%s\t%s\t%s\t%s\n", repo.RepoID, ReleaseLabel(repo.LatestRelease), published, stars)
+	}
+
+	return t.writer.Flush()
+}
+
+// ReleaseLabel is the release tag, marked when it is a pre-release or a bare tag.
+func ReleaseLabel(release *domain.Release) string {
+	switch {
+	case release == nil:
+		return "-"
+	case release.Prerelease:
+		return release.Tag + " (pre-release)"
+	case release.FromTag:
+		return release.Tag + " (tag)"
+	default:
+		return release.Tag
+	}
+}
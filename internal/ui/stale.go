@@ -0,0 +1,56 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/brianluby/karakeep-extractor/internal/core/domain"
+)
+
+// StaleTableRenderer renders the stale repository report with the reason each repo is listed.
+type StaleTableRenderer struct {
+	writer *tabwriter.Writer
+	cutoff time.Time
+}
+
+func NewStaleTableRenderer(output io.Writer, cutoff time.Time) *StaleTableRenderer {
+	return &StaleTableRenderer{
+		writer: tabwriter.NewWriter(output, 0, 8, 2, ' ', 0),
+		cutoff: cutoff,
+	}
+}
+
+// Render prints the table to the configured writer.
+func (t *StaleTableRenderer) Render(repos []domain.ExtractedRepo) error {
+	fmt.Fprintln(t.writer, "NAME\tREASON\tSTARS\tLAST PUSH")
+
+	for _, repo := range repos {
+		stars := "-"
+		if repo.Stars != nil {
+			stars = fmt.Sprintf("%d", *repo.Stars)
+		}
+		lastPush := "-"
+		if repo.LastPushedAt != nil {
+			lastPush = repo.LastPushedAt.Format("2006-01-02")
+		}
+		fmt.Fprintf(t.writer, "%s\t%s\t%s\t%s\n", repo.RepoID, StaleReason(repo, t.cutoff), stars, lastPush)
+	}
+
+	return t.writer.Flush()
+}
+
+// StaleReason explains why a repo appears in the stale report.
+func StaleReason(repo domain.ExtractedRepo, cutoff time.Time) string {
+	switch {
+	case repo.EnrichmentStatus == domain.StatusNotFound:
+		return "not found"
+	case repo.RepoArchived:
+		return "archived"
+	case repo.LastPushedAt != nil && repo.LastPushedAt.Before(cutoff):
+		return "no recent push"
+	default:
+		return "-"
+	}
+}
@@ -3,6 +3,8 @@ package ui
 import (
 	"fmt"
 	"io"
+	"strconv"
+	"strings"
 	"text/tabwriter"
 	"time"
 
@@ -11,7 +13,9 @@ import (
 
 // TableRenderer renders a list of repos as a formatted table.
 type TableRenderer struct {
-	writer *tabwriter.Writer
+	writer     *tabwriter.Writer
+	explain    bool
+	rankOffset int
 }
 
 func NewTableRenderer(output io.Writer) *TableRenderer {
@@ -21,14 +25,63 @@ func NewTableRenderer(output io.Writer) *TableRenderer {
 	}
 }
 
+// WithExplain adds a column per score component to score rankings.
+func (t *TableRenderer) WithExplain(explain bool) *TableRenderer {
+	t.explain = explain
+	return t
+}
+
+// WithRankOffset numbers the rows from offset+1, for pages after the first.
+func (t *TableRenderer) WithRankOffset(offset int) *TableRenderer {
+	t.rankOffset = offset
+	return t
+}
+
 // Render prints the table to the configured writer.
 func (t *TableRenderer) Render(repos []domain.ExtractedRepo) error {
+	// The delta column only appears for trend rankings, and the bookmarks
+	// column only once some repo has been bookmarked more than once.
+	showDelta, showBookmarks, showScore := false, false, false
+	for _, repo := range repos {
+		if repo.StarsDelta != nil {
+			showDelta = true
+		}
+		if repo.BookmarkCount > 1 {
+			showBookmarks = true
+		}
+		if repo.Score != nil {
+			showScore = true
+		}
+	}
+	explain := showScore && t.explain
+
 	// Header
-	fmt.Fprintln(t.writer, "RANK\tNAME\tSTARS\tFORKS\tUPDATED")
+	header := []string{"RANK", "NAME"}
+	if showScore {
+		header = append(header, "SCORE")
+	}
+	header = append(header, "STARS")
+	if showDelta {
+		header = append(header, "DELTA")
+	}
+	if showBookmarks {
+		header = append(header, "BOOKMARKS")
+	}
+	header = append(header, "FORKS", "UPDATED")
+	if explain {
+		header = append(header, "LOG-STARS", "FORK-RATIO", "RECENCY", "BOOKMARKED", "TAG-MATCH")
+	}
+	fmt.Fprintln(t.writer, strings.Join(header, "\t"))
 
 	for i, repo := range repos {
-		rank := i + 1
+		rank := t.rankOffset + i + 1
 		name := repo.RepoID
+		// Only visible when the query includes orphaned/archived rows.
+		if repo.Orphaned {
+			name += " [orphaned]"
+		} else if repo.BookmarkArchived {
+			name += " [archived]"
+		}
 		stars := 0
 		if repo.Stars != nil {
 			stars = *repo.Stars
@@ -43,7 +96,33 @@ func (t *TableRenderer) Render(repos []domain.ExtractedRepo) error {
 			updated = formatRelativeTime(*repo.LastPushedAt)
 		}
 
-		fmt.Fprintf(t.writer, "%d\t%s\t%d\t%d\t%s\n", rank, name, stars, forks, updated)
+		row := []string{strconv.Itoa(rank), name}
+		if showScore {
+			score := "-"
+			if repo.Score != nil {
+				score = fmt.Sprintf("%.3f", repo.Score.Total)
+			}
+			row = append(row, score)
+		}
+		row = append(row, strconv.Itoa(stars))
+		if showDelta {
+			delta := "-"
+			if repo.StarsDelta != nil {
+				delta = fmt.Sprintf("%+d", *repo.StarsDelta)
+			}
+			row = append(row, delta)
+		}
+		if showBookmarks {
+			row = append(row, strconv.Itoa(repo.BookmarkCount))
+		}
+		row = append(row, strconv.Itoa(forks), updated)
+		if explain && repo.Score != nil {
+			s := repo.Score
+			for _, c := range []float64{s.Stars, s.ForkRatio, s.Recency, s.Bookmarks, s.Tags} {
+				row = append(row, fmt.Sprintf("%.3f", c))
+			}
+		}
+		fmt.Fprintln(t.writer, strings.Join(row, "\t"))
 	}
 
 	return t.writer.Flush()
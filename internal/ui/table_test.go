@@ -38,6 +38,29 @@ func TestTableRenderer_Render(t *testing.T) {
 	}
 }
 
+func TestTableRenderer_Render_Bookmarks(t *testing.T) {
+	var buf bytes.Buffer
+	repos := []domain.ExtractedRepo{
+		{RepoID: "owner/popular", BookmarkCount: 4},
+		{RepoID: "owner/once", BookmarkCount: 1},
+	}
+	if err := NewTableRenderer(&buf).Render(repos); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), "BOOKMARKS") {
+		t.Errorf("Expected bookmarks column, got: %s", buf.String())
+	}
+
+	// Single bookmarks everywhere don't earn a column.
+	buf.Reset()
+	if err := NewTableRenderer(&buf).Render(repos[1:]); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if strings.Contains(buf.String(), "BOOKMARKS") {
+		t.Errorf("Expected no bookmarks column, got: %s", buf.String())
+	}
+}
+
 func intPtr(i int) *int {
 	return &i
 }
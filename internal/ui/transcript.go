@@ -0,0 +1,38 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/brianluby/karakeep-extractor/internal/core/domain"
+)
+
+// WriteChatTranscript writes an analyze chat session as Markdown, noting the repo
+// selection whenever it changes.
+func WriteChatTranscript(w io.Writer, turns []domain.ChatTurn, saved time.Time) error {
+	var b strings.Builder
+	b.WriteString("# Repository Analysis\n\n")
+	fmt.Fprintf(&b, "Saved %s.\n", saved.Format("2006-01-02 15:04"))
+
+	filter, count := "", -1
+	for i, turn := range turns {
+		if turn.Filter != filter || turn.RepoCount != count {
+			filter, count = turn.Filter, turn.RepoCount
+			desc := "all repositories"
+			if filter != "" {
+				desc = "`" + filter + "`"
+			}
+			fmt.Fprintf(&b, "\n**Filter:** %s (%d repositories)\n", desc, count)
+		}
+		fmt.Fprintf(&b, "\n## Question %d\n\n", i+1)
+		for _, line := range strings.Split(strings.TrimSpace(turn.Question), "\n") {
+			b.WriteString(strings.TrimRight("> "+line, " ") + "\n")
+		}
+		b.WriteString("\n" + strings.TrimSpace(turn.Answer) + "\n")
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
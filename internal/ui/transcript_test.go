@@ -0,0 +1,31 @@
+package ui
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/brianluby/karakeep-extractor/internal/core/domain"
+)
+
+func TestWriteChatTranscript(t *testing.T) {
+	turns := []domain.ChatTurn{
+		{Question: "Which is best?", Answer: "owner/one.\n", RepoCount: 2},
+		{Question: "Why?\n\nExplain", Answer: "Stars.", RepoCount: 2},
+		{Question: "And Go?", Answer: "owner/gopher.", Filter: "lang:Go", RepoCount: 1},
+	}
+	var buf bytes.Buffer
+	if err := WriteChatTranscript(&buf, turns, time.Date(2026, 1, 2, 15, 4, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("WriteChatTranscript failed: %v", err)
+	}
+
+	expected := "# Repository Analysis\n\nSaved 2026-01-02 15:04.\n" +
+		"\n**Filter:** all repositories (2 repositories)\n" +
+		"\n## Question 1\n\n> Which is best?\n\nowner/one.\n" +
+		"\n## Question 2\n\n> Why?\n>\n> Explain\n\nStars.\n" +
+		"\n**Filter:** `lang:Go` (1 repositories)\n" +
+		"\n## Question 3\n\n> And Go?\n\nowner/gopher.\n"
+	if buf.String() != expected {
+		t.Errorf("Unexpected transcript:\n%s", buf.String())
+	}
+}